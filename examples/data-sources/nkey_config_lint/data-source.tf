@@ -0,0 +1,15 @@
+resource "nkey_nkey" "app" {
+  type = "user"
+}
+
+data "nkey_config_lint" "server" {
+  path       = "${path.module}/nats-server.conf"
+  known_keys = [nkey_nkey.app.public_key]
+}
+
+check "server_config" {
+  assert {
+    condition     = data.nkey_config_lint.server.valid
+    error_message = "nats-server.conf references unknown keys: ${join(", ", data.nkey_config_lint.server.unknown_keys)}"
+  }
+}
@@ -0,0 +1,15 @@
+# A user JWT issued with nsc, checked before it is handed out.
+data "nkey_decode_jwt" "legacy" {
+  jwt = file("${path.module}/legacy.jwt")
+}
+
+check "legacy_user" {
+  assert {
+    condition     = data.nkey_decode_jwt.legacy.type == "user" && data.nkey_decode_jwt.legacy.expires_at != null
+    error_message = "legacy.jwt must be a user JWT that expires."
+  }
+}
+
+output "legacy_publish" {
+  value = try(data.nkey_decode_jwt.legacy.permissions.publish.allow, [])
+}
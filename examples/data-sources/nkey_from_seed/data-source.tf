@@ -0,0 +1,13 @@
+# The operator seed is kept in Vault rather than generated by Terraform.
+data "vault_kv_secret_v2" "operator" {
+  mount = "secret"
+  name  = "nats/operator"
+}
+
+data "nkey_from_seed" "operator" {
+  seed = data.vault_kv_secret_v2.operator.data["seed"]
+}
+
+output "operator_public_key" {
+  value = data.nkey_from_seed.operator.public_key
+}
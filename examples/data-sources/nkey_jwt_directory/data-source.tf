@@ -0,0 +1,18 @@
+variable "account_jwts" {
+  type = list(string)
+}
+
+data "nkey_jwt_directory" "accounts" {
+  account_jwts = var.account_jwts
+  prefix       = "jwt/"
+}
+
+resource "aws_s3_object" "account_jwt" {
+  for_each = data.nkey_jwt_directory.accounts.files
+
+  bucket        = "nats-bootstrap"
+  key           = each.key
+  content       = each.value
+  content_type  = "application/jwt"
+  cache_control = "max-age=60"
+}
@@ -0,0 +1,13 @@
+resource "nkey_nkey" "account" {
+  type = "account"
+}
+
+data "nkey_key_file" "account" {
+  seed = nkey_nkey.account.seed
+}
+
+resource "local_sensitive_file" "account_key" {
+  filename        = pathexpand("~/.local/share/nats/nsc/keys/keys/${data.nkey_key_file.account.nsc_path}")
+  content         = data.nkey_key_file.account.content
+  file_permission = "0600"
+}
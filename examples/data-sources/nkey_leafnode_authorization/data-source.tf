@@ -0,0 +1,9 @@
+resource "nkey_nkey" "edge" {
+  type = "user"
+}
+
+data "nkey_leafnode_authorization" "hub" {
+  accounts = {
+    EDGE = [nkey_nkey.edge.public_key]
+  }
+}
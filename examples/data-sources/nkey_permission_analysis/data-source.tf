@@ -0,0 +1,25 @@
+data "nkey_permission_analysis" "orders" {
+  users = {
+    frontend = {
+      publish = {
+        allow = ["orders.new"]
+      }
+      subscribe = {
+        allow = ["_INBOX.>"]
+      }
+    }
+    worker = {
+      publish = {
+        allow = ["orders.*.status"]
+      }
+      subscribe = {
+        allow = ["orders.>", "_INBOX.>"]
+        deny  = ["orders.internal.>"]
+      }
+    }
+  }
+}
+
+output "permission_findings" {
+  value = [for f in data.nkey_permission_analysis.orders.findings : "${f.kind}: ${f.description}"]
+}
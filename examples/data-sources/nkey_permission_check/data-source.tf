@@ -0,0 +1,20 @@
+data "nkey_permission_check" "tenant_isolation" {
+  action  = "subscribe"
+  subject = "tenant_b.>"
+
+  permissions = {
+    publish = {
+      allow = ["tenant_a.>"]
+    }
+    subscribe = {
+      allow = ["tenant_a.>", "_INBOX.>"]
+    }
+  }
+}
+
+check "tenant_isolation" {
+  assert {
+    condition     = !data.nkey_permission_check.tenant_isolation.allowed
+    error_message = "Tenant A can see tenant B: ${data.nkey_permission_check.tenant_isolation.reason}"
+  }
+}
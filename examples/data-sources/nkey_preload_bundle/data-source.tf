@@ -0,0 +1,21 @@
+variable "operator_jwt" {
+  type = string
+}
+
+variable "system_account_jwt" {
+  type = string
+}
+
+variable "tenant_jwt" {
+  type = string
+}
+
+data "nkey_preload_bundle" "firmware" {
+  operator_jwt = var.operator_jwt
+  account_jwts = [var.system_account_jwt, var.tenant_jwt]
+}
+
+resource "local_file" "resolver" {
+  filename = "${path.module}/resolver.conf"
+  content  = data.nkey_preload_bundle.firmware.config
+}
@@ -0,0 +1,4 @@
+data "nkey_resolver_config" "full" {
+  dir          = "/data/jwt"
+  allow_delete = true
+}
@@ -0,0 +1,26 @@
+variable "system_user_creds" {
+  type      = string
+  sensitive = true
+}
+
+variable "account_jwts" {
+  type        = map(string)
+  description = "Account public keys to account JWTs"
+}
+
+data "nkey_resolver_drift" "cluster" {
+  servers     = ["nats://nats.example.com:4222"]
+  credentials = var.system_user_creds
+  accounts    = var.account_jwts
+
+  timeouts {
+    read = "30s"
+  }
+}
+
+check "resolver_in_sync" {
+  assert {
+    condition     = data.nkey_resolver_drift.cluster.in_sync
+    error_message = "Resolver drift detected: ${jsonencode(data.nkey_resolver_drift.cluster.status)}"
+  }
+}
@@ -0,0 +1,9 @@
+resource "nkey_nkey" "operator" {
+  type            = "operator"
+  shares          = 5
+  share_threshold = 3
+}
+
+data "nkey_seed_from_shares" "operator" {
+  shares = slice(nkey_nkey.operator.seed_shares, 0, 3)
+}
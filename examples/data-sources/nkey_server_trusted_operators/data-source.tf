@@ -0,0 +1,14 @@
+resource "nkey_nkey" "operator" {
+  type = "operator"
+}
+
+data "nkey_server_trusted_operators" "cluster" {
+  monitoring_url = "http://nats.example.com:8222"
+
+  lifecycle {
+    postcondition {
+      condition     = contains(self.operator_public_keys, nkey_nkey.operator.public_key)
+      error_message = "The cluster does not trust the operator managed by Terraform."
+    }
+  }
+}
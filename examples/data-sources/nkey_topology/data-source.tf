@@ -0,0 +1,17 @@
+variable "operator_jwt" {
+  type = string
+}
+
+variable "account_jwts" {
+  type = list(string)
+}
+
+data "nkey_topology" "this" {
+  operator_jwt = var.operator_jwt
+  account_jwts = var.account_jwts
+}
+
+resource "local_file" "topology" {
+  filename = "${path.module}/topology.dot"
+  content  = data.nkey_topology.this.dot
+}
@@ -0,0 +1,9 @@
+variable "tenant_deny" {
+  type    = list(string)
+  default = ["tenant.b.>"]
+
+  validation {
+    condition     = !provider::nkey::permission_allows("subscribe", "tenant.b.orders", null, var.tenant_deny)
+    error_message = "The deny list must block the subjects of tenant B."
+  }
+}
@@ -0,0 +1,8 @@
+variable "creds" {
+  type      = string
+  sensitive = true
+}
+
+output "support_bundle_creds" {
+  value = nonsensitive(provider::nkey::redact_creds(var.creds))
+}
@@ -0,0 +1,8 @@
+resource "nkey_nkey" "tenant" {
+  type = "account"
+}
+
+locals {
+  # e.g. "_INBOX_b7Xq2mZ01kPa"
+  inbox_prefix = "_INBOX_${provider::nkey::subject_token(nkey_nkey.tenant.public_key, 12)}"
+}
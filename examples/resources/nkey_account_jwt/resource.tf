@@ -0,0 +1,150 @@
+resource "nkey_nkey" "billing" {
+  type = "account"
+}
+
+resource "nkey_account_jwt" "billing" {
+  public_key  = nkey_nkey.billing.public_key
+  issuer_seed = nkey_nkey.operator_signing_key.seed
+  name        = "billing"
+  description = "Invoicing and quotes"
+  info_url    = "https://wiki.example.com/teams/billing"
+  tags        = ["team:billing"]
+
+  # Catch a signing key of another operator of the workspace during plan.
+  operator_jwt = nkey_operator_jwt.main.jwt
+
+  default_permissions = {
+    publish = {
+      deny = ["$SYS.>"]
+    }
+    subscribe = {
+      allow = ["billing.>", "_INBOX.>"]
+    }
+  }
+
+  scoped_signing_keys = [
+    {
+      key         = nkey_nkey.billing_team_signing_key.public_key
+      role        = "billing-team"
+      description = "Users issued by the billing team"
+
+      permissions = {
+        publish = {
+          allow = ["billing.>"]
+        }
+        subscribe = {
+          allow = ["billing.>", "_INBOX.>"]
+        }
+      }
+    },
+  ]
+
+  limits = {
+    connections = 100
+    payload     = 1048576
+  }
+
+  jetstream_tiered_limits = {
+    R1 = {
+      memory_storage = 268435456
+      disk_storage   = 10737418240
+      streams        = 10
+    }
+    R3 = {
+      memory_storage = 0
+      disk_storage   = 1073741824
+      streams        = 2
+    }
+  }
+
+  # Replicate the streams of the account over its own connections rather than
+  # those of the system account.
+  cluster_traffic = "owner"
+
+  revocations = {
+    (nkey_nkey.leaked_user.public_key) = "2026-10-01T00:00:00Z"
+  }
+
+  # Cut off all users of the reporting application at once.
+  revoked_users = [
+    {
+      public_keys = values(nkey_keyset.reporting_users.public_keys)
+      revoked_at  = "2026-10-01T00:00:00Z"
+    },
+  ]
+
+  mappings = {
+    "billing.quotes" = {
+      destinations = [
+        { subject = "billing.quotes.v1", weight = 90 },
+        { subject = "billing.quotes.v2", weight = 10 },
+      ]
+    }
+  }
+
+  exports = [
+    {
+      name    = "invoices"
+      subject = "billing.invoices.>"
+      type    = "stream"
+    },
+    {
+      name           = "quotes"
+      subject        = "billing.quotes"
+      type           = "service"
+      token_required = true
+    },
+  ]
+}
+
+resource "nkey_nkey" "shipping" {
+  type = "account"
+}
+
+resource "nkey_account_jwt" "shipping" {
+  public_key  = nkey_nkey.shipping.public_key
+  issuer_seed = nkey_nkey.operator_signing_key.seed
+  name        = "shipping"
+
+  imports = [
+    {
+      name          = "invoices"
+      account       = nkey_nkey.billing.public_key
+      subject       = "billing.invoices.>"
+      local_subject = "invoices.>"
+      type          = "stream"
+    },
+    {
+      name    = "quotes"
+      account = nkey_nkey.billing.public_key
+      subject = "billing.quotes"
+      type    = "service"
+      token   = nkey_activation_jwt.quotes_for_shipping.jwt
+    },
+  ]
+
+  # Let the shipping team monitor its own connections through the system
+  # account.
+  system_imports = {
+    account = nkey_system_account.this.public_key
+  }
+}
+
+resource "nkey_nkey" "auth_service" {
+  type = "user"
+}
+
+resource "nkey_nkey" "auth_service_xkey" {
+  type = "curve"
+}
+
+resource "nkey_account_jwt" "tenants" {
+  public_key  = nkey_nkey.tenants.public_key
+  issuer_seed = nkey_nkey.operator_signing_key.seed
+  name        = "tenants"
+
+  authorization = {
+    auth_users = [nkey_nkey.auth_service.public_key]
+    xkey       = nkey_nkey.auth_service_xkey.public_key
+  }
+}
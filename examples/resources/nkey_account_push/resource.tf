@@ -0,0 +1,38 @@
+variable "system_creds" {
+  type      = string
+  sensitive = true
+}
+
+variable "staging_system_creds" {
+  type      = string
+  sensitive = true
+}
+
+variable "account_jwts" {
+  description = "Map of account public keys to account JWTs"
+  type        = map(string)
+}
+
+resource "nkey_account_push" "all" {
+  servers     = ["nats://nats-0.example.com:4222", "nats://nats-1.example.com:4222"]
+  credentials = var.system_creds
+  accounts    = var.account_jwts
+  retries     = 5
+
+  # Changed accounts are pushed to staging first and only reach production
+  # once the staging resolver serves them.
+  canary = {
+    servers     = ["nats://nats-staging.example.com:4222"]
+    credentials = var.staging_system_creds
+  }
+
+  # The cluster is reached over a slow VPN link.
+  timeouts {
+    create = "2m"
+    update = "2m"
+  }
+}
+
+output "failed_accounts" {
+  value = [for account, result in nkey_account_push.all.results : account if !result.pushed]
+}
@@ -0,0 +1,17 @@
+resource "nkey_activation_jwt" "invoices_for_shipping" {
+  subject        = "billing.invoices.>"
+  import_type    = "stream"
+  target_account = nkey_nkey.shipping.public_key
+  issuer_seed    = nkey_nkey.billing.seed
+  expires_at     = "2027-01-01T00:00:00Z"
+}
+
+# Signed with a signing key of the billing account instead of its identity
+# seed
+resource "nkey_activation_jwt" "quotes_for_shipping" {
+  subject        = "billing.quotes"
+  import_type    = "service"
+  target_account = nkey_nkey.shipping.public_key
+  issuer_seed    = nkey_nkey.billing_signing_key.seed
+  issuer_account = nkey_nkey.billing.public_key
+}
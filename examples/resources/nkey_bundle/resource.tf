@@ -0,0 +1,12 @@
+resource "nkey_nkey" "partner" {
+  type = "user"
+}
+
+resource "nkey_bundle" "partner" {
+  path   = "${path.module}/partner-credentials.tar.gz"
+  format = "tar.gz"
+  files = {
+    "partner.nk"  = nkey_nkey.partner.seed
+    "partner.pub" = nkey_nkey.partner.public_key
+  }
+}
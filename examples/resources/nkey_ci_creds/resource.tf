@@ -0,0 +1,18 @@
+resource "nkey_ci_creds" "deploy" {
+  issuer_seed    = nkey_nkey.billing_signing_key.seed
+  issuer_account = nkey_nkey.billing.public_key
+  name           = "ci-deploy"
+  ttl            = "8h"
+  renew_before   = "2h"
+
+  permissions = {
+    publish = {
+      allow = ["deploy.>"]
+    }
+  }
+}
+
+resource "local_sensitive_file" "deploy_creds" {
+  filename = "${path.module}/deploy.creds"
+  content  = nkey_ci_creds.deploy.creds
+}
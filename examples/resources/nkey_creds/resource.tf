@@ -0,0 +1,16 @@
+resource "nkey_creds" "invoicing" {
+  jwt     = nkey_user_jwt.invoicing.jwt
+  seed    = nkey_nkey.invoicing.seed
+  servers = ["tls://nats-1.example.com:4222", "tls://nats-2.example.com:4222"]
+}
+
+resource "local_sensitive_file" "invoicing_creds" {
+  filename = "${path.module}/invoicing.creds"
+  content  = nkey_creds.invoicing.creds
+}
+
+# Application modules take everything they need to connect in one attribute.
+module "invoicing" {
+  source = "./modules/invoicing"
+  nats   = nkey_creds.invoicing.connection_info
+}
@@ -0,0 +1,13 @@
+resource "nkey_nkey" "token_issuer" {
+  type = "server"
+}
+
+resource "nkey_generic_jwt" "reporting" {
+  subject     = "reporting-service"
+  issuer_seed = nkey_nkey.token_issuer.seed
+  expires_at  = "2027-01-01T00:00:00Z"
+
+  claims = jsonencode({
+    roles = ["reports:read"]
+  })
+}
@@ -0,0 +1,8 @@
+resource "nkey_keyset" "services" {
+  type  = "user"
+  names = ["billing", "orders", "shipping"]
+}
+
+output "service_public_keys" {
+  value = nkey_keyset.services.public_keys
+}
@@ -0,0 +1,25 @@
+resource "nkey_limits_profile" "small" {
+  name = "small"
+
+  limits = {
+    connections = 100
+    imports     = 10
+    exports     = 10
+  }
+
+  jetstream_tiered_limits = {
+    R1 = {
+      disk_storage = 1073741824
+      streams      = 10
+    }
+  }
+}
+
+resource "nkey_account_jwt" "billing" {
+  public_key  = nkey_nkey.billing.public_key
+  issuer_seed = nkey_nkey.operator_signing_key.seed
+  name        = "billing"
+
+  limits                  = nkey_limits_profile.small.limits
+  jetstream_tiered_limits = nkey_limits_profile.small.jetstream_tiered_limits
+}
@@ -0,0 +1,16 @@
+resource "nkey_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nkey_nkey" "account" {
+  type = "account"
+}
+
+resource "nkey_manifest" "release" {
+  path = "${path.module}/manifest.json"
+  keys = {
+    operator = nkey_nkey.operator.public_key
+    account  = nkey_nkey.account.public_key
+  }
+  signing_key = nkey_nkey.operator.seed
+}
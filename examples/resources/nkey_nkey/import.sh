@@ -0,0 +1,3 @@
+# An nkey is imported by its seed, the type is inferred from the seed. Read
+# the seed from a file to keep it out of the shell history.
+terraform import nkey_nkey.account "$(cat account.nk)"
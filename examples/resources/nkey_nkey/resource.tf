@@ -0,0 +1,39 @@
+resource "nkey_nkey" "service" {
+  type = "user"
+
+  # Generate a new key pair every 90 days, or whenever the service is
+  # redeployed to a new environment.
+  rotation_days = 90
+  keepers = {
+    environment = "production"
+  }
+}
+
+# Rotated in place every 30 days. The previous key stays available for a week,
+# so both keys can be trusted while clients pick up the new one. The last five
+# retired keys are kept for deny lists.
+resource "nkey_nkey" "gateway" {
+  type                  = "server"
+  rotation_days         = 30
+  rotation_overlap_days = 7
+  key_history           = 5
+}
+
+output "gateway_trusted_keys" {
+  value = compact([nkey_nkey.gateway.public_key, nkey_nkey.gateway.previous_public_key])
+}
+
+output "gateway_retired_keys" {
+  value = nkey_nkey.gateway.retired_public_keys
+}
+
+output "service_public_key" {
+  value = nkey_nkey.service.public_key
+}
+
+# Derived from the provider master_seed, the same key is recreated from
+# the master seed alone, e.g. in a new workspace after a disaster.
+resource "nkey_nkey" "billing" {
+  type            = "account"
+  derivation_path = "prod/accounts/billing"
+}
@@ -0,0 +1,39 @@
+resource "nkey_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nkey_nkey" "operator_signing_key" {
+  type = "operator"
+}
+
+resource "nkey_nkey" "system_account" {
+  type = "account"
+}
+
+resource "nkey_operator_jwt" "main" {
+  seed               = nkey_nkey.operator.seed
+  name               = "main"
+  signing_keys       = [nkey_nkey.operator_signing_key.public_key]
+  system_account     = nkey_nkey.system_account.public_key
+  account_server_url = "nats://nats.example.com:4222"
+  tags               = ["env:production"]
+
+  operator_service_urls    = ["tls://nats.example.com:4222"]
+  strict_signing_key_usage = true
+}
+
+# The identity key of this operator lives on an air-gapped machine. The first
+# apply outputs signing_request, which is signed offline and fed back through
+# signed_jwt, e.g. from a file carried over.
+resource "nkey_operator_jwt" "airgapped" {
+  public_key   = "OCD4ZJXFGMRRIX3DJ666MLKR2GX4R7UMXZIUSPKZPAJWUNDPXZFX7CNJ"
+  name         = "airgapped"
+  signing_keys = [nkey_nkey.operator_signing_key.public_key]
+  signed_jwt   = fileexists("operator.jwt") ? trimspace(file("operator.jwt")) : null
+
+  strict_signing_key_usage = true
+}
+
+output "operator_signing_request" {
+  value = nkey_operator_jwt.airgapped.signing_request
+}
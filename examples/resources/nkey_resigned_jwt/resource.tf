@@ -0,0 +1,12 @@
+resource "nkey_nkey" "billing_signing_key" {
+  type = "account"
+}
+
+# Users issued by nsc with the account identity key, moved to a signing key
+# of the account. The signing key must be listed in the account JWT.
+resource "nkey_resigned_jwt" "users" {
+  for_each = fileset("${path.module}/users", "*.jwt")
+
+  source_jwt  = trimspace(file("${path.module}/users/${each.value}"))
+  issuer_seed = nkey_nkey.billing_signing_key.seed
+}
@@ -0,0 +1,15 @@
+resource "nkey_system_account" "sys" {
+  issuer_seed = nkey_nkey.operator_signing_key.seed
+}
+
+resource "nkey_operator_jwt" "main" {
+  seed           = nkey_nkey.operator.seed
+  name           = "main"
+  signing_keys   = [nkey_nkey.operator_signing_key.public_key]
+  system_account = nkey_system_account.sys.public_key
+}
+
+resource "local_sensitive_file" "sys_creds" {
+  filename = "${path.module}/sys.creds"
+  content  = nkey_system_account.sys.creds
+}
@@ -0,0 +1,32 @@
+resource "nkey_tenant" "acme" {
+  issuer_seed = nkey_nkey.operator_signing_key.seed
+  name        = "acme"
+
+  limits = {
+    connections = 100
+  }
+
+  jetstream_limits = {
+    memory_storage = 1073741824
+    disk_storage   = 10737418240
+  }
+
+  permissions = {
+    publish = {
+      allow = ["acme.>", "$JS.API.>"]
+    }
+    subscribe = {
+      allow = ["acme.>", "_INBOX.>"]
+    }
+  }
+
+  push = {
+    servers     = ["nats://nats.example.com:4222"]
+    credentials = nkey_system_account.sys.creds
+  }
+}
+
+resource "local_sensitive_file" "acme_creds" {
+  filename = "${path.module}/acme.creds"
+  content  = nkey_tenant.acme.creds
+}
@@ -0,0 +1,80 @@
+resource "nkey_nkey" "invoicing" {
+  type = "user"
+}
+
+resource "nkey_user_jwt" "invoicing" {
+  public_key  = nkey_nkey.invoicing.public_key
+  issuer_seed = nkey_nkey.billing.seed
+  name        = "invoicing"
+
+  permissions = {
+    publish = {
+      allow = ["billing.invoices.>"]
+      deny  = ["billing.invoices.admin.>"]
+    }
+    subscribe = {
+      allow = ["_INBOX.>", "billing.quotes"]
+    }
+  }
+
+  # Reply to quote requests without publish permissions on the inboxes.
+  allow_responses = {
+    max_messages = 1
+    ttl          = "5s"
+  }
+
+  # Valid for 30 days, issued again during the last week.
+  expires_at   = "720h"
+  renew_before = "168h"
+}
+
+resource "nkey_nkey" "dashboard" {
+  type = "user"
+}
+
+# The browser dashboard connects over WebSocket and cannot sign the nonce.
+resource "nkey_user_jwt" "dashboard" {
+  public_key   = nkey_nkey.dashboard.public_key
+  issuer_seed  = nkey_nkey.billing.seed
+  name         = "dashboard"
+  bearer_token = true
+
+  # The WebSocket gateway only admits tokens issued for it.
+  audience = "dashboard-gateway"
+
+  permissions = {
+    subscribe = {
+      allow = ["billing.invoices.>"]
+    }
+  }
+}
+
+resource "nkey_nkey" "sensor" {
+  type = "user"
+}
+
+resource "nkey_user_jwt" "sensor" {
+  public_key               = nkey_nkey.sensor.public_key
+  issuer_seed              = nkey_nkey.billing.seed
+  name                     = "sensor"
+  allowed_connection_types = ["MQTT"]
+
+  limits = {
+    src    = ["192.0.2.0/24"]
+    locale = "Europe/Berlin"
+    times = [
+      { start = "06:00:00", end = "22:00:00" },
+    ]
+  }
+}
+
+# Signed with a signing key of the billing account instead of its identity
+# key, which stays offline. The account JWT lets the plan catch a signing key
+# missing from the account or a user outliving its account.
+resource "nkey_user_jwt" "reporting" {
+  public_key     = nkey_nkey.reporting.public_key
+  issuer_seed    = nkey_nkey.billing_signing_key.seed
+  issuer_account = nkey_nkey.billing.public_key
+  account_jwt    = nkey_account_jwt.billing.jwt
+  name           = "reporting"
+}
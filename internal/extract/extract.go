@@ -0,0 +1,225 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package extract implements the `extract` subcommand of the provider
+// binary, which reads Terraform state and writes nkey credentials to files.
+package extract
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// extractable lists the resource types the subcommand understands together
+// with the attribute written out by default.
+var extractable = map[string]string{
+	"nkey_nkey":           "seed",
+	"nkey_keyset":         "seeds",
+	"nkey_creds":          "creds",
+	"nkey_system_account": "creds",
+}
+
+// sensitiveAttributes are never printed when listing resources.
+var sensitiveAttributes = map[string]bool{
+	"private_key":  true,
+	"private_keys": true,
+	"seed":         true,
+	"seeds":        true,
+	"user_seed":    true,
+	"user_jwt":     true,
+	"creds":        true,
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+const usage = `Usage: terraform-provider-nkey extract [options] <state-file>
+
+  Reads a Terraform state file (or the output of "terraform show -json")
+  and writes credentials of nkey_nkey, nkey_keyset, nkey_creds and
+  nkey_system_account resources to files, one per key of a keyset. Use "-"
+  to read the state from stdin.
+
+Options:
+`
+
+// Run parses args and executes the subcommand.
+func Run(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("extract", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+
+	var (
+		list      bool
+		address   string
+		attribute string
+		outDir    string
+	)
+
+	fs.BoolVar(&list, "list", false, "list matching resources with sensitive values redacted instead of writing files")
+	fs.StringVar(&address, "address", "", "comma separated list of resource addresses to extract (default all)")
+	fs.StringVar(&attribute, "attribute", "", "attribute to write (default seed, seeds of nkey_keyset and creds of nkey_creds and nkey_system_account)")
+	fs.StringVar(&outDir, "out", ".", "directory to write files to")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("exactly one state file must be given")
+	}
+
+	data, err := readInput(fs.Arg(0), stdin)
+	if err != nil {
+		return err
+	}
+
+	instances, err := ParseState(data, func(t string) bool {
+		_, ok := extractable[t]
+		return ok
+	})
+	if err != nil {
+		return err
+	}
+
+	instances = filterAddresses(instances, address)
+
+	if list {
+		return listInstances(instances, stdout)
+	}
+
+	return writeInstances(instances, attribute, outDir, stdout)
+}
+
+func readInput(name string, stdin io.Reader) ([]byte, error) {
+	if name == "-" {
+		return io.ReadAll(stdin)
+	}
+	return os.ReadFile(name)
+}
+
+func filterAddresses(instances []Instance, addresses string) []Instance {
+	if addresses == "" {
+		return instances
+	}
+
+	wanted := map[string]bool{}
+	for _, a := range strings.Split(addresses, ",") {
+		wanted[strings.TrimSpace(a)] = true
+	}
+
+	var filtered []Instance
+	for _, i := range instances {
+		if wanted[i.Address] {
+			filtered = append(filtered, i)
+		}
+	}
+	return filtered
+}
+
+func listInstances(instances []Instance, stdout io.Writer) error {
+	w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(w, "ADDRESS\tTYPE\tKEY TYPE\tPUBLIC KEY\tSEED")
+	for _, i := range instances {
+		if seeds := i.Map("seeds"); seeds != nil {
+			publicKeys := i.Map("public_keys")
+			for _, name := range sortedKeys(seeds) {
+				fmt.Fprintf(w, "%s.%s\t%s\t%s\t%s\t%s\n", i.Address, name, i.Type, i.String("type"), publicKeys[name], redact("seeds", seeds[name]))
+			}
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", i.Address, i.Type, i.String("type"), i.String("public_key"), redact("seed", i.String("seed")))
+	}
+
+	return w.Flush()
+}
+
+func writeInstances(instances []Instance, attribute, outDir string, stdout io.Writer) error {
+	if err := os.MkdirAll(outDir, 0o700); err != nil {
+		return err
+	}
+
+	for _, i := range instances {
+		attr := attribute
+		if attr == "" {
+			attr = extractable[i.Type]
+		}
+
+		values := attributeValues(i, attr)
+		if len(values) == 0 {
+			return fmt.Errorf("%s: attribute %q is not set", i.Address, attr)
+		}
+
+		for _, address := range sortedKeys(values) {
+			file := filepath.Join(outDir, fileName(address, attr))
+			if err := os.WriteFile(file, []byte(values[address]+"\n"), 0o600); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(stdout, "%s: wrote %s to %s\n", address, attr, file)
+		}
+	}
+
+	return nil
+}
+
+// attributeValues returns the values of the attribute of i to write, keyed
+// by the address they are written for. A map attribute, such as the seeds
+// of a keyset, has one value per name, addressed as `<address>.<name>`.
+func attributeValues(i Instance, attr string) map[string]string {
+	if m := i.Map(attr); m != nil {
+		values := map[string]string{}
+		for name, v := range m {
+			if v != "" {
+				values[i.Address+"."+name] = v
+			}
+		}
+		return values
+	}
+
+	if v := i.String(attr); v != "" {
+		return map[string]string{i.Address: v}
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func fileName(address, attribute string) string {
+	name := unsafeFileChars.ReplaceAllString(address, "_")
+
+	switch attribute {
+	case "public_key", "public_keys", "user_public_key":
+		return name + ".pub"
+	case "creds":
+		return name + ".creds"
+	case "jwt", "user_jwt":
+		return name + ".jwt"
+	}
+	return name + ".nk"
+}
+
+func redact(attribute, value string) string {
+	if value == "" || !sensitiveAttributes[attribute] {
+		return value
+	}
+	return "(sensitive)"
+}
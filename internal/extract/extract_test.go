@@ -0,0 +1,326 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package extract
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const stateFile = `{
+  "version": 4,
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "nkey_nkey",
+      "name": "operator",
+      "instances": [
+        {"attributes": {"type": "operator", "public_key": "OPUB", "seed": "SOSEED"}}
+      ]
+    },
+    {
+      "module": "module.users",
+      "mode": "managed",
+      "type": "nkey_nkey",
+      "name": "user",
+      "instances": [
+        {"index_key": "alice", "attributes": {"type": "user", "public_key": "UALICE", "seed": "SUALICE"}},
+        {"index_key": 1, "attributes": {"type": "user", "public_key": "UONE", "seed": "SUONE"}}
+      ]
+    },
+    {
+      "mode": "data",
+      "type": "nkey_nkey",
+      "name": "ignored",
+      "instances": [{"attributes": {"seed": "SDATA"}}]
+    },
+    {
+      "mode": "managed",
+      "type": "null_resource",
+      "name": "ignored",
+      "instances": [{"attributes": {"id": "1"}}]
+    }
+  ]
+}`
+
+const showJSON = `{
+  "format_version": "1.0",
+  "values": {
+    "root_module": {
+      "resources": [
+        {"address": "nkey_nkey.operator", "mode": "managed", "type": "nkey_nkey", "values": {"public_key": "OPUB", "seed": "SOSEED"}}
+      ],
+      "child_modules": [
+        {
+          "resources": [
+            {"address": "module.users.nkey_nkey.user[\"alice\"]", "mode": "managed", "type": "nkey_nkey", "values": {"public_key": "UALICE", "seed": "SUALICE"}},
+            {"address": "module.users.data.nkey_nkey.ignored", "mode": "data", "type": "nkey_nkey", "values": {}}
+          ]
+        }
+      ]
+    }
+  }
+}`
+
+// resourcesState holds the other resources with credentials.
+const resourcesState = `{
+  "version": 4,
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "nkey_keyset",
+      "name": "app",
+      "instances": [
+        {"attributes": {"type": "user", "public_keys": {"alice": "UALICE", "bob": "UBOB"}, "seeds": {"alice": "SUALICE", "bob": "SUBOB"}}}
+      ]
+    },
+    {
+      "mode": "managed",
+      "type": "nkey_creds",
+      "name": "app",
+      "instances": [
+        {"attributes": {"jwt": "eyJ.app", "seed": "SUAPP", "creds": "APP CREDS"}}
+      ]
+    },
+    {
+      "mode": "managed",
+      "type": "nkey_system_account",
+      "name": "sys",
+      "instances": [
+        {"attributes": {"public_key": "ASYS", "seed": "SASYS", "user_seed": "SUSYS", "creds": "SYS CREDS"}}
+      ]
+    }
+  ]
+}`
+
+func isNkey(t string) bool {
+	return t == "nkey_nkey"
+}
+
+func addresses(instances []Instance) []string {
+	var a []string
+	for _, i := range instances {
+		a = append(a, i.Address)
+	}
+	return a
+}
+
+func TestParseState(t *testing.T) {
+	tests := map[string]struct {
+		state string
+		want  []string
+	}{
+		"state file": {
+			state: stateFile,
+			want:  []string{"nkey_nkey.operator", `module.users.nkey_nkey.user["alice"]`, "module.users.nkey_nkey.user[1]"},
+		},
+		"show json": {
+			state: showJSON,
+			want:  []string{"nkey_nkey.operator", `module.users.nkey_nkey.user["alice"]`},
+		},
+		"empty show json": {
+			state: `{"format_version": "1.0"}`,
+		},
+		"empty state file": {
+			state: `{"version": 4, "resources": []}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			instances, err := ParseState([]byte(test.state), isNkey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := addresses(instances); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("addresses = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseStateErrors(t *testing.T) {
+	for name, state := range map[string]string{
+		"not json":        "nope",
+		"old state":       `{"version": 3}`,
+		"unknown version": `{}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseState([]byte(state), isNkey); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestRunWrite(t *testing.T) {
+	dir := t.TempDir()
+
+	var out bytes.Buffer
+	err := Run([]string{"-out", dir, "-address", "nkey_nkey.operator, module.users.nkey_nkey.user[1]", "-"}, strings.NewReader(stateFile), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"nkey_nkey.operator.nk":             "SOSEED\n",
+		"module.users.nkey_nkey.user_1_.nk": "SUONE\n",
+	}
+	for name, want := range files {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0o600 {
+			t.Errorf("%s has mode %v, want 0600", name, info.Mode().Perm())
+		}
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != len(files) {
+		t.Errorf("wrote %d files, want %d", len(entries), len(files))
+	}
+}
+
+func TestRunWriteResources(t *testing.T) {
+	dir := t.TempDir()
+
+	var out bytes.Buffer
+	if err := Run([]string{"-out", dir, "-"}, strings.NewReader(resourcesState), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"nkey_keyset.app.alice.nk":      "SUALICE\n",
+		"nkey_keyset.app.bob.nk":        "SUBOB\n",
+		"nkey_creds.app.creds":          "APP CREDS\n",
+		"nkey_system_account.sys.creds": "SYS CREDS\n",
+	}
+	for name, want := range files {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != len(files) {
+		t.Errorf("wrote %d files, want %d", len(entries), len(files))
+	}
+}
+
+func TestRunKeysetPublicKeys(t *testing.T) {
+	dir := t.TempDir()
+
+	err := Run([]string{"-out", dir, "-address", "nkey_keyset.app", "-attribute", "public_keys", "-"}, strings.NewReader(resourcesState), &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "nkey_keyset.app.bob.pub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "UBOB\n" {
+		t.Errorf("public key = %q", got)
+	}
+}
+
+func TestRunPublicKey(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := Run([]string{"-out", dir, "-attribute", "public_key", "-"}, strings.NewReader(showJSON), &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "module.users.nkey_nkey.user_alice_.pub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "UALICE\n" {
+		t.Errorf("public key = %q", got)
+	}
+}
+
+func TestRunMissingAttribute(t *testing.T) {
+	err := Run([]string{"-out", t.TempDir(), "-attribute", "encrypted_private_key", "-"}, strings.NewReader(stateFile), &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "encrypted_private_key") {
+		t.Errorf("expected a missing attribute error, got %v", err)
+	}
+}
+
+func TestRunList(t *testing.T) {
+	var out bytes.Buffer
+	if err := Run([]string{"-list", "-"}, strings.NewReader(stateFile), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(out.String(), "SOSEED") || strings.Contains(out.String(), "SUALICE") {
+		t.Errorf("list prints seeds:\n%s", out.String())
+	}
+	for _, want := range []string{"nkey_nkey.operator", "OPUB", "(sensitive)", `module.users.nkey_nkey.user["alice"]`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("list does not contain %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunListResources(t *testing.T) {
+	var out bytes.Buffer
+	if err := Run([]string{"-list", "-"}, strings.NewReader(resourcesState), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, secret := range []string{"SUALICE", "SUBOB", "SUAPP", "SASYS", "CREDS"} {
+		if strings.Contains(out.String(), secret) {
+			t.Errorf("list prints %s:\n%s", secret, out.String())
+		}
+	}
+	for _, want := range []string{"nkey_keyset.app.alice", "UBOB", "nkey_creds.app", "nkey_system_account.sys", "ASYS"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("list does not contain %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunUsage(t *testing.T) {
+	if err := Run(nil, strings.NewReader(""), &bytes.Buffer{}); err == nil {
+		t.Error("expected an error without state file")
+	}
+}
+
+func TestFileName(t *testing.T) {
+	tests := []struct {
+		address, attribute, want string
+	}{
+		{"nkey_nkey.operator", "seed", "nkey_nkey.operator.nk"},
+		{"nkey_nkey.operator", "public_key", "nkey_nkey.operator.pub"},
+		{`module.a.nkey_nkey.user["bob/../x"]`, "seed", "module.a.nkey_nkey.user_bob_.._x_.nk"},
+		{"nkey_keyset.app.alice", "seeds", "nkey_keyset.app.alice.nk"},
+		{"nkey_keyset.app.alice", "public_keys", "nkey_keyset.app.alice.pub"},
+		{"nkey_creds.app", "creds", "nkey_creds.app.creds"},
+		{"nkey_system_account.sys", "user_jwt", "nkey_system_account.sys.jwt"},
+	}
+
+	for _, test := range tests {
+		if got := fileName(test.address, test.attribute); got != test.want {
+			t.Errorf("fileName(%q, %q) = %q, want %q", test.address, test.attribute, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package extract
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Instance is a single resource instance found in a state document.
+type Instance struct {
+	Address    string
+	Type       string
+	Attributes map[string]interface{}
+}
+
+// String returns the attribute value as a string, or "" if it is unset.
+func (i Instance) String(name string) string {
+	if v, ok := i.Attributes[name].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// Map returns the map attribute value with its string elements, or nil if
+// it is unset or not a map.
+func (i Instance) Map(name string) map[string]string {
+	v, ok := i.Attributes[name].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	m := make(map[string]string, len(v))
+	for k, e := range v {
+		if s, ok := e.(string); ok {
+			m[k] = s
+		}
+	}
+	return m
+}
+
+// rawState covers both the on-disk state format (version 4) and the
+// output of `terraform show -json`.
+type rawState struct {
+	// terraform.tfstate
+	Version   int           `json:"version"`
+	Resources []rawResource `json:"resources"`
+
+	// terraform show -json
+	FormatVersion string     `json:"format_version"`
+	Values        *rawValues `json:"values"`
+}
+
+type rawResource struct {
+	Module    string        `json:"module"`
+	Mode      string        `json:"mode"`
+	Type      string        `json:"type"`
+	Name      string        `json:"name"`
+	Instances []rawInstance `json:"instances"`
+}
+
+type rawInstance struct {
+	IndexKey   interface{}            `json:"index_key"`
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+type rawValues struct {
+	RootModule rawModule `json:"root_module"`
+}
+
+type rawModule struct {
+	Resources    []rawShowResource `json:"resources"`
+	ChildModules []rawModule       `json:"child_modules"`
+}
+
+type rawShowResource struct {
+	Address string                 `json:"address"`
+	Mode    string                 `json:"mode"`
+	Type    string                 `json:"type"`
+	Values  map[string]interface{} `json:"values"`
+}
+
+// ParseState reads either a state file or `terraform show -json` output and
+// returns every managed resource instance whose type is accepted by filter.
+func ParseState(data []byte, filter func(resourceType string) bool) ([]Instance, error) {
+	var state rawState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("decoding state: %w", err)
+	}
+
+	var instances []Instance
+
+	switch {
+	case state.Values != nil:
+		instances = collectModule(state.Values.RootModule, filter, instances)
+	case state.FormatVersion != "":
+		// `terraform show -json` of an empty state has no values object.
+	case state.Version == 4:
+		for _, r := range state.Resources {
+			if r.Mode != "managed" || !filter(r.Type) {
+				continue
+			}
+			for _, inst := range r.Instances {
+				instances = append(instances, Instance{
+					Address:    stateAddress(r, inst.IndexKey),
+					Type:       r.Type,
+					Attributes: inst.Attributes,
+				})
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported state format version %d", state.Version)
+	}
+
+	return instances, nil
+}
+
+func collectModule(m rawModule, filter func(string) bool, instances []Instance) []Instance {
+	for _, r := range m.Resources {
+		if r.Mode != "managed" || !filter(r.Type) {
+			continue
+		}
+		instances = append(instances, Instance{
+			Address:    r.Address,
+			Type:       r.Type,
+			Attributes: r.Values,
+		})
+	}
+	for _, child := range m.ChildModules {
+		instances = collectModule(child, filter, instances)
+	}
+	return instances
+}
+
+func stateAddress(r rawResource, indexKey interface{}) string {
+	var b strings.Builder
+
+	if r.Module != "" {
+		b.WriteString(r.Module)
+		b.WriteString(".")
+	}
+	b.WriteString(r.Type)
+	b.WriteString(".")
+	b.WriteString(r.Name)
+
+	switch k := indexKey.(type) {
+	case string:
+		fmt.Fprintf(&b, "[%q]", k)
+	case float64:
+		fmt.Fprintf(&b, "[%d]", int(k))
+	}
+
+	return b.String()
+}
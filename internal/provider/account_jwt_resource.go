@@ -0,0 +1,1117 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/objectvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &AccountJWT{}
+var _ resource.ResourceWithModifyPlan = &AccountJWT{}
+
+func NewAccountJWT() resource.Resource {
+	return &AccountJWT{}
+}
+
+// AccountJWT defines the resource implementation.
+type AccountJWT struct {
+	provider *providerData
+}
+
+// AccountJWTModel describes the resource data model.
+type AccountJWTModel struct {
+	PublicKey          types.String                    `tfsdk:"public_key"`
+	IssuerSeed         types.String                    `tfsdk:"issuer_seed"`
+	OperatorJWT        types.String                    `tfsdk:"operator_jwt"`
+	Name               types.String                    `tfsdk:"name"`
+	Description        types.String                    `tfsdk:"description"`
+	InfoURL            types.String                    `tfsdk:"info_url"`
+	Tags               types.Set                       `tfsdk:"tags"`
+	SigningKeys        types.Set                       `tfsdk:"signing_keys"`
+	ScopedKeys         []ScopedSigningKeyModel         `tfsdk:"scoped_signing_keys"`
+	Limits             *AccountLimitsModel             `tfsdk:"limits"`
+	JetStream          *JetStreamLimitsModel           `tfsdk:"jetstream_limits"`
+	JetStreamTiers     map[string]JetStreamLimitsModel `tfsdk:"jetstream_tiered_limits"`
+	Exports            []AccountExportModel            `tfsdk:"exports"`
+	Imports            []AccountImportModel            `tfsdk:"imports"`
+	SystemImports      *SystemImportsModel             `tfsdk:"system_imports"`
+	Mappings           map[string]AccountMappingModel  `tfsdk:"mappings"`
+	Revocations        map[string]types.String         `tfsdk:"revocations"`
+	RevokedUsers       []RevokedUsersModel             `tfsdk:"revoked_users"`
+	Authorization      *AuthorizationModel             `tfsdk:"authorization"`
+	DefaultPerms       *PermissionsModel               `tfsdk:"default_permissions"`
+	ClusterTraffic     types.String                    `tfsdk:"cluster_traffic"`
+	ExpiresAt          types.String                    `tfsdk:"expires_at"`
+	NotBefore          types.String                    `tfsdk:"not_before"`
+	RenewBefore        types.String                    `tfsdk:"renew_before"`
+	IssuedAt           types.String                    `tfsdk:"issued_at"`
+	DeterministicID    types.Bool                      `tfsdk:"deterministic_id"`
+	JWTVersion         types.Int64                     `tfsdk:"jwt_version"`
+	Claims             types.Dynamic                   `tfsdk:"claims"`
+	Issuer             types.String                    `tfsdk:"issuer"`
+	SigningRequest     types.String                    `tfsdk:"signing_request"`
+	SigningRequestHash types.String                    `tfsdk:"signing_request_sha256"`
+	SignedJWT          types.String                    `tfsdk:"signed_jwt"`
+	Signature          types.String                    `tfsdk:"signature"`
+	JWT                types.String                    `tfsdk:"jwt"`
+}
+
+// ScopedSigningKeyModel describes a signing key whose users are bound to
+// the permissions and limits of its role.
+type ScopedSigningKeyModel struct {
+	Key         types.String      `tfsdk:"key"`
+	Role        types.String      `tfsdk:"role"`
+	Description types.String      `tfsdk:"description"`
+	Permissions *PermissionsModel `tfsdk:"permissions"`
+	Responses   *ResponsesModel   `tfsdk:"allow_responses"`
+	Limits      *UserLimitsModel  `tfsdk:"limits"`
+	BearerToken types.Bool        `tfsdk:"bearer_token"`
+	ConnTypes   types.Set         `tfsdk:"allowed_connection_types"`
+}
+
+// RevokedUsersModel describes users of an account revoked at once.
+type RevokedUsersModel struct {
+	PublicKeys types.Set    `tfsdk:"public_keys"`
+	RevokedAt  types.String `tfsdk:"revoked_at"`
+}
+
+// JetStreamLimitsModel describes the JetStream limits of an account or of
+// one of its replication tiers. Unset limits are unlimited.
+type JetStreamLimitsModel struct {
+	MemoryStorage        types.Int64 `tfsdk:"memory_storage"`
+	DiskStorage          types.Int64 `tfsdk:"disk_storage"`
+	Streams              types.Int64 `tfsdk:"streams"`
+	Consumers            types.Int64 `tfsdk:"consumers"`
+	MaxAckPending        types.Int64 `tfsdk:"max_ack_pending"`
+	MemoryMaxStreamBytes types.Int64 `tfsdk:"memory_max_stream_bytes"`
+	DiskMaxStreamBytes   types.Int64 `tfsdk:"disk_max_stream_bytes"`
+	MaxBytesRequired     types.Bool  `tfsdk:"max_bytes_required"`
+}
+
+// AccountExportModel describes a stream or service export of an account.
+type AccountExportModel struct {
+	Name          types.String `tfsdk:"name"`
+	Description   types.String `tfsdk:"description"`
+	InfoURL       types.String `tfsdk:"info_url"`
+	Subject       types.String `tfsdk:"subject"`
+	Type          types.String `tfsdk:"type"`
+	TokenRequired types.Bool   `tfsdk:"token_required"`
+	ResponseType  types.String `tfsdk:"response_type"`
+}
+
+// AccountImportModel describes a stream or service import of an account.
+type AccountImportModel struct {
+	Name         types.String `tfsdk:"name"`
+	Account      types.String `tfsdk:"account"`
+	Subject      types.String `tfsdk:"subject"`
+	LocalSubject types.String `tfsdk:"local_subject"`
+	Type         types.String `tfsdk:"type"`
+	Token        types.String `tfsdk:"token"`
+}
+
+// SystemImportsModel describes the imports of the monitoring exports of the
+// system account.
+type SystemImportsModel struct {
+	Account  types.String `tfsdk:"account"`
+	Streams  types.Bool   `tfsdk:"streams"`
+	Services types.Bool   `tfsdk:"services"`
+}
+
+// AccountMappingModel describes the destinations of a subject mapping.
+type AccountMappingModel struct {
+	Destinations []MappingDestinationModel `tfsdk:"destinations"`
+}
+
+// MappingDestinationModel is a weighted destination of a subject mapping.
+type MappingDestinationModel struct {
+	Subject types.String `tfsdk:"subject"`
+	Weight  types.Int64  `tfsdk:"weight"`
+	Cluster types.String `tfsdk:"cluster"`
+}
+
+// AuthorizationModel describes the auth callout configuration of an
+// account.
+type AuthorizationModel struct {
+	AuthUsers       types.Set    `tfsdk:"auth_users"`
+	AllowedAccounts types.Set    `tfsdk:"allowed_accounts"`
+	XKey            types.String `tfsdk:"xkey"`
+}
+
+// AccountLimitsModel describes the limits block of an account JWT. Unset
+// limits are unlimited.
+type AccountLimitsModel struct {
+	Subscriptions       types.Int64 `tfsdk:"subscriptions"`
+	Data                types.Int64 `tfsdk:"data"`
+	Payload             types.Int64 `tfsdk:"payload"`
+	Imports             types.Int64 `tfsdk:"imports"`
+	Exports             types.Int64 `tfsdk:"exports"`
+	WildcardExports     types.Bool  `tfsdk:"wildcard_exports"`
+	DisallowBearer      types.Bool  `tfsdk:"disallow_bearer"`
+	Connections         types.Int64 `tfsdk:"connections"`
+	LeafNodeConnections types.Int64 `tfsdk:"leaf_node_connections"`
+}
+
+func (r *AccountJWT) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_account_jwt"
+}
+
+func (r *AccountJWT) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "An account JWT signed by the operator or one of its signing keys. The JWT is issued " +
+			"again whenever its claims change.",
+
+		Attributes: map[string]schema.Attribute{
+			"public_key": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Public key of the account the JWT is issued for",
+			},
+			"issuer_seed": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Seed of the operator or an operator signing key the JWT is signed with",
+				Sensitive:           true,
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("issuer")),
+				},
+			},
+			"operator_jwt": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "JWT of the operator the account belongs to, e.g. `nkey_operator_jwt.this.jwt`. If set, " +
+					"the account is checked against the operator during plan, or on apply if the operator JWT or other " +
+					"attributes are not known yet: the issuer must be the operator or one of its signing keys, only a " +
+					"signing key if the operator enforces strict signing key usage, and the account must not outlive the " +
+					"operator. Guards against signing an account with the key of another operator",
+			},
+			"name": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Name of the account",
+			},
+			"description": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Description of the account",
+			},
+			"info_url": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "URL of further information about the account, e.g. its owner's documentation",
+			},
+			"tags": schema.SetAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Tags of the account. Tags are lowercased",
+			},
+			"signing_keys": schema.SetAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Account public keys that may sign user JWTs on behalf of the account",
+			},
+			"scoped_signing_keys": schema.ListNestedAttribute{
+				Optional: true,
+				MarkdownDescription: "Account public keys that may sign user JWTs on behalf of the account, restricted to " +
+					"a role. The permissions and limits of the role apply to every user signed with the key, " +
+					"regardless of the permissions and limits in the user JWT",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"key": schema.StringAttribute{
+							Required:            true,
+							MarkdownDescription: "Account public key of the signing key",
+						},
+						"role": schema.StringAttribute{
+							Required:            true,
+							MarkdownDescription: "Name of the role",
+						},
+						"description": schema.StringAttribute{
+							Optional:            true,
+							MarkdownDescription: "Description of the role",
+						},
+						"permissions": schema.SingleNestedAttribute{
+							Optional:            true,
+							MarkdownDescription: "Publish and subscribe permissions of the users of the role",
+							Attributes:          permissionsResourceAttributes(),
+						},
+						"allow_responses": schema.SingleNestedAttribute{
+							Optional:            true,
+							MarkdownDescription: "Response permissions of the users of the role, see `nkey_user_jwt`",
+							Attributes:          responsesAttributes(),
+						},
+						"limits": schema.SingleNestedAttribute{
+							Optional:            true,
+							MarkdownDescription: "Limits of the users of the role. Unset limits are unlimited",
+							Attributes:          userLimitsAttributes(),
+						},
+						"bearer_token": schema.BoolAttribute{
+							Optional:            true,
+							MarkdownDescription: "Whether the users of the role authenticate with bearer tokens",
+						},
+						"allowed_connection_types": connectionTypesAttribute("Connection types the users of the role may " +
+							"connect with, see `nkey_user_jwt`"),
+					},
+				},
+			},
+			"limits": schema.SingleNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "Limits of the account. Unset limits are unlimited",
+				Attributes:          accountLimitsAttributes(),
+			},
+			"jetstream_limits": schema.SingleNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "JetStream limits of the account, enables JetStream for it. Unset limits are unlimited",
+				Attributes:          jetStreamLimitsAttributes(),
+				Validators: []validator.Object{
+					objectvalidator.ConflictsWith(path.MatchRoot("jetstream_tiered_limits")),
+				},
+			},
+			"jetstream_tiered_limits": schema.MapNestedAttribute{
+				Optional: true,
+				MarkdownDescription: "JetStream limits per replication tier, e.g. `R1` and `R3`, enables JetStream for the " +
+					"account. Unset limits are unlimited",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: jetStreamLimitsAttributes(),
+				},
+			},
+			"exports": schema.ListNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "Streams and services the account shares with other accounts",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Optional:            true,
+							MarkdownDescription: "Name of the export",
+						},
+						"description": schema.StringAttribute{
+							Optional:            true,
+							MarkdownDescription: "Description of the export",
+						},
+						"info_url": schema.StringAttribute{
+							Optional:            true,
+							MarkdownDescription: "URL of further information about the export",
+						},
+						"subject": schema.StringAttribute{
+							Required:            true,
+							MarkdownDescription: "Subject of the export. May contain wildcards",
+						},
+						"type": schema.StringAttribute{
+							Required:            true,
+							MarkdownDescription: "Type of the export. Must be one of stream|service",
+							Validators: []validator.String{
+								stringvalidator.OneOf("stream", "service"),
+							},
+						},
+						"token_required": schema.BoolAttribute{
+							Optional:            true,
+							MarkdownDescription: "Whether importing accounts need an activation token, see `nkey_activation_jwt`",
+						},
+						"response_type": schema.StringAttribute{
+							Optional: true,
+							MarkdownDescription: "Responses a service sends per request. Must be one of Singleton|Stream|Chunked, " +
+								"defaults to Singleton. Only valid for services",
+							Validators: []validator.String{
+								stringvalidator.OneOf(string(jwt.ResponseTypeSingleton), string(jwt.ResponseTypeStream), string(jwt.ResponseTypeChunked)),
+							},
+						},
+					},
+				},
+			},
+			"imports": schema.ListNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "Streams and services the account uses from other accounts",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Optional:            true,
+							MarkdownDescription: "Name of the import",
+						},
+						"account": schema.StringAttribute{
+							Required:            true,
+							MarkdownDescription: "Public key of the exporting account",
+						},
+						"subject": schema.StringAttribute{
+							Required:            true,
+							MarkdownDescription: "Subject of the export to import",
+						},
+						"local_subject": schema.StringAttribute{
+							Optional: true,
+							MarkdownDescription: "Subject the import is available under in this account. Wildcards of `subject` " +
+								"can be referenced as `$1`, `$2`, ... Defaults to `subject`",
+						},
+						"type": schema.StringAttribute{
+							Required:            true,
+							MarkdownDescription: "Type of the export. Must be one of stream|service",
+							Validators: []validator.String{
+								stringvalidator.OneOf("stream", "service"),
+							},
+						},
+						"token": schema.StringAttribute{
+							Optional:            true,
+							MarkdownDescription: "Activation token of the export if it requires one, see `nkey_activation_jwt`",
+						},
+					},
+				},
+			},
+			"system_imports": schema.SingleNestedAttribute{
+				Optional: true,
+				MarkdownDescription: "Import the `$SYS` monitoring streams and services of the system account for this " +
+					"account, as `nkey_system_account` exports them, instead of writing the imports by hand",
+				Attributes: map[string]schema.Attribute{
+					"account": schema.StringAttribute{
+						Required:            true,
+						MarkdownDescription: "Public key of the system account, e.g. `nkey_system_account.this.public_key`",
+					},
+					"streams": schema.BoolAttribute{
+						Optional: true,
+						MarkdownDescription: "Import the account monitoring streams `$SYS.ACCOUNT.<account>.>`, such as the " +
+							"connect and disconnect events of the account. Defaults to true",
+					},
+					"services": schema.BoolAttribute{
+						Optional: true,
+						MarkdownDescription: "Import the account monitoring services `$SYS.REQ.ACCOUNT.<account>.*`, such as " +
+							"`CONNZ` and `JSZ`. Defaults to true",
+					},
+				},
+			},
+			"mappings": schema.MapNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "Map of source subjects to the destinations messages published to them are mapped to",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"destinations": schema.ListNestedAttribute{
+							Required: true,
+							MarkdownDescription: "Destinations of the mapping. Messages are distributed by weight, the " +
+								"weights of the destinations for the same cluster must not exceed 100",
+							NestedObject: schema.NestedAttributeObject{
+								Attributes: map[string]schema.Attribute{
+									"subject": schema.StringAttribute{
+										Required: true,
+										MarkdownDescription: "Destination subject. Wildcards of the source subject can be " +
+											"referenced as `{{wildcard(1)}}`, ...",
+									},
+									"weight": schema.Int64Attribute{
+										Optional:            true,
+										MarkdownDescription: "Percentage of messages mapped to the destination. Defaults to 100",
+										Validators: []validator.Int64{
+											int64validator.Between(1, 100),
+										},
+									},
+									"cluster": schema.StringAttribute{
+										Optional:            true,
+										MarkdownDescription: "Only apply the weight to messages published in this cluster",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"revocations": schema.MapAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				MarkdownDescription: "Map of user public keys to an RFC 3339 timestamp. User JWTs of the user issued " +
+					"before the timestamp are rejected. Use `*` as key to revoke the JWTs of all users. Timestamps must not be " +
+					"null: a revocation is pinned to the time it was made, not to the time of the apply",
+			},
+			"revoked_users": schema.ListNestedAttribute{
+				Optional: true,
+				MarkdownDescription: "Lists of users revoked at once, e.g. to cut off all users of a compromised " +
+					"application. User JWTs of the users issued before `revoked_at` are rejected. A user revoked more " +
+					"than once, here or in `revocations`, is revoked at the latest timestamp. Include `*` to revoke the " +
+					"JWTs of all users of the account",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"public_keys": schema.SetAttribute{
+							Required:            true,
+							ElementType:         types.StringType,
+							MarkdownDescription: "Public keys of the users to revoke, or `*` for all users",
+						},
+						"revoked_at": schema.StringAttribute{
+							Required:            true,
+							MarkdownDescription: "RFC 3339 timestamp the users are revoked at",
+						},
+					},
+				},
+			},
+			"default_permissions": schema.SingleNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "Publish and subscribe permissions of the users of the account without permissions of their own",
+				Attributes:          permissionsResourceAttributes(),
+			},
+			"cluster_traffic": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "Account whose connections carry the cluster traffic of the account, such as " +
+					"JetStream replication. Must be one of owner|system, defaults to system. Requires nats-server 2.11",
+				Validators: []validator.String{
+					stringvalidator.OneOf(jwt.ClusterTrafficOwner, jwt.ClusterTrafficSystem),
+				},
+			},
+			"authorization": schema.SingleNestedAttribute{
+				Optional: true,
+				MarkdownDescription: "Auth callout configuration. Users connecting to the account are authorized by an " +
+					"external service, which responds with a user JWT",
+				Attributes: map[string]schema.Attribute{
+					"auth_users": schema.SetAttribute{
+						Required:            true,
+						ElementType:         types.StringType,
+						MarkdownDescription: "Public keys of the users of the authorization service, which bypass the auth callout",
+					},
+					"allowed_accounts": schema.SetAttribute{
+						Optional:    true,
+						ElementType: types.StringType,
+						MarkdownDescription: "Public keys of the accounts the authorization service may place users in, " +
+							"`*` for any account. Only the account itself if empty",
+					},
+					"xkey": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "Curve public key of the authorization service the callout requests are encrypted for",
+					},
+				},
+			},
+			"expires_at":       expiresAtAttribute(),
+			"not_before":       notBeforeAttribute(),
+			"renew_before":     renewBeforeAttribute(),
+			"issued_at":        issuedAtAttribute(),
+			"deterministic_id": deterministicIDAttribute(),
+			"jwt_version":      jwtVersionAttribute(),
+			"issuer": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				MarkdownDescription: "Public key of the operator or signing key the JWT was signed with. Set it instead " +
+					"of `issuer_seed` to sign the JWT offline, see `signing_request`",
+			},
+			"signing_request":        signingRequestAttribute(),
+			"signing_request_sha256": signingRequestHashAttribute(),
+			"signed_jwt":             signedJWTAttribute("issuer_seed"),
+			"signature":              signatureAttribute("issuer_seed"),
+			"jwt": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The encoded account JWT. Empty while a JWT signed offline awaits its signature",
+			},
+			"claims": claimsAttribute(),
+		},
+	}
+}
+
+// accountLimitsAttributes returns the attributes of an AccountLimitsModel.
+func accountLimitsAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"subscriptions": schema.Int64Attribute{
+			Optional:            true,
+			MarkdownDescription: "Maximum number of subscriptions",
+		},
+		"data": schema.Int64Attribute{
+			Optional:            true,
+			MarkdownDescription: "Maximum number of bytes",
+		},
+		"payload": schema.Int64Attribute{
+			Optional:            true,
+			MarkdownDescription: "Maximum message payload in bytes",
+		},
+		"imports": schema.Int64Attribute{
+			Optional:            true,
+			MarkdownDescription: "Maximum number of imports",
+		},
+		"exports": schema.Int64Attribute{
+			Optional:            true,
+			MarkdownDescription: "Maximum number of exports",
+		},
+		"wildcard_exports": schema.BoolAttribute{
+			Optional:            true,
+			MarkdownDescription: "Whether exports may contain wildcards. Defaults to true",
+		},
+		"disallow_bearer": schema.BoolAttribute{
+			Optional:            true,
+			MarkdownDescription: "Reject bearer token user JWTs",
+		},
+		"connections": schema.Int64Attribute{
+			Optional:            true,
+			MarkdownDescription: "Maximum number of active connections",
+		},
+		"leaf_node_connections": schema.Int64Attribute{
+			Optional:            true,
+			MarkdownDescription: "Maximum number of active leaf node connections",
+		},
+	}
+}
+
+// jetStreamLimitsAttributes returns the attributes of a JetStreamLimitsModel.
+func jetStreamLimitsAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"memory_storage": schema.Int64Attribute{
+			Optional:            true,
+			MarkdownDescription: "Maximum number of bytes stored in memory across all streams. 0 disables memory storage",
+		},
+		"disk_storage": schema.Int64Attribute{
+			Optional:            true,
+			MarkdownDescription: "Maximum number of bytes stored on disk across all streams. 0 disables disk storage",
+		},
+		"streams": schema.Int64Attribute{
+			Optional:            true,
+			MarkdownDescription: "Maximum number of streams",
+		},
+		"consumers": schema.Int64Attribute{
+			Optional:            true,
+			MarkdownDescription: "Maximum number of consumers",
+		},
+		"max_ack_pending": schema.Int64Attribute{
+			Optional:            true,
+			MarkdownDescription: "Maximum number of unacknowledged messages of a consumer",
+		},
+		"memory_max_stream_bytes": schema.Int64Attribute{
+			Optional:            true,
+			MarkdownDescription: "Maximum number of bytes of a memory backed stream",
+		},
+		"disk_max_stream_bytes": schema.Int64Attribute{
+			Optional:            true,
+			MarkdownDescription: "Maximum number of bytes of a disk backed stream",
+		},
+		"max_bytes_required": schema.BoolAttribute{
+			Optional:            true,
+			MarkdownDescription: "Whether every stream must set a maximum number of bytes",
+		},
+	}
+}
+
+func (r *AccountJWT) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	// Claims the target server does not support fail the plan.
+	if !req.Plan.Raw.IsNull() {
+		r.provider.checkServerVersion(usedFeatures(req.Config.Raw, accountServerFeatures), &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Nothing to do on destroy. Accounts with attributes that are not known
+	// yet, such as the public key of an account created in the same apply,
+	// are checked on apply.
+	if req.Plan.Raw.IsNull() || !req.Config.Raw.IsFullyKnown() {
+		return
+	}
+
+	var plan AccountJWTModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !plan.OperatorJWT.IsNull() {
+		r.checkPlan(ctx, &plan, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Nothing to compare against on create
+	if req.State.Raw.IsNull() {
+		return
+	}
+
+	var state AccountJWTModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The JWT is only signed again if its claims change. Errors are reported
+	// on apply.
+	var diags diag.Diagnostics
+	r.issue(ctx, &plan, &diags)
+	issued, previous := plan.JWT, state.JWT
+	if plan.IssuerSeed.IsNull() {
+		issued, previous = plan.SigningRequest, state.SigningRequest
+	}
+	if diags.HasError() || !sameClaims(issued, previous,
+		!plan.ExpiresAt.Equal(state.ExpiresAt) || !plan.NotBefore.Equal(state.NotBefore),
+		!plan.IssuedAt.Equal(state.IssuedAt) || !plan.DeterministicID.Equal(state.DeterministicID),
+		r.provider.provenanceKeys()...) {
+		// The claims issued again on apply only differ in those that
+		// change with the issuance.
+		if !diags.HasError() {
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("claims"),
+				claimsValue(issued, &resp.Diagnostics, volatileClaims(r.provider, plan.IssuedAt, plan.ExpiresAt, plan.NotBefore)...))...)
+		}
+		return
+	}
+
+	token := state.JWT
+	if plan.IssuerSeed.IsNull() {
+		var err error
+		signed, p := offlineJWT(state.SigningRequest, plan.SignedJWT, plan.Signature)
+		if token, err = signedJWT(state.SigningRequest, signed, p); err != nil {
+			addError(&resp.Diagnostics, "Invalid signed JWT", err)
+		}
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("issuer"), state.Issuer)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("signing_request"), state.SigningRequest)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("signing_request_sha256"), state.SigningRequestHash)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("jwt"), token)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("claims"), claimsValue(previous, &resp.Diagnostics))...)
+}
+
+// checkPlan checks the planned account against its operator JWT before
+// anything is signed.
+func (r *AccountJWT) checkPlan(ctx context.Context, plan *AccountJWTModel, diags *diag.Diagnostics) {
+	keys, err := issuerSigner(plan.IssuerSeed, path.Root("issuer_seed"), plan.Issuer, path.Root("issuer"), nkeys.PrefixByteOperator)
+	if err != nil {
+		addError(diags, "Invalid issuer", err)
+		return
+	}
+	defer keys.Wipe()
+
+	issuer, err := keys.PublicKey()
+	if err != nil {
+		addError(diags, "Invalid issuer seed", errorAt(path.Root("issuer_seed"), err))
+		return
+	}
+
+	claims := plan.claims(ctx, diags)
+	if diags.HasError() {
+		return
+	}
+
+	plan.checkOperator(claims, issuer, diags)
+}
+
+func (r *AccountJWT) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.provider = data
+}
+
+func (r *AccountJWT) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var data AccountJWTModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.issue(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Trace(ctx, "created account JWT resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.provider.register(ctx, "nkey_account_jwt", data.JWT, &resp.Diagnostics)
+}
+
+func (r *AccountJWT) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data AccountJWTModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A JWT close to expiry is issued again on the next apply.
+	if renewalDue(data.JWT, data.RenewBefore) {
+		tflog.Info(ctx, "JWT is due for renewal, removing it from state", map[string]interface{}{
+			"renew_before": data.RenewBefore.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Claims are missing from the state of earlier versions.
+	if data.Claims.IsNull() {
+		token := data.JWT
+		if token.IsNull() {
+			token = data.SigningRequest
+		}
+		data.Claims = claimsValue(token, &resp.Diagnostics)
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AccountJWT) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var plan AccountJWTModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The JWT in state is kept if its claims did not change.
+	reissued := plan.JWT.IsUnknown()
+	if reissued {
+		r.issue(ctx, &plan, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if reissued && !plan.JWT.IsNull() {
+		r.provider.notify(ctx, jwtEvent("reissued", "nkey_account_jwt", plan.JWT), &resp.Diagnostics)
+		r.provider.register(ctx, "nkey_account_jwt", plan.JWT, &resp.Diagnostics)
+	}
+}
+
+func (r *AccountJWT) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Nothing to do here as the JWT only exists in state
+}
+
+// issue encodes the account claims of data and signs them with the issuer
+// seed, or encodes them as signing request if the JWT is signed offline.
+func (r *AccountJWT) issue(ctx context.Context, data *AccountJWTModel, diags *diag.Diagnostics) {
+	keys, err := issuerSigner(data.IssuerSeed, path.Root("issuer_seed"), data.Issuer, path.Root("issuer"), nkeys.PrefixByteOperator)
+	if err != nil {
+		addError(diags, "Invalid issuer", err)
+		return
+	}
+	defer keys.Wipe()
+
+	issuer, err := keys.PublicKey()
+	if err != nil {
+		addError(diags, "Invalid issuer seed", errorAt(path.Root("issuer_seed"), err))
+		return
+	}
+
+	claims := data.claims(ctx, diags)
+	if diags.HasError() {
+		return
+	}
+	claims.Tags.Add(r.provider.provenanceTags("nkey_account_jwt", claims.Name)...)
+
+	// The operator JWT may not have been known during plan.
+	data.checkOperator(claims, issuer, diags)
+	if diags.HasError() {
+		return
+	}
+
+	token, err := jwtEncoder(data.JWTVersion)(claims, keys, data.DeterministicID.ValueBool(), diags)
+	if err != nil {
+		addError(diags, "Unable to issue account JWT", err)
+		return
+	}
+	data.Claims = claimsValue(types.StringValue(token), diags)
+
+	data.Issuer = types.StringValue(issuer)
+	if data.IssuerSeed.IsNull() {
+		data.SigningRequest = types.StringValue(token)
+		data.SigningRequestHash = signingRequestHash(data.SigningRequest)
+		signed, p := offlineJWT(data.SigningRequest, data.SignedJWT, data.Signature)
+		data.JWT = awaitSignature(data.SigningRequest, signed, p, diags)
+		return
+	}
+	data.SigningRequest = types.StringNull()
+	data.SigningRequestHash = types.StringNull()
+	data.JWT = types.StringValue(token)
+}
+
+// claims returns the account claims described by m.
+func (m *AccountJWTModel) claims(ctx context.Context, diags *diag.Diagnostics) *jwt.AccountClaims {
+	if err := checkPublicKey(m.PublicKey.ValueString(), nkeys.PrefixByteAccount); err != nil {
+		addError(diags, "Invalid account public key", errorAt(path.Root("public_key"), err))
+		return nil
+	}
+
+	claims := jwt.NewAccountClaims(m.PublicKey.ValueString())
+	claims.Name = m.Name.ValueString()
+	claims.Description = m.Description.ValueString()
+	claims.InfoURL = m.InfoURL.ValueString()
+	claims.Tags.Add(tagList(ctx, m.Tags, diags)...)
+	claims.SigningKeys.Add(publicKeys(ctx, m.SigningKeys, path.Root("signing_keys"), nkeys.PrefixByteAccount, diags)...)
+	for i, k := range m.ScopedKeys {
+		if err := checkPublicKey(k.Key.ValueString(), nkeys.PrefixByteAccount); err != nil {
+			addError(diags, "Invalid public key", errorAt(path.Root("scoped_signing_keys").AtListIndex(i).AtName("key"), err))
+		}
+
+		scope := jwt.NewUserScope()
+		scope.Key = k.Key.ValueString()
+		scope.Role = k.Role.ValueString()
+		scope.Description = k.Description.ValueString()
+		scope.Template.Permissions = k.Permissions.jwtPermissions()
+		scope.Template.Resp = k.Responses.jwtResponses(path.Root("scoped_signing_keys").AtListIndex(i).AtName("allow_responses"), diags)
+		if k.Limits != nil {
+			scope.Template.Limits = k.Limits.jwtLimits()
+		}
+		scope.Template.BearerToken = k.BearerToken.ValueBool()
+		diags.Append(k.ConnTypes.ElementsAs(ctx, &scope.Template.AllowedConnectionTypes, false)...)
+		claims.SigningKeys.AddScopedSigner(scope)
+	}
+
+	setValidity(&claims.ClaimsData, m.IssuedAt, m.ExpiresAt, m.NotBefore, m.RenewBefore, diags)
+
+	for _, e := range m.Exports {
+		claims.Exports.Add(&jwt.Export{
+			Name:         e.Name.ValueString(),
+			Subject:      jwt.Subject(e.Subject.ValueString()),
+			Type:         exportType(e.Type.ValueString()),
+			TokenReq:     e.TokenRequired.ValueBool(),
+			ResponseType: jwt.ResponseType(e.ResponseType.ValueString()),
+			Info: jwt.Info{
+				Description: e.Description.ValueString(),
+				InfoURL:     e.InfoURL.ValueString(),
+			},
+		})
+	}
+
+	for i, im := range m.Imports {
+		if err := checkPublicKey(im.Account.ValueString(), nkeys.PrefixByteAccount); err != nil {
+			addError(diags, "Invalid import", errorAt(path.Root("imports").AtListIndex(i).AtName("account"), err))
+		}
+		claims.Imports.Add(&jwt.Import{
+			Name:         im.Name.ValueString(),
+			Account:      im.Account.ValueString(),
+			Subject:      jwt.Subject(im.Subject.ValueString()),
+			LocalSubject: jwt.RenamingSubject(im.LocalSubject.ValueString()),
+			Type:         exportType(im.Type.ValueString()),
+			Token:        im.Token.ValueString(),
+		})
+	}
+
+	if si := m.SystemImports; si != nil {
+		if err := checkPublicKey(si.Account.ValueString(), nkeys.PrefixByteAccount); err != nil {
+			addError(diags, "Invalid system imports", errorAt(path.Root("system_imports").AtName("account"), err))
+		}
+		claims.Imports.Add(systemAccountImports(si.Account.ValueString(), m.PublicKey.ValueString(),
+			si.Streams.IsNull() || si.Streams.ValueBool(), si.Services.IsNull() || si.Services.ValueBool())...)
+	}
+
+	if len(m.Mappings) > 0 {
+		claims.Mappings = jwt.Mapping{}
+		for from, mapping := range m.Mappings {
+			to := make([]jwt.WeightedMapping, len(mapping.Destinations))
+			for i, dest := range mapping.Destinations {
+				to[i] = jwt.WeightedMapping{
+					Subject: jwt.Subject(dest.Subject.ValueString()),
+					Weight:  uint8(limit(dest.Weight, 100)),
+					Cluster: dest.Cluster.ValueString(),
+				}
+			}
+			claims.AddMapping(jwt.Subject(from), to...)
+		}
+	}
+
+	for user, at := range m.Revocations {
+		p := path.Root("revocations").AtMapKey(user)
+		if user != jwt.All {
+			if err := checkPublicKey(user, nkeys.PrefixByteUser); err != nil {
+				addError(diags, "Invalid revocation", errorAt(p, err))
+				continue
+			}
+		}
+
+		// A null timestamp would be encoded as 0 and revoke nothing, and
+		// taking the current time instead would revoke the user again on
+		// every apply.
+		if at.IsNull() {
+			diags.AddAttributeError(p, "Invalid revocation",
+				fmt.Sprintf("the revocation of %s has no timestamp, set it to the RFC 3339 timestamp the user is revoked at", user))
+			continue
+		}
+
+		revokedAt, err := jwtTime(at, p)
+		if err != nil {
+			addError(diags, "Invalid revocation", err)
+			continue
+		}
+		claims.RevokeAt(user, time.Unix(revokedAt, 0))
+	}
+
+	for i, revoked := range m.RevokedUsers {
+		p := path.Root("revoked_users").AtListIndex(i)
+		revokedAt, err := jwtTime(revoked.RevokedAt, p.AtName("revoked_at"))
+		if err != nil {
+			addError(diags, "Invalid revocation", err)
+			continue
+		}
+
+		var users []string
+		diags.Append(revoked.PublicKeys.ElementsAs(ctx, &users, false)...)
+		for _, user := range users {
+			if user != jwt.All {
+				if err := checkPublicKey(user, nkeys.PrefixByteUser); err != nil {
+					addError(diags, "Invalid revocation", errorAt(p.AtName("public_keys").AtSetValue(types.StringValue(user)), err))
+					continue
+				}
+			}
+			claims.RevokeAt(user, time.Unix(revokedAt, 0))
+		}
+	}
+
+	claims.DefaultPermissions = m.DefaultPerms.jwtPermissions()
+	claims.ClusterTraffic = jwt.ClusterTraffic(m.ClusterTraffic.ValueString())
+
+	if a := m.Authorization; a != nil {
+		p := path.Root("authorization")
+		claims.Authorization.AuthUsers.Add(publicKeys(ctx, a.AuthUsers, p.AtName("auth_users"), nkeys.PrefixByteUser, diags)...)
+
+		var accounts []string
+		diags.Append(a.AllowedAccounts.ElementsAs(ctx, &accounts, false)...)
+		for _, account := range accounts {
+			if account == jwt.AnyAccount {
+				continue
+			}
+			if err := checkPublicKey(account, nkeys.PrefixByteAccount); err != nil {
+				addError(diags, "Invalid public key", errorAt(p.AtName("allowed_accounts").AtSetValue(types.StringValue(account)), err))
+			}
+		}
+		claims.Authorization.AllowedAccounts.Add(accounts...)
+
+		if !a.XKey.IsNull() {
+			if err := checkPublicKey(a.XKey.ValueString(), nkeys.PrefixByteCurve); err != nil {
+				addError(diags, "Invalid public key", errorAt(p.AtName("xkey"), err))
+			}
+			claims.Authorization.XKey = a.XKey.ValueString()
+		}
+	}
+
+	if l := m.Limits; l != nil {
+		claims.Limits.Subs = limit(l.Subscriptions, jwt.NoLimit)
+		claims.Limits.Data = limit(l.Data, jwt.NoLimit)
+		claims.Limits.Payload = limit(l.Payload, jwt.NoLimit)
+		claims.Limits.Imports = limit(l.Imports, jwt.NoLimit)
+		claims.Limits.Exports = limit(l.Exports, jwt.NoLimit)
+		claims.Limits.Conn = limit(l.Connections, jwt.NoLimit)
+		claims.Limits.LeafNodeConn = limit(l.LeafNodeConnections, jwt.NoLimit)
+		claims.Limits.WildcardExports = l.WildcardExports.IsNull() || l.WildcardExports.ValueBool()
+		claims.Limits.DisallowBearer = l.DisallowBearer.ValueBool()
+	}
+
+	if m.JetStream != nil {
+		claims.Limits.JetStreamLimits = m.JetStream.jwtLimits()
+	}
+	if len(m.JetStreamTiers) > 0 {
+		claims.Limits.JetStreamTieredLimits = jwt.JetStreamTieredLimits{}
+		for tier, l := range m.JetStreamTiers {
+			claims.Limits.JetStreamTieredLimits[tier] = l.jwtLimits()
+		}
+	}
+
+	return claims
+}
+
+// checkOperator checks claims, signed by issuer, against the operator JWT of
+// m if it is set.
+func (m *AccountJWTModel) checkOperator(claims *jwt.AccountClaims, issuer string, diags *diag.Diagnostics) {
+	if m.OperatorJWT.IsNull() || m.OperatorJWT.IsUnknown() {
+		return
+	}
+
+	operator, err := jwt.DecodeOperatorClaims(m.OperatorJWT.ValueString())
+	if err != nil {
+		addError(diags, "Invalid operator JWT", errorAt(path.Root("operator_jwt"), err))
+		return
+	}
+
+	issuerPath := path.Root("issuer_seed")
+	if m.IssuerSeed.IsNull() {
+		issuerPath = path.Root("issuer")
+	}
+
+	if issuer != operator.Subject && !operator.SigningKeys.Contains(issuer) {
+		diags.AddAttributeError(issuerPath, "Issuer of another operator",
+			fmt.Sprintf("%s is neither operator %s (%s) nor one of its signing keys, servers trusting the operator "+
+				"reject the account. Sign with a key of that operator or set operator_jwt to the JWT of the operator "+
+				"the account belongs to.", issuer, operator.Subject, operator.Name))
+		return
+	}
+
+	if issuer == operator.Subject && operator.StrictSigningKeyUsage {
+		diags.AddAttributeError(issuerPath, "Operator requires signing keys",
+			fmt.Sprintf("Operator %s enforces strict signing key usage, servers reject accounts signed with its "+
+				"identity key. Sign with one of its signing keys.", operator.Subject))
+	}
+
+	if operator.Expires != 0 && (claims.Expires == 0 || claims.Expires > operator.Expires) {
+		diags.AddAttributeError(path.Root("expires_at"), "Account outlives its operator",
+			fmt.Sprintf("The operator JWT expires at %s, from then on the account is not trusted. Set expires_at to "+
+				"no later than that.", time.Unix(operator.Expires, 0).UTC().Format(time.RFC3339)))
+	}
+}
+
+// accountServerFeatures are the claims of account JWTs that require a recent
+// nats-server.
+var accountServerFeatures = []serverFeature{
+	{"jetstream_tiered_limits", "JetStream tiered limits", "2.8.0"},
+	{"mappings", "Subject mappings", "2.7.0"},
+	{"authorization", "Auth callout", "2.10.0"},
+	{"cluster_traffic", "cluster_traffic", "2.11.0"},
+}
+
+// exportType returns the JWT export type of "stream" or "service".
+func exportType(t string) jwt.ExportType {
+	if t == "service" {
+		return jwt.Service
+	}
+	return jwt.Stream
+}
+
+// jwtLimits returns the JWT representation of m.
+func (m *JetStreamLimitsModel) jwtLimits() jwt.JetStreamLimits {
+	return jwt.JetStreamLimits{
+		MemoryStorage:        limit(m.MemoryStorage, jwt.NoLimit),
+		DiskStorage:          limit(m.DiskStorage, jwt.NoLimit),
+		Streams:              limit(m.Streams, jwt.NoLimit),
+		Consumer:             limit(m.Consumers, jwt.NoLimit),
+		MaxAckPending:        limit(m.MaxAckPending, jwt.NoLimit),
+		MemoryMaxStreamBytes: limit(m.MemoryMaxStreamBytes, 0),
+		DiskMaxStreamBytes:   limit(m.DiskMaxStreamBytes, 0),
+		MaxBytesRequired:     m.MaxBytesRequired.ValueBool(),
+	}
+}
+
+// limit returns the value of v, or def if it is null.
+func limit(v types.Int64, def int64) int64 {
+	if v.IsNull() {
+		return def
+	}
+	return v.ValueInt64()
+}
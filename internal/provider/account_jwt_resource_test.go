@@ -0,0 +1,460 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"sort"
+	"strings"
+	"terraform-provider-nkey/internal/subject"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestAccountJWT(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	operator, _ := nkeys.CreateOperator()
+	operatorKey, _ := operator.PublicKey()
+	operatorSeed, _ := operator.Seed()
+	signer, _ := nkeys.CreateOperator()
+	signerKey, _ := signer.PublicKey()
+	signerSeed, _ := signer.Seed()
+	account, _ := nkeys.CreateAccount()
+	accountKey, _ := account.PublicKey()
+	accountSeed, _ := account.Seed()
+	accountSigner, _ := nkeys.CreateAccount()
+	accountSignerKey, _ := accountSigner.PublicKey()
+
+	// nested returns the nested attribute name with the attributes in
+	// attrs, all other attributes are null.
+	typ := p.resourceSchema("nkey_account_jwt").ValueType().(tftypes.Object)
+	nested := func(name string, attrs map[string]tftypes.Value) tftypes.Value {
+		nestedType := typ.AttributeTypes[name].(tftypes.Object)
+		values := map[string]tftypes.Value{}
+		for name, t := range nestedType.AttributeTypes {
+			values[name] = tftypes.NewValue(t, nil)
+			if v, ok := attrs[name]; ok {
+				values[name] = v
+			}
+		}
+		return tftypes.NewValue(nestedType, values)
+	}
+
+	for name, test := range map[string]struct {
+		seed   []byte
+		issuer string
+	}{
+		"operator":    {operatorSeed, operatorKey},
+		"signing key": {signerSeed, signerKey},
+	} {
+		t.Run(name, func(t *testing.T) {
+			state := p.create("nkey_account_jwt", map[string]tftypes.Value{
+				"public_key":   stringValue(accountKey),
+				"issuer_seed":  stringValue(string(test.seed)),
+				"name":         stringValue("billing"),
+				"description":  stringValue("Invoices and payments"),
+				"info_url":     stringValue("https://wiki.example.com/billing"),
+				"signing_keys": stringSetValue(accountSignerKey),
+				"limits": nested("limits", map[string]tftypes.Value{
+					"connections":   numberValue(10),
+					"subscriptions": numberValue(100),
+					"payload":       numberValue(1024),
+				}),
+				"jetstream_limits": nested("jetstream_limits", map[string]tftypes.Value{
+					"disk_storage": numberValue(1 << 20),
+				}),
+				"expires_at": stringValue("720h"),
+			})
+
+			token := stringAttribute(t, state, "jwt")
+			verifySignature(t, token, test.issuer)
+
+			claims, err := jwt.DecodeAccountClaims(token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims.Issuer != test.issuer || claims.Subject != accountKey {
+				t.Errorf("JWT of %s issued by %s, want %s issued by %s", claims.Subject, claims.Issuer, accountKey, test.issuer)
+			}
+			if got := stringAttribute(t, state, "issuer"); got != test.issuer {
+				t.Errorf("issuer = %s, want %s", got, test.issuer)
+			}
+			if claims.Name != "billing" {
+				t.Errorf("name = %q, want billing", claims.Name)
+			}
+			if claims.Description != "Invoices and payments" || claims.InfoURL != "https://wiki.example.com/billing" {
+				t.Errorf("description = %q, info URL = %q", claims.Description, claims.InfoURL)
+			}
+			if !claims.SigningKeys.Contains(accountSignerKey) || len(claims.SigningKeys) != 1 {
+				t.Errorf("signing keys = %v, want %s", claims.SigningKeys.Keys(), accountSignerKey)
+			}
+			if claims.Limits.Conn != 10 || claims.Limits.Subs != 100 || claims.Limits.Payload != 1024 {
+				t.Errorf("limits = %+v", claims.Limits.NatsLimits)
+			}
+			if claims.Limits.DiskStorage != 1<<20 {
+				t.Errorf("disk storage = %d, want %d", claims.Limits.DiskStorage, 1<<20)
+			}
+			if got := claims.Expires - claims.IssuedAt; got != 720*60*60 {
+				t.Errorf("JWT expires %ds after issuance, want 720h", got)
+			}
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		for name, config := range map[string]map[string]tftypes.Value{
+			"account seed": {"public_key": stringValue(accountKey), "issuer_seed": stringValue(string(accountSeed))},
+			"public key":   {"public_key": stringValue(operatorKey), "issuer_seed": stringValue(string(operatorSeed))},
+			"signing key":  {"public_key": stringValue(accountKey), "issuer_seed": stringValue(string(operatorSeed)), "signing_keys": stringSetValue(operatorKey)},
+		} {
+			config["name"] = stringValue("billing")
+			if _, diags := p.tryApply("nkey_account_jwt", tftypes.Value{}, config); !hasError(diags, "Invalid") {
+				t.Errorf("%s: expected an error, got %v", name, diags)
+			}
+		}
+	})
+}
+
+func TestAccountJWTClusterTraffic(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	operator, _ := nkeys.CreateOperator()
+	seed, _ := operator.Seed()
+	account, _ := nkeys.CreateAccount()
+	accountKey, _ := account.PublicKey()
+
+	config := func(traffic string) map[string]tftypes.Value {
+		return map[string]tftypes.Value{
+			"public_key":      stringValue(accountKey),
+			"issuer_seed":     stringValue(string(seed)),
+			"name":            stringValue("billing"),
+			"cluster_traffic": stringValue(traffic),
+		}
+	}
+
+	for _, traffic := range []string{"owner", "system"} {
+		t.Run(traffic, func(t *testing.T) {
+			state := p.create("nkey_account_jwt", config(traffic))
+
+			claims, err := jwt.DecodeAccountClaims(stringAttribute(t, state, "jwt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(claims.ClusterTraffic) != traffic {
+				t.Errorf("cluster_traffic = %q, want %q", claims.ClusterTraffic, traffic)
+			}
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		if diags := p.validate("nkey_account_jwt", config("leaf")); !hasError(diags, "Invalid Attribute Value Match") {
+			t.Errorf("expected a validation error, got %v", diags)
+		}
+	})
+}
+
+func TestAccountJWTSystemImports(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	operator, _ := nkeys.CreateOperator()
+	seed, _ := operator.Seed()
+	account, _ := nkeys.CreateAccount()
+	accountKey, _ := account.PublicKey()
+
+	system := p.create("nkey_system_account", map[string]tftypes.Value{"issuer_seed": stringValue(string(seed))})
+	systemKey := stringAttribute(t, system, "public_key")
+	systemClaims, err := jwt.DecodeAccountClaims(stringAttribute(t, system, "jwt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	importsType := tftypes.Object{AttributeTypes: map[string]tftypes.Type{
+		"account":  tftypes.String,
+		"streams":  tftypes.Bool,
+		"services": tftypes.Bool,
+	}}
+
+	tests := map[string]struct {
+		streams, services tftypes.Value
+		// want are the expected import names.
+		want []string
+	}{
+		"defaults": {
+			streams:  tftypes.NewValue(tftypes.Bool, nil),
+			services: tftypes.NewValue(tftypes.Bool, nil),
+			want:     []string{"account-monitoring-services", "account-monitoring-streams"},
+		},
+		"streams only": {
+			streams:  boolValue(true),
+			services: boolValue(false),
+			want:     []string{"account-monitoring-streams"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := p.create("nkey_account_jwt", map[string]tftypes.Value{
+				"public_key":  stringValue(accountKey),
+				"issuer_seed": stringValue(string(seed)),
+				"name":        stringValue("billing"),
+				"system_imports": tftypes.NewValue(importsType, map[string]tftypes.Value{
+					"account":  stringValue(systemKey),
+					"streams":  test.streams,
+					"services": test.services,
+				}),
+			})
+
+			claims, err := jwt.DecodeAccountClaims(stringAttribute(t, state, "jwt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for _, imp := range claims.Imports {
+				names = append(names, imp.Name)
+				if imp.Account != systemKey {
+					t.Errorf("%s: account = %s, want the system account", imp.Name, imp.Account)
+				}
+
+				// The server only grants imports within an export whose
+				// account token is the importing account.
+				var export *jwt.Export
+				for _, e := range systemClaims.Exports {
+					if e.Type == imp.Type && subject.Covers(string(e.Subject), string(imp.Subject)) {
+						export = e
+					}
+				}
+				if export == nil {
+					t.Errorf("%s: %s is not exported by the system account", imp.Name, imp.Subject)
+					continue
+				}
+				if token := strings.Split(string(imp.Subject), ".")[export.AccountTokenPosition-1]; token != accountKey {
+					t.Errorf("%s: account token = %s, want %s", imp.Name, token, accountKey)
+				}
+			}
+			sort.Strings(names)
+			if got, want := strings.Join(names, ","), strings.Join(test.want, ","); got != want {
+				t.Errorf("imports = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestAccountJWTTargetServerVersion(t *testing.T) {
+	operator, _ := nkeys.CreateOperator()
+	seed, _ := operator.Seed()
+	account, _ := nkeys.CreateAccount()
+	accountKey, _ := account.PublicKey()
+	authUser, _ := nkeys.CreateUser()
+	authUserKey, _ := authUser.PublicKey()
+
+	authorizationType := tftypes.Object{AttributeTypes: map[string]tftypes.Type{
+		"auth_users":       tftypes.Set{ElementType: tftypes.String},
+		"allowed_accounts": tftypes.Set{ElementType: tftypes.String},
+		"xkey":             tftypes.String,
+	}}
+	config := map[string]tftypes.Value{
+		"public_key":  stringValue(accountKey),
+		"issuer_seed": stringValue(string(seed)),
+		"name":        stringValue("tenants"),
+		"authorization": tftypes.NewValue(authorizationType, map[string]tftypes.Value{
+			"auth_users":       tftypes.NewValue(tftypes.Set{ElementType: tftypes.String}, []tftypes.Value{stringValue(authUserKey)}),
+			"allowed_accounts": tftypes.NewValue(tftypes.Set{ElementType: tftypes.String}, nil),
+			"xkey":             tftypes.NewValue(tftypes.String, nil),
+		}),
+	}
+
+	for version, supported := range map[string]bool{
+		"":       true,
+		"2.9.0":  false,
+		"2.10.0": true,
+	} {
+		t.Run(version, func(t *testing.T) {
+			providerConfig := map[string]tftypes.Value{}
+			if version != "" {
+				providerConfig["target_server_version"] = stringValue(version)
+			}
+			p := newTestProvider(t, providerConfig)
+
+			resp := p.plan("nkey_account_jwt", tftypes.Value{}, config)
+			if got := hasError(resp.Diagnostics, "Claim not supported by the target server"); got == supported {
+				t.Errorf("unsupported claim error = %t, want %t", got, !supported)
+			}
+		})
+	}
+}
+
+func TestAccountJWTOperator(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	operator, _ := nkeys.CreateOperator()
+	operatorKey, _ := operator.PublicKey()
+	operatorSeed, _ := operator.Seed()
+	signer, _ := nkeys.CreateOperator()
+	signerKey, _ := signer.PublicKey()
+	signerSeed, _ := signer.Seed()
+	other, _ := nkeys.CreateOperator()
+	otherSeed, _ := other.Seed()
+	account, _ := nkeys.CreateAccount()
+	accountKey, _ := account.PublicKey()
+
+	operatorJWT := func(strict bool, expires int64) string {
+		claims := jwt.NewOperatorClaims(operatorKey)
+		claims.Name = "business-unit-a"
+		claims.SigningKeys.Add(signerKey)
+		claims.StrictSigningKeyUsage = strict
+		claims.Expires = expires
+		token, err := claims.Encode(operator)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	tests := map[string]struct {
+		seed        []byte
+		operatorJWT string
+		// err is the expected error summary, empty for none.
+		err string
+	}{
+		"operator":                 {seed: operatorSeed, operatorJWT: operatorJWT(false, 0)},
+		"signing key":              {seed: signerSeed, operatorJWT: operatorJWT(true, 0)},
+		"other operator":           {seed: otherSeed, operatorJWT: operatorJWT(false, 0), err: "Issuer of another operator"},
+		"strict signing key usage": {seed: operatorSeed, operatorJWT: operatorJWT(true, 0), err: "Operator requires signing keys"},
+		"outlives operator":        {seed: signerSeed, operatorJWT: operatorJWT(false, time.Now().Add(time.Hour).Unix()), err: "Account outlives its operator"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := map[string]tftypes.Value{
+				"public_key":   stringValue(accountKey),
+				"issuer_seed":  stringValue(string(test.seed)),
+				"operator_jwt": stringValue(test.operatorJWT),
+				"name":         stringValue("billing"),
+			}
+
+			resp := p.plan("nkey_account_jwt", tftypes.Value{}, config)
+			if test.err == "" {
+				checkDiagnostics(t, resp.Diagnostics)
+			} else if !hasError(resp.Diagnostics, test.err) {
+				t.Errorf("expected %q during plan, got %v", test.err, resp.Diagnostics)
+			}
+
+			// An operator JWT not known yet is checked once it is, when
+			// Terraform plans again during apply.
+			config["operator_jwt"] = tftypes.NewValue(tftypes.String, tftypes.UnknownValue)
+			checkDiagnostics(t, p.plan("nkey_account_jwt", tftypes.Value{}, config).Diagnostics)
+		})
+	}
+}
+
+func TestAccountJWTRevokedUsers(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	operator, _ := nkeys.CreateOperator()
+	seed, _ := operator.Seed()
+	account, _ := nkeys.CreateAccount()
+	accountKey, _ := account.PublicKey()
+	var users []string
+	for range 3 {
+		user, _ := nkeys.CreateUser()
+		userKey, _ := user.PublicKey()
+		users = append(users, userKey)
+	}
+
+	revokedType := tftypes.Object{AttributeTypes: map[string]tftypes.Type{
+		"public_keys": tftypes.Set{ElementType: tftypes.String},
+		"revoked_at":  tftypes.String,
+	}}
+	revoked := func(at string, keys ...string) tftypes.Value {
+		values := make([]tftypes.Value, len(keys))
+		for i, key := range keys {
+			values[i] = stringValue(key)
+		}
+		return tftypes.NewValue(revokedType, map[string]tftypes.Value{
+			"public_keys": tftypes.NewValue(tftypes.Set{ElementType: tftypes.String}, values),
+			"revoked_at":  stringValue(at),
+		})
+	}
+	config := func(revoked ...tftypes.Value) map[string]tftypes.Value {
+		return map[string]tftypes.Value{
+			"public_key":    stringValue(accountKey),
+			"issuer_seed":   stringValue(string(seed)),
+			"name":          stringValue("billing"),
+			"revocations":   tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{users[0]: stringValue("2024-06-01T00:00:00Z")}),
+			"revoked_users": tftypes.NewValue(tftypes.List{ElementType: revokedType}, revoked),
+		}
+	}
+
+	state := p.create("nkey_account_jwt", config(
+		revoked("2024-01-01T00:00:00Z", users[0], users[1]),
+		revoked("2024-03-01T00:00:00Z", users[1], users[2]),
+	))
+
+	claims, err := jwt.DecodeAccountClaims(stringAttribute(t, state, "jwt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for user, want := range map[string]string{
+		users[0]: "2024-06-01T00:00:00Z",
+		users[1]: "2024-03-01T00:00:00Z",
+		users[2]: "2024-03-01T00:00:00Z",
+	} {
+		if got := time.Unix(claims.Revocations[user], 0).UTC().Format(time.RFC3339); got != want {
+			t.Errorf("%s revoked at %s, want %s", user, got, want)
+		}
+	}
+
+	t.Run("all users", func(t *testing.T) {
+		state := p.create("nkey_account_jwt", config(revoked("2024-01-01T00:00:00Z", jwt.All)))
+		claims, err := jwt.DecodeAccountClaims(stringAttribute(t, state, "jwt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := claims.Revocations[jwt.All]; !ok {
+			t.Errorf("revocations = %v, want all users revoked", claims.Revocations)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, test := range []tftypes.Value{
+			revoked("yesterday", users[0]),
+			revoked("2024-01-01T00:00:00Z", accountKey),
+		} {
+			if _, diags := p.tryApply("nkey_account_jwt", tftypes.Value{}, config(test)); !hasError(diags, "Invalid revocation") {
+				t.Errorf("expected an invalid revocation error, got %v", diags)
+			}
+		}
+	})
+
+	t.Run("null timestamp", func(t *testing.T) {
+		null := config()
+		null["revocations"] = tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{
+			users[0]: tftypes.NewValue(tftypes.String, nil),
+		})
+		_, diags := p.tryApply("nkey_account_jwt", tftypes.Value{}, null)
+		if !hasError(diags, "Invalid revocation") {
+			t.Fatalf("expected an invalid revocation error, got %v", diags)
+		}
+		want := tftypes.NewAttributePath().WithAttributeName("revocations").WithElementKeyString(users[0])
+		for _, d := range diags {
+			if d.Attribute == nil || !d.Attribute.Equal(want) {
+				t.Errorf("diagnostic at %v, want %v", d.Attribute, want)
+			}
+		}
+
+		// The revoked_at attribute of revoked_users is required.
+		null = config(tftypes.NewValue(revokedType, map[string]tftypes.Value{
+			"public_keys": tftypes.NewValue(tftypes.Set{ElementType: tftypes.String}, []tftypes.Value{stringValue(users[0])}),
+			"revoked_at":  tftypes.NewValue(tftypes.String, nil),
+		}))
+		if diags := p.validate("nkey_account_jwt", null); !hasError(diags, "Missing Configuration for Required Attribute") {
+			t.Errorf("expected a missing revoked_at error, got %v", diags)
+		}
+	})
+}
@@ -0,0 +1,495 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &AccountPush{}
+var _ resource.ResourceWithModifyPlan = &AccountPush{}
+
+// accountPushRetryDelay is the delay before the first retry of a failed
+// push. It doubles with every further attempt. Tests shorten it.
+var accountPushRetryDelay = time.Second
+
+func NewAccountPush() resource.Resource {
+	return &AccountPush{}
+}
+
+// AccountPush defines the resource implementation.
+type AccountPush struct {
+	provider *providerData
+}
+
+// AccountPushModel describes the resource data model.
+type AccountPushModel struct {
+	Servers     types.List              `tfsdk:"servers"`
+	Credentials types.String            `tfsdk:"credentials"`
+	Accounts    types.Map               `tfsdk:"accounts"`
+	Retries     types.Int64             `tfsdk:"retries"`
+	Canary      *AccountPushCanaryModel `tfsdk:"canary"`
+	Results     types.Map               `tfsdk:"results"`
+
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+}
+
+// AccountPushCanaryModel describes the cluster accounts are pushed to before
+// the production cluster.
+type AccountPushCanaryModel struct {
+	Servers         types.List   `tfsdk:"servers"`
+	Credentials     types.String `tfsdk:"credentials"`
+	Verify          types.Bool   `tfsdk:"verify"`
+	TestCredentials types.String `tfsdk:"test_credentials"`
+}
+
+// AccountPushResultModel describes the outcome of pushing a single account.
+type AccountPushResultModel struct {
+	Pushed   types.Bool   `tfsdk:"pushed"`
+	Attempts types.Int64  `tfsdk:"attempts"`
+	Message  types.String `tfsdk:"message"`
+	JWTHash  types.String `tfsdk:"jwt_sha256"`
+}
+
+var accountPushResultType = types.ObjectType{AttrTypes: map[string]attr.Type{
+	"pushed":     types.BoolType,
+	"attempts":   types.Int64Type,
+	"message":    types.StringType,
+	"jwt_sha256": types.StringType,
+}}
+
+func (r *AccountPush) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_account_push"
+}
+
+func (r *AccountPush) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Pushes a set of account JWTs to the account resolver of a NATS cluster over a single " +
+			"connection. Accounts that cannot be pushed after all retries fail the apply, the others are recorded in " +
+			"`results` and are not pushed again until their JWT changes. Destroying the resource does not delete the " +
+			"accounts from the resolver. The `timeouts` block bounds the connection and all pushes of an apply, " +
+			"otherwise every push is bounded by 5 seconds.",
+
+		Attributes: map[string]schema.Attribute{
+			"servers": schema.ListAttribute{
+				Required:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "NATS server URLs to connect to",
+			},
+			"credentials": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Content of a creds file of a system account user",
+				Sensitive:           true,
+			},
+			"accounts": schema.MapAttribute{
+				Required:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Map of account public keys to the account JWT to push",
+			},
+			"retries": schema.Int64Attribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             int64default.StaticInt64(3),
+				MarkdownDescription: "Number of times a failed push is retried, with exponential backoff",
+			},
+			"canary": schema.SingleNestedAttribute{
+				Optional: true,
+				MarkdownDescription: "Staging cluster the changed accounts are pushed to first. Only if all of them were " +
+					"pushed and verified there, they are pushed to `servers`, otherwise they are recorded as not pushed " +
+					"and the apply fails",
+				Attributes: map[string]schema.Attribute{
+					"servers": schema.ListAttribute{
+						Required:            true,
+						ElementType:         types.StringType,
+						MarkdownDescription: "NATS server URLs of the staging cluster",
+					},
+					"credentials": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "Content of a creds file of a system account user of the staging cluster",
+						Sensitive:           true,
+					},
+					"verify": schema.BoolAttribute{
+						Optional:            true,
+						Computed:            true,
+						Default:             booldefault.StaticBool(true),
+						MarkdownDescription: "Whether the staging resolver must serve each pushed JWT. Defaults to `true`",
+					},
+					"test_credentials": schema.StringAttribute{
+						Optional: true,
+						MarkdownDescription: "Content of a creds file of a user of a pushed account. If set, the staging " +
+							"cluster must accept a connection of the user after the push",
+						Sensitive: true,
+					},
+				},
+			},
+			"results": schema.MapNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "Map of account public keys to the outcome of their last push",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"pushed": schema.BoolAttribute{
+							Computed:            true,
+							MarkdownDescription: "Whether the resolver accepted the JWT",
+						},
+						"attempts": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "Number of attempts made",
+						},
+						"message": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Response of the resolver or the error of the last attempt",
+						},
+						"jwt_sha256": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Hex encoded SHA-256 hash of the pushed JWT",
+						},
+					},
+				},
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Update: true,
+			}),
+		},
+	}
+}
+
+func (r *AccountPush) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.provider = data
+}
+
+func (r *AccountPush) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var state AccountPushModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	previous := map[string]AccountPushResultModel{}
+	resp.Diagnostics.Append(state.Results.ElementsAs(ctx, &previous, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Accounts that failed or were skipped are pushed again on the next apply.
+	for _, result := range previous {
+		if !result.Pushed.ValueBool() {
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("results"), types.MapUnknown(accountPushResultType))...)
+			return
+		}
+	}
+}
+
+func (r *AccountPush) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var data AccountPushModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := data.Timeouts.Create(ctx, 0)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, createTimeout)
+	defer cancel()
+
+	r.push(ctx, &data, nil, &resp.Diagnostics)
+	if data.Results.IsUnknown() {
+		return
+	}
+	tflog.Trace(ctx, "created account push resource")
+
+	// Save data into Terraform state, including the accounts that failed
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AccountPush) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data AccountPushModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AccountPush) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var plan, state AccountPushModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	previous := map[string]AccountPushResultModel{}
+	resp.Diagnostics.Append(state.Results.ElementsAs(ctx, &previous, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updateTimeout, diags := plan.Timeouts.Update(ctx, 0)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	r.push(ctx, &plan, previous, &resp.Diagnostics)
+	if plan.Results.IsUnknown() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *AccountPush) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Removing accounts requires a delete request signed by the operator,
+	// so the accounts are left on the resolver.
+}
+
+// push sends every account JWT that is not already recorded as pushed in
+// previous and sets data.Results. With a canary, the accounts are pushed to
+// the canary servers first and none is pushed to the servers if that fails.
+// Accounts that could not be pushed are reported as errors. data.Results
+// stays unknown if nothing was attempted.
+func (r *AccountPush) push(ctx context.Context, data *AccountPushModel, previous map[string]AccountPushResultModel, diags *diag.Diagnostics) {
+	var servers, canaryServers []string
+	accounts := map[string]string{}
+
+	diags.Append(data.Servers.ElementsAs(ctx, &servers, false)...)
+	diags.Append(data.Accounts.ElementsAs(ctx, &accounts, false)...)
+	if data.Canary != nil {
+		diags.Append(data.Canary.Servers.ElementsAs(ctx, &canaryServers, false)...)
+	}
+	if diags.HasError() {
+		return
+	}
+
+	for _, account := range sortedKeys(accounts) {
+		sub, err := jwtSubject(accounts[account])
+		switch {
+		case !nkeys.IsValidPublicAccountKey(account):
+			diags.AddAttributeError(path.Root("accounts").AtMapKey(account), "Invalid account public key",
+				fmt.Sprintf("%q is not an account public key", account))
+		case err != nil:
+			addError(diags, "Invalid account JWT", errorAt(path.Root("accounts").AtMapKey(account), err))
+		case sub != account:
+			diags.AddAttributeError(path.Root("accounts").AtMapKey(account), "Invalid account JWT",
+				fmt.Sprintf("the JWT was issued for %s", sub))
+		}
+	}
+	if diags.HasError() {
+		return
+	}
+
+	results := map[string]AccountPushResultModel{}
+	var pending []string
+
+	for _, account := range sortedKeys(accounts) {
+		hash := jwtHash(accounts[account])
+		if prev, ok := previous[account]; ok && prev.Pushed.ValueBool() && prev.JWTHash.ValueString() == hash {
+			results[account] = prev
+			continue
+		}
+		pending = append(pending, account)
+	}
+
+	switch {
+	case len(pending) == 0:
+	case r.provider.isOffline():
+		diags.AddWarning("Accounts not pushed",
+			"The provider is configured offline, the accounts are recorded as not pushed and will be pushed on the next online apply.")
+		for _, account := range pending {
+			results[account] = AccountPushResultModel{
+				Pushed:   types.BoolValue(false),
+				Attempts: types.Int64Value(0),
+				Message:  types.StringValue("skipped, the provider is offline"),
+				JWTHash:  types.StringValue(jwtHash(accounts[account])),
+			}
+		}
+	default:
+		if data.Canary != nil {
+			if err := r.pushCanary(ctx, data, canaryServers, pending, accounts); err != nil {
+				addError(diags, "Canary push failed", err)
+				for _, account := range pending {
+					results[account] = AccountPushResultModel{
+						Pushed:   types.BoolValue(false),
+						Attempts: types.Int64Value(0),
+						Message:  types.StringValue("skipped, the canary push failed: " + err.Error()),
+						JWTHash:  types.StringValue(jwtHash(accounts[account])),
+					}
+				}
+				break
+			}
+		}
+
+		nc, err := connectNATS(ctx, servers, data.Credentials.ValueString())
+		if err != nil {
+			addError(diags, "Unable to connect to NATS", err)
+			return
+		}
+		defer nc.Close()
+
+		var failed []string
+		for _, account := range pending {
+			message, attempts, err := pushWithRetries(ctx, nc, account, accounts[account], data.Retries.ValueInt64())
+
+			result := AccountPushResultModel{
+				Pushed:   types.BoolValue(err == nil),
+				Attempts: types.Int64Value(attempts),
+				JWTHash:  types.StringValue(jwtHash(accounts[account])),
+			}
+			if err != nil {
+				message = err.Error()
+				failed = append(failed, account)
+			}
+			result.Message = types.StringValue(message)
+			results[account] = result
+		}
+
+		for _, account := range failed {
+			diags.AddAttributeError(path.Root("accounts").AtMapKey(account), "Unable to push account JWT",
+				fmt.Sprintf("%s could not be pushed after %d attempts: %s", account, results[account].Attempts.ValueInt64(),
+					results[account].Message.ValueString()))
+		}
+	}
+
+	value, d := types.MapValueFrom(ctx, accountPushResultType, results)
+	diags.Append(d...)
+	if d.HasError() {
+		return
+	}
+	data.Results = value
+}
+
+// pushCanary pushes the pending accounts to the canary servers of data, in
+// the order given, and verifies the result. The first failure is returned.
+func (r *AccountPush) pushCanary(ctx context.Context, data *AccountPushModel, servers, pending []string, accounts map[string]string) error {
+	canary := path.Root("canary")
+
+	// connect reports the errors of connectNATS at the canary attributes,
+	// with the credentials at credentials.
+	connect := func(creds, credentials string) (*nats.Conn, error) {
+		nc, err := connectNATS(ctx, servers, creds)
+		var ae *attributeError
+		if errors.As(err, &ae) {
+			name := ae.path.String()
+			if name == "credentials" {
+				name = credentials
+			}
+			return nil, errorAt(canary.AtName(name), ae.err)
+		}
+		return nc, err
+	}
+
+	nc, err := connect(data.Canary.Credentials.ValueString(), "credentials")
+	if err != nil {
+		return err
+	}
+	defer nc.Close()
+
+	for _, account := range pending {
+		if _, _, err := pushWithRetries(ctx, nc, account, accounts[account], data.Retries.ValueInt64()); err != nil {
+			return errorAt(canary, fmt.Errorf("pushing %s: %w", account, err))
+		}
+
+		if !data.Canary.Verify.ValueBool() {
+			continue
+		}
+		served, err := lookupAccountJWT(ctx, nc, account)
+		switch {
+		case err != nil:
+			return errorAt(canary, fmt.Errorf("resolving %s: %w", account, err))
+		case served != accounts[account]:
+			return errorAt(canary, fmt.Errorf("the resolver does not serve the pushed JWT of %s", account))
+		}
+		tflog.Debug(ctx, "verified canary push", map[string]interface{}{"account": account})
+	}
+
+	if creds := data.Canary.TestCredentials.ValueString(); creds != "" {
+		test, err := connect(creds, "test_credentials")
+		if err != nil {
+			return fmt.Errorf("connection test: %w", err)
+		}
+		test.Close()
+	}
+
+	return nil
+}
+
+// pushWithRetries pushes the JWT token of account over nc, retrying failed
+// pushes up to retries times with exponential backoff. It returns the
+// message of the resolver and the number of attempts made.
+func pushWithRetries(ctx context.Context, nc *nats.Conn, account, token string, retries int64) (string, int64, error) {
+	for attempts := int64(1); ; attempts++ {
+		message, err := pushAccountJWT(ctx, nc, token)
+		if err == nil || attempts > retries || ctx.Err() != nil {
+			return message, attempts, err
+		}
+
+		delay := accountPushRetryDelay << (attempts - 1)
+		tflog.Debug(ctx, "retrying account push", map[string]interface{}{"account": account, "delay": delay.String(), "error": err.Error()})
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+		}
+	}
+}
@@ -0,0 +1,288 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestAccountPushTimeout(t *testing.T) {
+	// A server that accepts connections but never greets the client.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	operator, _ := nkeys.CreateOperator()
+	account, _ := nkeys.CreateAccount()
+	accountKey, _ := account.PublicKey()
+	token, err := jwt.NewAccountClaims(accountKey).Encode(operator)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := newTestProvider(t, nil)
+	timeoutsType := p.resourceSchema("nkey_account_push").ValueType().(tftypes.Object).AttributeTypes["timeouts"]
+
+	start := time.Now()
+	_, diags := p.tryApply("nkey_account_push", tftypes.Value{}, map[string]tftypes.Value{
+		"servers":  tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, []tftypes.Value{stringValue("nats://" + listener.Addr().String())}),
+		"accounts": tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{accountKey: stringValue(token)}),
+		"timeouts": tftypes.NewValue(timeoutsType, map[string]tftypes.Value{
+			"create": stringValue("300ms"),
+			"update": tftypes.NewValue(tftypes.String, nil),
+		}),
+	})
+
+	if !hasError(diags, "Unable to connect to NATS") {
+		t.Fatalf("expected a connection error, got %v", diags)
+	}
+	if elapsed := time.Since(start); elapsed > 1500*time.Millisecond {
+		t.Errorf("create took %s despite a timeout of 300ms", elapsed)
+	}
+}
+
+func TestAccountPushCanary(t *testing.T) {
+	operator, _ := nkeys.CreateOperator()
+	tokens := map[string]tftypes.Value{}
+	for range 2 {
+		account, _ := nkeys.CreateAccount()
+		accountKey, _ := account.PublicKey()
+		token, err := jwt.NewAccountClaims(accountKey).Encode(operator)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens[accountKey] = stringValue(token)
+	}
+
+	// resolver returns a fake resolver storing the pushed JWTs in pushed
+	// and serving them on lookup if serve is set.
+	var mu sync.Mutex
+	resolver := func(pushed map[string]string, serve bool) string {
+		return fakeResolver(t, func(subject string, data []byte) []byte {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if subject == "$SYS.REQ.CLAIMS.UPDATE" {
+				sub, _ := jwtSubject(string(data))
+				pushed[sub] = string(data)
+				return []byte(`{"data":{"code":200,"message":"jwt updated"}}`)
+			}
+			account := strings.TrimSuffix(strings.TrimPrefix(subject, "$SYS.REQ.ACCOUNT."), ".CLAIMS.LOOKUP")
+			if serve {
+				return []byte(pushed[account])
+			}
+			return []byte{}
+		})
+	}
+
+	p := newTestProvider(t, nil)
+	canaryType := p.resourceSchema("nkey_account_push").ValueType().(tftypes.Object).AttributeTypes["canary"].(tftypes.Object)
+	servers := func(url string) tftypes.Value {
+		return tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, []tftypes.Value{stringValue(url)})
+	}
+
+	tests := map[string]struct {
+		// serve is whether the canary resolver serves the pushed JWTs.
+		serve  bool
+		pushed bool
+	}{
+		"verified":     {serve: true, pushed: true},
+		"not resolved": {serve: false, pushed: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			staging, production := map[string]string{}, map[string]string{}
+			state, diags := p.tryApply("nkey_account_push", tftypes.Value{}, map[string]tftypes.Value{
+				"servers":  servers(resolver(production, true)),
+				"accounts": tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, tokens),
+				"canary": tftypes.NewValue(canaryType, map[string]tftypes.Value{
+					"servers":          servers(resolver(staging, test.serve)),
+					"credentials":      tftypes.NewValue(tftypes.String, nil),
+					"verify":           tftypes.NewValue(tftypes.Bool, nil),
+					"test_credentials": tftypes.NewValue(tftypes.String, nil),
+				}),
+			})
+
+			if failed := hasError(diags, "Canary push failed"); failed == test.pushed {
+				t.Fatalf("canary failed = %t, want %t: %v", failed, !test.pushed, diags)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(staging) == 0 {
+				t.Error("nothing was pushed to the canary")
+			}
+			if len(production) != 0 != test.pushed {
+				t.Errorf("pushed %d accounts to production", len(production))
+			}
+
+			var results map[string]tftypes.Value
+			if err := attribute(t, state, "results").As(&results); err != nil {
+				t.Fatal(err)
+			}
+			for account := range tokens {
+				var pushed bool
+				if err := attribute(t, results[account], "pushed").As(&pushed); err != nil {
+					t.Fatal(err)
+				}
+				if pushed != test.pushed {
+					t.Errorf("%s pushed = %t, want %t", account, pushed, test.pushed)
+				}
+			}
+		})
+	}
+}
+
+func TestAccountPushRetries(t *testing.T) {
+	delay := accountPushRetryDelay
+	accountPushRetryDelay = 10 * time.Millisecond
+	defer func() { accountPushRetryDelay = delay }()
+
+	operator, _ := nkeys.CreateOperator()
+	account, _ := nkeys.CreateAccount()
+	accountKey, _ := account.PublicKey()
+	token, err := jwt.NewAccountClaims(accountKey).Encode(operator)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// resolver returns a fake resolver that fails the first failures pushes
+	// and records the time of every push in pushes.
+	var mu sync.Mutex
+	resolver := func(failures int, pushes *[]time.Time) string {
+		return fakeResolver(t, func(subject string, data []byte) []byte {
+			mu.Lock()
+			defer mu.Unlock()
+
+			*pushes = append(*pushes, time.Now())
+			if len(*pushes) <= failures {
+				return []byte(`{"error":{"code":500,"description":"jwt store unavailable"}}`)
+			}
+			return []byte(`{"data":{"code":200,"message":"jwt updated"}}`)
+		})
+	}
+
+	p := newTestProvider(t, nil)
+	config := func(url string, retries int64) map[string]tftypes.Value {
+		return map[string]tftypes.Value{
+			"servers":  tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, []tftypes.Value{stringValue(url)}),
+			"accounts": tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{accountKey: stringValue(token)}),
+			"retries":  numberValue(retries),
+		}
+	}
+	result := func(state tftypes.Value) (pushed bool, attempts int64, message string) {
+		var results map[string]tftypes.Value
+		if err := attribute(t, state, "results").As(&results); err != nil {
+			t.Fatal(err)
+		}
+		var n big.Float
+		if err := attribute(t, results[accountKey], "pushed").As(&pushed); err != nil {
+			t.Fatal(err)
+		}
+		if err := attribute(t, results[accountKey], "attempts").As(&n); err != nil {
+			t.Fatal(err)
+		}
+		attempts, _ = n.Int64()
+		return pushed, attempts, stringAttribute(t, results[accountKey], "message")
+	}
+
+	tests := map[string]struct {
+		failures int
+		retries  int64
+		pushed   bool
+		attempts int64
+	}{
+		"first attempt": {failures: 0, retries: 3, pushed: true, attempts: 1},
+		"retried":       {failures: 2, retries: 3, pushed: true, attempts: 3},
+		"no retries":    {failures: 1, retries: 0, pushed: false, attempts: 1},
+		"exhausted":     {failures: 5, retries: 2, pushed: false, attempts: 3},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var pushes []time.Time
+			state, diags := p.tryApply("nkey_account_push", tftypes.Value{}, config(resolver(test.failures, &pushes), test.retries))
+
+			if failed := hasError(diags, "Unable to push account JWT"); failed == test.pushed {
+				t.Fatalf("push failed = %t, want %t: %v", failed, !test.pushed, diags)
+			}
+			pushed, attempts, message := result(state)
+			if pushed != test.pushed || attempts != test.attempts {
+				t.Errorf("pushed = %t after %d attempts, want %t after %d", pushed, attempts, test.pushed, test.attempts)
+			}
+			if want := "jwt store unavailable"; !test.pushed && !strings.Contains(message, want) {
+				t.Errorf("message = %q, want the error of the resolver", message)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if int64(len(pushes)) != test.attempts {
+				t.Fatalf("the resolver received %d pushes, want %d", len(pushes), test.attempts)
+			}
+			// The delay doubles with every retry.
+			for i := 1; i < len(pushes); i++ {
+				if gap, want := pushes[i].Sub(pushes[i-1]), accountPushRetryDelay<<(i-1); gap < want {
+					t.Errorf("retry %d after %s, want at least %s", i, gap, want)
+				}
+			}
+		})
+	}
+
+	t.Run("pushed again", func(t *testing.T) {
+		var pushes []time.Time
+		cfg := config(resolver(1, &pushes), 0)
+		state, diags := p.tryApply("nkey_account_push", tftypes.Value{}, cfg)
+		if !hasError(diags, "Unable to push account JWT") {
+			t.Fatalf("expected a failed push, got %v", diags)
+		}
+
+		// The failed account is planned to be pushed again although the
+		// configuration is unchanged.
+		resp := p.plan("nkey_account_push", state, cfg)
+		checkDiagnostics(t, resp.Diagnostics)
+		if attribute(t, p.value(p.resourceSchema("nkey_account_push"), resp.PlannedState), "results").IsKnown() {
+			t.Fatal("results of a failed push are known in the plan")
+		}
+
+		state = p.apply("nkey_account_push", state, cfg)
+		if pushed, attempts, _ := result(state); !pushed || attempts != 1 {
+			t.Errorf("pushed = %t after %d attempts, want true after 1", pushed, attempts)
+		}
+
+		// Once pushed, the account is not pushed again.
+		resp = p.plan("nkey_account_push", state, cfg)
+		checkDiagnostics(t, resp.Diagnostics)
+		if !attribute(t, p.value(p.resourceSchema("nkey_account_push"), resp.PlannedState), "results").IsKnown() {
+			t.Error("results of a pushed account are unknown in the plan")
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if len(pushes) != 2 {
+			t.Errorf("the resolver received %d pushes, want 2", len(pushes))
+		}
+	})
+}
@@ -0,0 +1,312 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &ActivationJWT{}
+var _ resource.ResourceWithModifyPlan = &ActivationJWT{}
+
+func NewActivationJWT() resource.Resource {
+	return &ActivationJWT{}
+}
+
+// ActivationJWT defines the resource implementation.
+type ActivationJWT struct {
+	provider *providerData
+}
+
+// ActivationJWTModel describes the resource data model.
+type ActivationJWTModel struct {
+	Subject         types.String  `tfsdk:"subject"`
+	ImportType      types.String  `tfsdk:"import_type"`
+	TargetAccount   types.String  `tfsdk:"target_account"`
+	Tags            types.Set     `tfsdk:"tags"`
+	IssuerSeed      types.String  `tfsdk:"issuer_seed"`
+	IssuerAccount   types.String  `tfsdk:"issuer_account"`
+	ExpiresAt       types.String  `tfsdk:"expires_at"`
+	NotBefore       types.String  `tfsdk:"not_before"`
+	RenewBefore     types.String  `tfsdk:"renew_before"`
+	IssuedAt        types.String  `tfsdk:"issued_at"`
+	DeterministicID types.Bool    `tfsdk:"deterministic_id"`
+	JWTVersion      types.Int64   `tfsdk:"jwt_version"`
+	Claims          types.Dynamic `tfsdk:"claims"`
+	Issuer          types.String  `tfsdk:"issuer"`
+	JWT             types.String  `tfsdk:"jwt"`
+}
+
+func (r *ActivationJWT) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_activation_jwt"
+}
+
+func (r *ActivationJWT) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "An activation token that allows another account to import a private export, signed by " +
+			"the exporting account or one of its signing keys. The JWT is issued again whenever its claims change.",
+
+		Attributes: map[string]schema.Attribute{
+			"subject": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Subject of the export the activation grants access to",
+			},
+			"import_type": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Type of the export. Must be one of stream|service",
+				Validators: []validator.String{
+					stringvalidator.OneOf("stream", "service"),
+				},
+			},
+			"target_account": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Public key of the account that may import the export",
+			},
+			"tags": schema.SetAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				MarkdownDescription: "Tags of the activation. Tags are lowercased. Activation claims have no " +
+					"`description` or `info_url`, those of the export are set on `nkey_account_jwt`",
+			},
+			"issuer_seed": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Seed of the exporting account or an account signing key the JWT is signed with",
+				Sensitive:           true,
+			},
+			"issuer_account":   issuerAccountAttribute("that exports the subject"),
+			"expires_at":       expiresAtAttribute(),
+			"not_before":       notBeforeAttribute(),
+			"renew_before":     renewBeforeAttribute(),
+			"issued_at":        issuedAtAttribute(),
+			"deterministic_id": deterministicIDAttribute(),
+			"jwt_version":      jwtVersionAttribute(),
+			"issuer": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the account or signing key the JWT was signed with",
+			},
+			"jwt": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The encoded activation JWT",
+			},
+			"claims": claimsAttribute(),
+		},
+	}
+}
+
+func (r *ActivationJWT) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	// Nothing to compare against on create or destroy, or before all
+	// attributes are known
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() || !req.Config.Raw.IsFullyKnown() {
+		return
+	}
+
+	var plan, state ActivationJWTModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The JWT is only signed again if its claims change. Errors are reported
+	// on apply.
+	var diags diag.Diagnostics
+	r.issue(ctx, &plan, &diags)
+	if !diags.HasError() && sameClaims(plan.JWT, state.JWT,
+		!plan.ExpiresAt.Equal(state.ExpiresAt) || !plan.NotBefore.Equal(state.NotBefore),
+		!plan.IssuedAt.Equal(state.IssuedAt) || !plan.DeterministicID.Equal(state.DeterministicID),
+		r.provider.provenanceKeys()...) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("issuer"), state.Issuer)...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("jwt"), state.JWT)...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("claims"), claimsValue(state.JWT, &resp.Diagnostics))...)
+	} else if !diags.HasError() {
+		// The claims issued again on apply only differ in those that
+		// change with the issuance.
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("claims"),
+			claimsValue(plan.JWT, &resp.Diagnostics, volatileClaims(r.provider, plan.IssuedAt, plan.ExpiresAt, plan.NotBefore)...))...)
+	}
+}
+
+func (r *ActivationJWT) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.provider = data
+}
+
+func (r *ActivationJWT) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var data ActivationJWTModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.issue(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Trace(ctx, "created activation JWT resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.provider.register(ctx, "nkey_activation_jwt", data.JWT, &resp.Diagnostics)
+}
+
+func (r *ActivationJWT) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data ActivationJWTModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A JWT close to expiry is issued again on the next apply.
+	if renewalDue(data.JWT, data.RenewBefore) {
+		tflog.Info(ctx, "JWT is due for renewal, removing it from state", map[string]interface{}{
+			"renew_before": data.RenewBefore.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Claims are missing from the state of earlier versions.
+	if data.Claims.IsNull() {
+		data.Claims = claimsValue(data.JWT, &resp.Diagnostics)
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ActivationJWT) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var plan ActivationJWTModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The JWT in state is kept if its claims did not change.
+	reissued := plan.JWT.IsUnknown()
+	if reissued {
+		r.issue(ctx, &plan, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if reissued {
+		r.provider.notify(ctx, jwtEvent("reissued", "nkey_activation_jwt", plan.JWT), &resp.Diagnostics)
+		r.provider.register(ctx, "nkey_activation_jwt", plan.JWT, &resp.Diagnostics)
+	}
+}
+
+func (r *ActivationJWT) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Nothing to do here as the JWT only exists in state
+}
+
+// issue encodes the activation claims of data and signs them with the
+// issuer seed.
+func (r *ActivationJWT) issue(ctx context.Context, data *ActivationJWTModel, diags *diag.Diagnostics) {
+	keys, err := jwtSigner(data.IssuerSeed.ValueString(), path.Root("issuer_seed"), nkeys.PrefixByteAccount)
+	if err != nil {
+		addError(diags, "Invalid issuer seed", err)
+		return
+	}
+	defer keys.Wipe()
+
+	issuer, err := keys.PublicKey()
+	if err != nil {
+		addError(diags, "Invalid issuer seed", errorAt(path.Root("issuer_seed"), err))
+		return
+	}
+
+	claims := data.claims(ctx, diags)
+	if diags.HasError() {
+		return
+	}
+	claims.Tags.Add(r.provider.provenanceTags("nkey_activation_jwt", claims.Name)...)
+
+	claims.IssuerAccount = issuerAccount(data.IssuerAccount, issuer, diags)
+	if diags.HasError() {
+		return
+	}
+
+	token, err := jwtEncoder(data.JWTVersion)(claims, keys, data.DeterministicID.ValueBool(), diags)
+	if err != nil {
+		addError(diags, "Unable to issue activation JWT", err)
+		return
+	}
+	data.Claims = claimsValue(types.StringValue(token), diags)
+
+	data.Issuer = types.StringValue(issuer)
+	data.JWT = types.StringValue(token)
+}
+
+// claims returns the activation claims described by m.
+func (m *ActivationJWTModel) claims(ctx context.Context, diags *diag.Diagnostics) *jwt.ActivationClaims {
+	if err := checkPublicKey(m.TargetAccount.ValueString(), nkeys.PrefixByteAccount); err != nil {
+		addError(diags, "Invalid target account", errorAt(path.Root("target_account"), err))
+		return nil
+	}
+
+	claims := jwt.NewActivationClaims(m.TargetAccount.ValueString())
+	claims.ImportSubject = jwt.Subject(m.Subject.ValueString())
+	claims.ImportType = exportType(m.ImportType.ValueString())
+	claims.Tags.Add(tagList(ctx, m.Tags, diags)...)
+
+	setValidity(&claims.ClaimsData, m.IssuedAt, m.ExpiresAt, m.NotBefore, m.RenewBefore, diags)
+
+	return claims
+}
@@ -0,0 +1,89 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestActivationJWTIssuerAccount(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	account, _ := nkeys.CreateAccount()
+	accountKey, _ := account.PublicKey()
+	accountSeed, _ := account.Seed()
+	signer, _ := nkeys.CreateAccount()
+	signerKey, _ := signer.PublicKey()
+	signerSeed, _ := signer.Seed()
+	target, _ := nkeys.CreateAccount()
+	targetKey, _ := target.PublicKey()
+
+	tests := map[string]struct {
+		seed          []byte
+		issuerAccount tftypes.Value
+		// issuer and account are the expected iss and issuer_account claims.
+		issuer, account string
+	}{
+		"account": {
+			seed:          accountSeed,
+			issuerAccount: tftypes.NewValue(tftypes.String, nil),
+			issuer:        accountKey,
+		},
+		"account as issuer account": {
+			seed:          accountSeed,
+			issuerAccount: stringValue(accountKey),
+			issuer:        accountKey,
+		},
+		"signing key": {
+			seed:          signerSeed,
+			issuerAccount: stringValue(accountKey),
+			issuer:        signerKey,
+			account:       accountKey,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := p.create("nkey_activation_jwt", map[string]tftypes.Value{
+				"subject":        stringValue("billing.invoices.>"),
+				"import_type":    stringValue("stream"),
+				"target_account": stringValue(targetKey),
+				"issuer_seed":    stringValue(string(test.seed)),
+				"issuer_account": test.issuerAccount,
+			})
+
+			claims, err := jwt.DecodeActivationClaims(stringAttribute(t, state, "jwt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims.Issuer != test.issuer {
+				t.Errorf("iss = %s, want %s", claims.Issuer, test.issuer)
+			}
+			if claims.IssuerAccount != test.account {
+				t.Errorf("issuer_account = %q, want %q", claims.IssuerAccount, test.account)
+			}
+			if got := stringAttribute(t, state, "issuer"); got != test.issuer {
+				t.Errorf("issuer = %s, want %s", got, test.issuer)
+			}
+		})
+	}
+
+	t.Run("invalid issuer account", func(t *testing.T) {
+		_, diags := p.tryApply("nkey_activation_jwt", tftypes.Value{}, map[string]tftypes.Value{
+			"subject":        stringValue("billing.invoices.>"),
+			"import_type":    stringValue("stream"),
+			"target_account": stringValue(targetKey),
+			"issuer_seed":    stringValue(string(signerSeed)),
+			"issuer_account": stringValue(targetKey[1:]),
+		})
+		if !hasError(diags, "Invalid issuer account") {
+			t.Errorf("expected an invalid issuer account error, got %v", diags)
+		}
+	})
+}
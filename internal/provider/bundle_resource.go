@@ -0,0 +1,329 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &Bundle{}
+
+// bundleChecksumFile is the name of the checksum manifest inside a bundle,
+// in the format of sha256sum(1).
+const bundleChecksumFile = "SHA256SUMS"
+
+func NewBundle() resource.Resource {
+	return &Bundle{}
+}
+
+// Bundle defines the resource implementation.
+type Bundle struct {
+}
+
+// BundleModel describes the resource data model.
+type BundleModel struct {
+	Path      types.String `tfsdk:"path"`
+	Format    types.String `tfsdk:"format"`
+	Files     types.Map    `tfsdk:"files"`
+	Checksums types.Map    `tfsdk:"checksums"`
+	SHA256    types.String `tfsdk:"sha256"`
+}
+
+func (r *Bundle) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_bundle"
+}
+
+func (r *Bundle) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "An archive of credential files with a `SHA256SUMS` checksum manifest, written to a local file on apply. " +
+			"The archive is reproducible: the same files always yield the same archive.",
+
+		Attributes: map[string]schema.Attribute{
+			"path": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Path of the archive",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"format": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("tar.gz"),
+				MarkdownDescription: "Archive format. Must be one of tar.gz|zip",
+				Validators: []validator.String{
+					stringvalidator.OneOf("tar.gz", "zip"),
+				},
+			},
+			"files": schema.MapAttribute{
+				Required:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Map of file names inside the archive to their content, e.g. creds, JWTs or seeds",
+				Sensitive:           true,
+			},
+			"checksums": schema.MapAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Map of file names to the hex encoded SHA-256 checksum of their content",
+			},
+			"sha256": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Hex encoded SHA-256 checksum of the archive",
+			},
+		},
+	}
+}
+
+func (r *Bundle) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Nothing to do here as the bundle is written locally
+}
+
+func (r *Bundle) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var data BundleModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.write(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Trace(ctx, "created bundle resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Bundle) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data BundleModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// An archive that was removed or modified outside of Terraform is
+	// written again on the next apply.
+	content, err := os.ReadFile(data.Path.ValueString())
+	if errors.Is(err, os.ErrNotExist) || (err == nil && sha256Hex(content) != data.SHA256.ValueString()) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addError(&resp.Diagnostics, "Unable to read bundle", errorAt(path.Root("path"), err))
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Bundle) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var plan BundleModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.write(ctx, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *Bundle) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data BundleModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := os.Remove(data.Path.ValueString()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		addError(&resp.Diagnostics, "Unable to delete bundle", errorAt(path.Root("path"), err))
+	}
+}
+
+func (r *Bundle) write(ctx context.Context, data *BundleModel, diags *diag.Diagnostics) {
+	files := map[string]string{}
+
+	diags.Append(data.Files.ElementsAs(ctx, &files, false)...)
+	if diags.HasError() {
+		return
+	}
+
+	checksums := map[string]attr.Value{}
+	var sums strings.Builder
+
+	for _, name := range sortedKeys(files) {
+		if err := validBundleFileName(name); err != nil {
+			addError(diags, "Invalid file name", errorAt(path.Root("files").AtMapKey(name), err))
+			continue
+		}
+
+		sum := sha256Hex([]byte(files[name]))
+		checksums[name] = types.StringValue(sum)
+		fmt.Fprintf(&sums, "%s  %s\n", sum, name)
+	}
+	if diags.HasError() {
+		return
+	}
+
+	names := append(sortedKeys(files), bundleChecksumFile)
+	files[bundleChecksumFile] = sums.String()
+
+	var (
+		archive []byte
+		err     error
+	)
+
+	switch data.Format.ValueString() {
+	case "tar.gz":
+		archive, err = tarGzip(names, files)
+	case "zip":
+		archive, err = zipArchive(names, files)
+	default:
+		err = errorAt(path.Root("format"), fmt.Errorf("unsupported format %q, must be one of tar.gz|zip", data.Format.ValueString()))
+	}
+	if err != nil {
+		addError(diags, "Unable to create bundle", err)
+		return
+	}
+
+	if err := os.WriteFile(data.Path.ValueString(), archive, 0o600); err != nil {
+		addError(diags, "Unable to write bundle", errorAt(path.Root("path"), err))
+		return
+	}
+
+	data.Checksums = types.MapValueMust(types.StringType, checksums)
+	data.SHA256 = types.StringValue(sha256Hex(archive))
+}
+
+// validBundleFileName returns an error if name is not the path of a file
+// inside the archive: absolute, the archive root itself, such as "." or "a/..",
+// or outside of it.
+func validBundleFileName(name string) error {
+	clean := filepath.ToSlash(filepath.Clean(name))
+	switch {
+	case name == bundleChecksumFile:
+		return fmt.Errorf("%s is reserved for the checksum manifest", bundleChecksumFile)
+	case name == "" || filepath.IsAbs(name) || clean == "." || clean == ".." || strings.HasPrefix(clean, "../"):
+		return fmt.Errorf("%q must be a relative path inside the archive", name)
+	}
+	return nil
+}
+
+// tarGzip and zipArchive use fixed timestamps and modes so the archive only
+// depends on the file names and contents.
+func tarGzip(names []string, files map[string]string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	for _, name := range names {
+		hdr := &tar.Header{
+			Name:    filepath.ToSlash(name),
+			Mode:    0o600,
+			Size:    int64(len(files[name])),
+			ModTime: time.Unix(0, 0),
+			Format:  tar.FormatPAX,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(tw, files[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func zipArchive(names []string, files map[string]string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	zw := zip.NewWriter(&buf)
+
+	for _, name := range names {
+		hdr := &zip.FileHeader{
+			Name:     filepath.ToSlash(name),
+			Method:   zip.Deflate,
+			Modified: time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+		hdr.SetMode(0o600)
+
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(w, files[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
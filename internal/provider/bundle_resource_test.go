@@ -0,0 +1,163 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// readBundle returns the files of the archive in format.
+func readBundle(t *testing.T, format string, archive []byte) map[string]string {
+	t.Helper()
+
+	files := map[string]string{}
+	switch format {
+	case "tar.gz":
+		gz, err := gzip.NewReader(bytes.NewReader(archive))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tr := tar.NewReader(gz)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			content, err := io.ReadAll(tr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			files[hdr.Name] = string(content)
+		}
+	case "zip":
+		zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range zr.File {
+			r, err := f.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			content, err := io.ReadAll(r)
+			r.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			files[f.Name] = string(content)
+		}
+	}
+	return files
+}
+
+func TestBundle(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	account, _ := nkeys.CreateAccount()
+	accountKey, _ := account.PublicKey()
+	user, _ := nkeys.CreateUser()
+	userKey, _ := user.PublicKey()
+	userSeed, _ := user.Seed()
+	token, err := jwt.NewUserClaims(userKey).Encode(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds, err := jwt.FormatUserConfig(token, userSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{
+		"alice/user.creds": stringValue(string(creds)),
+		"alice/user.jwt":   stringValue(token),
+	})
+
+	for _, format := range []string{"tar.gz", "zip"} {
+		t.Run(format, func(t *testing.T) {
+			dir := t.TempDir()
+			config := func(name string) map[string]tftypes.Value {
+				return map[string]tftypes.Value{
+					"path":   stringValue(filepath.Join(dir, name)),
+					"format": stringValue(format),
+					"files":  files,
+				}
+			}
+			state := p.create("nkey_bundle", config("bundle"))
+
+			archive, err := os.ReadFile(filepath.Join(dir, "bundle"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := stringAttribute(t, state, "sha256"); got != sha256Hex(archive) {
+				t.Errorf("sha256 = %s, want the checksum of the archive", got)
+			}
+
+			content := readBundle(t, format, archive)
+			checksums := stringMapAttribute(t, state, "checksums")
+			want := fmt.Sprintf("%s  alice/user.creds\n%s  alice/user.jwt\n", checksums["alice/user.creds"], checksums["alice/user.jwt"])
+			if content[bundleChecksumFile] != want {
+				t.Errorf("%s = %q, want %q", bundleChecksumFile, content[bundleChecksumFile], want)
+			}
+			for name, sum := range checksums {
+				if sha256Hex([]byte(content[name])) != sum {
+					t.Errorf("checksum of %s does not match its content", name)
+				}
+			}
+
+			bundled, err := jwt.ParseDecoratedJWT([]byte(content["alice/user.creds"]))
+			if err != nil {
+				t.Fatal(err)
+			}
+			verifySignature(t, bundled, accountKey)
+			if claims, err := jwt.DecodeUserClaims(bundled); err != nil || claims.Subject != userKey {
+				t.Errorf("bundled creds of %v: %v", claims, err)
+			}
+
+			// The same files yield the same archive.
+			again := p.create("nkey_bundle", config("again"))
+			if stringAttribute(t, again, "sha256") != stringAttribute(t, state, "sha256") {
+				t.Error("the archive is not reproducible")
+			}
+		})
+	}
+
+	for _, name := range []string{bundleChecksumFile, "/etc/creds", "../creds", "..", ".", "creds/.."} {
+		t.Run("invalid "+name, func(t *testing.T) {
+			_, diags := p.tryApply("nkey_bundle", tftypes.Value{}, map[string]tftypes.Value{
+				"path":  stringValue(filepath.Join(t.TempDir(), "bundle")),
+				"files": tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{name: stringValue("x")}),
+			})
+			if !hasError(diags, "Invalid file name") {
+				t.Errorf("expected an invalid file name, got %v", diags)
+			}
+		})
+	}
+
+	t.Run("invalid format", func(t *testing.T) {
+		diags := p.validate("nkey_bundle", map[string]tftypes.Value{
+			"path":   stringValue(filepath.Join(t.TempDir(), "bundle")),
+			"format": stringValue("rar"),
+			"files":  tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{"creds": stringValue("x")}),
+		})
+		if !hasError(diags, "Invalid Attribute Value Match") {
+			t.Errorf("expected an invalid format, got %v", diags)
+		}
+	})
+}
@@ -0,0 +1,323 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/objectplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// maxCITTL is the longest time creds of a CI service account are valid for.
+const maxCITTL = 24 * time.Hour
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &CICreds{}
+var _ resource.ResourceWithConfigure = &CICreds{}
+var _ resource.ResourceWithValidateConfig = &CICreds{}
+
+func NewCICreds() resource.Resource {
+	return &CICreds{}
+}
+
+// CICreds defines the resource implementation.
+type CICreds struct {
+	provider *providerData
+}
+
+// CICredsModel describes the resource data model.
+type CICredsModel struct {
+	IssuerSeed    types.String      `tfsdk:"issuer_seed"`
+	IssuerAccount types.String      `tfsdk:"issuer_account"`
+	Name          types.String      `tfsdk:"name"`
+	Permissions   *PermissionsModel `tfsdk:"permissions"`
+	TTL           types.String      `tfsdk:"ttl"`
+	RenewBefore   types.String      `tfsdk:"renew_before"`
+	PublicKey     types.String      `tfsdk:"public_key"`
+	Seed          types.String      `tfsdk:"seed"`
+	JWT           types.String      `tfsdk:"jwt"`
+	Creds         types.String      `tfsdk:"creds"`
+	ExpiresAt     types.String      `tfsdk:"expires_at"`
+}
+
+func (r *CICreds) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_ci_creds"
+}
+
+func (r *CICreds) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	replace := []planmodifier.String{
+		stringplanmodifier.RequiresReplace(),
+	}
+	keep := []planmodifier.String{
+		stringplanmodifier.UseStateForUnknown(),
+	}
+
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Short-lived creds of a CI service account: a new user nkey and a JWT valid for at most " +
+			"24 hours. Once the creds are within `renew_before` of their expiry, refreshing removes them from state " +
+			"and the next apply issues them again for a new key. Changing any attribute issues them again as well, so " +
+			"run an apply before each pipeline run, or on a schedule shorter than the TTL.",
+
+		Attributes: map[string]schema.Attribute{
+			"issuer_seed": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Seed of the account or an account signing key the JWT is signed with",
+				Sensitive:           true,
+				PlanModifiers:       replace,
+			},
+			"issuer_account": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "Public key of the account the user belongs to. Required if `issuer_seed` is an " +
+					"account signing key",
+				PlanModifiers: replace,
+			},
+			"name": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Name of the service account",
+				PlanModifiers:       replace,
+			},
+			"permissions": schema.SingleNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "Publish and subscribe permissions of the service account",
+				Attributes:          permissionsResourceAttributes(),
+				PlanModifiers: []planmodifier.Object{
+					objectplanmodifier.RequiresReplace(),
+				},
+			},
+			"ttl": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("24h"),
+				MarkdownDescription: "Duration the creds are valid for, at most `24h`. Defaults to `24h`",
+				PlanModifiers:       replace,
+			},
+			"renew_before": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("1h"),
+				MarkdownDescription: "Duration before expiry from which on the creds are issued again on the next apply. Defaults to `1h`",
+			},
+			"public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the service account user",
+				PlanModifiers:       keep,
+			},
+			"seed": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Seed of the service account user",
+				Sensitive:           true,
+				PlanModifiers:       keep,
+			},
+			"jwt": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The encoded user JWT",
+				Sensitive:           true,
+				PlanModifiers:       keep,
+			},
+			"creds": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Creds file content of the service account",
+				Sensitive:           true,
+				PlanModifiers:       keep,
+			},
+			"expires_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "RFC 3339 timestamp the creds expire at",
+				PlanModifiers:       keep,
+			},
+		},
+	}
+}
+
+func (r *CICreds) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.provider = data
+}
+
+func (r *CICreds) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data CICredsModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ttl := maxCITTL
+	if !data.TTL.IsNull() && !data.TTL.IsUnknown() {
+		var err error
+		ttl, err = time.ParseDuration(data.TTL.ValueString())
+		if err != nil || ttl <= 0 || ttl > maxCITTL {
+			resp.Diagnostics.AddAttributeError(path.Root("ttl"), "Invalid TTL",
+				fmt.Sprintf("%q must be a positive duration of at most %s, such as \"8h\"", data.TTL.ValueString(), maxCITTL))
+			return
+		}
+	}
+
+	if !data.RenewBefore.IsNull() && !data.RenewBefore.IsUnknown() {
+		window, err := time.ParseDuration(data.RenewBefore.ValueString())
+		if err != nil || window < 0 || window >= ttl {
+			resp.Diagnostics.AddAttributeError(path.Root("renew_before"), "Invalid renewal window",
+				fmt.Sprintf("%q must be a duration shorter than the TTL of %s, such as \"1h\"", data.RenewBefore.ValueString(), ttl))
+		}
+	}
+}
+
+func (r *CICreds) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var data CICredsModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.issue(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Trace(ctx, "created CI creds resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.provider.register(ctx, "nkey_ci_creds", data.JWT, &resp.Diagnostics)
+}
+
+func (r *CICreds) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data CICredsModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Creds close to expiry are issued again on the next apply.
+	if renewalDue(data.JWT, data.RenewBefore) {
+		tflog.Info(ctx, "CI creds are due for renewal, removing them from state", map[string]interface{}{
+			"expires_at": data.ExpiresAt.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *CICreds) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan CICredsModel
+
+	// Only renew_before changes in place, the creds are kept.
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *CICreds) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Nothing to do here as the creds only exist in state and expire on their
+	// own.
+}
+
+// issue generates the user nkey of data and issues its JWT and creds, signed
+// with the issuer seed.
+func (r *CICreds) issue(ctx context.Context, data *CICredsModel, diags *diag.Diagnostics) {
+	keys, err := jwtSigner(data.IssuerSeed.ValueString(), path.Root("issuer_seed"), nkeys.PrefixByteAccount)
+	if err != nil {
+		addError(diags, "Invalid issuer seed", err)
+		return
+	}
+	defer keys.Wipe()
+
+	issuer, err := keys.PublicKey()
+	if err != nil {
+		addError(diags, "Invalid issuer seed", errorAt(path.Root("issuer_seed"), err))
+		return
+	}
+
+	user, err := newKeysetKey(nkeys.PrefixByteUser)
+	if err != nil {
+		addError(diags, "Unable to generate nkey", err)
+		return
+	}
+
+	// The claims are those of the user JWT resource.
+	claims := (&UserJWTModel{
+		PublicKey:   types.StringValue(user.publicKey),
+		Name:        data.Name,
+		Tags:        types.SetNull(types.StringType),
+		Permissions: data.Permissions,
+		ConnTypes:   types.SetNull(types.StringType),
+		ExpiresAt:   data.TTL,
+		RenewBefore: data.RenewBefore,
+	}).claims(ctx, diags)
+	if diags.HasError() {
+		return
+	}
+	claims.Tags.Add(r.provider.provenanceTags("nkey_ci_creds", claims.Name)...)
+	claims.IssuerAccount = issuerAccount(data.IssuerAccount, issuer, diags)
+	if diags.HasError() {
+		return
+	}
+
+	token, err := encodeJWT(claims, keys, false, diags)
+	if err != nil {
+		addError(diags, "Unable to issue user JWT", err)
+		return
+	}
+
+	creds, err := jwt.FormatUserConfig(token, []byte(user.seed))
+	if err != nil {
+		addError(diags, "Unable to render creds", err)
+		return
+	}
+	defer clear(creds)
+
+	data.PublicKey = types.StringValue(user.publicKey)
+	data.Seed = types.StringValue(user.seed)
+	data.JWT = types.StringValue(token)
+	data.Creds = types.StringValue(string(creds))
+	data.ExpiresAt = types.StringValue(time.Unix(claims.Expires, 0).UTC().Format(time.RFC3339))
+}
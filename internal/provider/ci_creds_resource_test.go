@@ -0,0 +1,107 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestCICreds(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	account, _ := nkeys.CreateAccount()
+	accountKey, _ := account.PublicKey()
+	signer, _ := nkeys.CreateAccount()
+	signerKey, _ := signer.PublicKey()
+	signerSeed, _ := signer.Seed()
+
+	config := func(ttl, renewBefore string) map[string]tftypes.Value {
+		config := map[string]tftypes.Value{
+			"issuer_seed":    stringValue(string(signerSeed)),
+			"issuer_account": stringValue(accountKey),
+			"name":           stringValue("ci-deploy"),
+		}
+		if ttl != "" {
+			config["ttl"] = stringValue(ttl)
+		}
+		if renewBefore != "" {
+			config["renew_before"] = stringValue(renewBefore)
+		}
+		return config
+	}
+
+	state := p.create("nkey_ci_creds", config("8h", ""))
+
+	claims, err := jwt.DecodeUserClaims(stringAttribute(t, state, "jwt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != stringAttribute(t, state, "public_key") || claims.Issuer != signerKey || claims.IssuerAccount != accountKey {
+		t.Errorf("JWT of %s issued by %s for %s", claims.Subject, claims.Issuer, claims.IssuerAccount)
+	}
+	if ttl := time.Duration(claims.Expires-claims.IssuedAt) * time.Second; ttl != 8*time.Hour {
+		t.Errorf("ttl = %s, want 8h", ttl)
+	}
+	if got, want := stringAttribute(t, state, "expires_at"), time.Unix(claims.Expires, 0).UTC().Format(time.RFC3339); got != want {
+		t.Errorf("expires_at = %s, want %s", got, want)
+	}
+	if kp, err := jwt.ParseDecoratedNKey([]byte(stringAttribute(t, state, "creds"))); err != nil {
+		t.Error(err)
+	} else if key, _ := kp.PublicKey(); key != claims.Subject {
+		t.Errorf("creds hold the seed of %s, want %s", key, claims.Subject)
+	}
+
+	t.Run("renewal", func(t *testing.T) {
+		// Creds within the renewal window are removed from state on
+		// refresh, so the next apply issues new ones.
+		if p.refresh("nkey_ci_creds", state).IsNull() {
+			t.Error("creds valid for 8h removed from state")
+		}
+
+		// The creds of the previous run, 30 minutes before their expiry.
+		expiring := *claims
+		expiring.Expires = time.Now().Add(30 * time.Minute).Unix()
+		token, err := expiring.Encode(signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var attrs map[string]tftypes.Value
+		if err := state.As(&attrs); err != nil {
+			t.Fatal(err)
+		}
+		attrs["jwt"] = stringValue(token)
+		due := tftypes.NewValue(state.Type(), attrs)
+
+		if !p.refresh("nkey_ci_creds", due).IsNull() {
+			t.Error("creds due for renewal kept in state")
+		}
+
+		renewed := p.create("nkey_ci_creds", config("8h", ""))
+		if stringAttribute(t, renewed, "public_key") == stringAttribute(t, due, "public_key") {
+			t.Error("renewed creds reuse the key of the previous ones")
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, test := range []struct {
+			ttl, renewBefore, err string
+		}{
+			{ttl: "48h", err: "Invalid TTL"},
+			{ttl: "tomorrow", err: "Invalid TTL"},
+			{ttl: "-1h", err: "Invalid TTL"},
+			{ttl: "1h", renewBefore: "2h", err: "Invalid renewal window"},
+			{renewBefore: "24h", err: "Invalid renewal window"},
+		} {
+			if diags := p.validate("nkey_ci_creds", config(test.ttl, test.renewBefore)); !hasError(diags, test.err) {
+				t.Errorf("ttl %q, renew_before %q: expected %q, got %v", test.ttl, test.renewBefore, test.err, diags)
+			}
+		}
+	})
+}
@@ -0,0 +1,156 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// claimsAttribute returns the claims attribute of the JWT resources.
+func claimsAttribute() schema.DynamicAttribute {
+	return schema.DynamicAttribute{
+		Computed: true,
+		MarkdownDescription: "Claims of the JWT as an object, e.g. `claims.nats.limits.conn`, so plans show which " +
+			"claims change and policy tools can read them from the state without decoding the JWT. The claims that " +
+			"change with every issuance, such as `iat` and `jti`, are only known after apply",
+	}
+}
+
+// claimsValue returns the claims of token as a dynamic object value, with
+// the claims at the dotted paths volatile unknown. It is null if token is.
+func claimsValue(token types.String, diags *diag.Diagnostics, volatile ...string) types.Dynamic {
+	if token.IsNull() || token.IsUnknown() {
+		return types.DynamicNull()
+	}
+
+	var payload json.RawMessage
+	if err := jwtClaims(token.ValueString(), &payload); err != nil {
+		diags.AddError("Invalid JWT", err.Error())
+		return types.DynamicNull()
+	}
+
+	// Numbers are kept as they are, they may exceed the precision of a
+	// float64.
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	var claims interface{}
+	if err := dec.Decode(&claims); err != nil {
+		diags.AddError("Invalid JWT", err.Error())
+		return types.DynamicNull()
+	}
+
+	unknown := map[string]bool{}
+	for _, p := range volatile {
+		unknown[p] = true
+	}
+
+	v, err := jsonValue(claims, "", unknown)
+	if err != nil {
+		diags.AddError("Invalid JWT", err.Error())
+		return types.DynamicNull()
+	}
+	return types.DynamicValue(v)
+}
+
+// volatileClaims returns the paths of the claims of a JWT issued again on
+// apply that may differ from those issued during plan: the time of issuance
+// and the ID unless issuedAt pins them, the validity times relative to it and
+// the provenance tags, which describe the run.
+func volatileClaims(p *providerData, issuedAt, expiresAt, notBefore types.String) []string {
+	var volatile []string
+	if len(p.provenanceKeys()) > 0 {
+		volatile = append(volatile, "tags", "nats.tags")
+	}
+
+	if _, err := time.Parse(time.RFC3339, issuedAt.ValueString()); err == nil {
+		return volatile
+	}
+	volatile = append(volatile, "iat", "jti")
+	for claim, v := range map[string]types.String{"exp": expiresAt, "nbf": notBefore} {
+		if _, err := time.Parse(time.RFC3339, v.ValueString()); err != nil {
+			volatile = append(volatile, claim)
+		}
+	}
+	return volatile
+}
+
+// jsonValue converts the decoded JSON v at the dotted path p into a value,
+// unknown for the paths in unknown.
+func jsonValue(v interface{}, p string, unknown map[string]bool) (attr.Value, error) {
+	var value attr.Value
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		attrTypes := make(map[string]attr.Type, len(v))
+		attrs := make(map[string]attr.Value, len(v))
+		for _, key := range keys {
+			child := key
+			if p != "" {
+				child = p + "." + key
+			}
+			elem, err := jsonValue(v[key], child, unknown)
+			if err != nil {
+				return nil, err
+			}
+			attrTypes[key] = elem.Type(context.Background())
+			attrs[key] = elem
+		}
+		obj, diags := types.ObjectValue(attrTypes, attrs)
+		if diags.HasError() {
+			return nil, fmt.Errorf("%s: %s", p, diags[0].Detail())
+		}
+		value = obj
+	case []interface{}:
+		elemTypes := make([]attr.Type, len(v))
+		elems := make([]attr.Value, len(v))
+		for i, e := range v {
+			elem, err := jsonValue(e, fmt.Sprintf("%s.%d", p, i), unknown)
+			if err != nil {
+				return nil, err
+			}
+			elemTypes[i] = elem.Type(context.Background())
+			elems[i] = elem
+		}
+		tuple, diags := types.TupleValue(elemTypes, elems)
+		if diags.HasError() {
+			return nil, fmt.Errorf("%s: %s", p, diags[0].Detail())
+		}
+		value = tuple
+	case json.Number:
+		n, ok := new(big.Float).SetString(v.String())
+		if !ok {
+			return nil, fmt.Errorf("%s: invalid number %s", p, v)
+		}
+		value = types.NumberValue(n)
+	case string:
+		value = types.StringValue(v)
+	case bool:
+		value = types.BoolValue(v)
+	default:
+		value = types.StringNull()
+	}
+
+	if !unknown[p] {
+		return value, nil
+	}
+	t := value.Type(context.Background())
+	return t.ValueFromTerraform(context.Background(), tftypes.NewValue(t.TerraformType(context.Background()), tftypes.UnknownValue))
+}
@@ -0,0 +1,100 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/nkeys"
+)
+
+func TestJWTClaims(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	account, _ := nkeys.CreateAccount()
+	accountKey, _ := account.PublicKey()
+	seed, _ := account.Seed()
+	user, _ := nkeys.CreateUser()
+	userKey, _ := user.PublicKey()
+
+	config := map[string]tftypes.Value{
+		"public_key":  stringValue(userKey),
+		"issuer_seed": stringValue(string(seed)),
+		"name":        stringValue("gateway"),
+		"expires_at":  stringValue("720h"),
+	}
+	claimsOf := func(t *testing.T, v tftypes.Value) tftypes.Value {
+		t.Helper()
+		return attribute(t, v, "claims")
+	}
+
+	state := p.create("nkey_user_jwt", config)
+	claims := claimsOf(t, state)
+	for name, want := range map[string]string{"sub": userKey, "iss": accountKey, "name": "gateway"} {
+		if got := stringAttribute(t, claims, name); got != want {
+			t.Errorf("claims.%s = %s, want %s", name, got, want)
+		}
+	}
+	if got := stringAttribute(t, attribute(t, claims, "nats"), "type"); got != "user" {
+		t.Errorf("claims.nats.type = %s, want user", got)
+	}
+
+	schema := p.resourceSchema("nkey_user_jwt")
+
+	t.Run("unchanged", func(t *testing.T) {
+		resp := p.plan("nkey_user_jwt", state, config)
+		checkDiagnostics(t, resp.Diagnostics)
+		if planned := claimsOf(t, p.value(schema, resp.PlannedState)); !planned.Equal(claims) {
+			t.Errorf("claims = %v, want those in state", planned)
+		}
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		changed := map[string]tftypes.Value{}
+		for k, v := range config {
+			changed[k] = v
+		}
+		changed["name"] = stringValue("renamed")
+
+		resp := p.plan("nkey_user_jwt", state, changed)
+		checkDiagnostics(t, resp.Diagnostics)
+		planned := claimsOf(t, p.value(schema, resp.PlannedState))
+
+		// The diff shows the changed claim, the others that change with the
+		// issuance are only known after apply.
+		if got := stringAttribute(t, planned, "name"); got != "renamed" {
+			t.Errorf("planned claims.name = %s, want renamed", got)
+		}
+		for _, name := range []string{"iat", "jti", "exp"} {
+			if attribute(t, planned, name).IsKnown() {
+				t.Errorf("planned claims.%s is known", name)
+			}
+		}
+		if stringAttribute(t, planned, "sub") != userKey {
+			t.Error("planned claims.sub is not the user")
+		}
+	})
+
+	t.Run("pinned", func(t *testing.T) {
+		pinned := map[string]tftypes.Value{}
+		for k, v := range config {
+			pinned[k] = v
+		}
+		pinned["issued_at"] = stringValue("2026-01-01T00:00:00Z")
+
+		resp := p.plan("nkey_user_jwt", state, pinned)
+		checkDiagnostics(t, resp.Diagnostics)
+		planned := claimsOf(t, p.value(schema, resp.PlannedState))
+		if !planned.IsFullyKnown() {
+			t.Errorf("planned claims = %v, want all known with a pinned iat", planned)
+		}
+
+		// Terraform requires the claims applied to be those planned.
+		if applied := claimsOf(t, p.apply("nkey_user_jwt", state, pinned)); !applied.Equal(planned) {
+			t.Errorf("applied claims = %v, want the planned %v", applied, planned)
+		}
+	})
+}
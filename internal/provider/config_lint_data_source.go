@@ -0,0 +1,215 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ConfigLint{}
+
+var (
+	publicKeyPattern = regexp.MustCompile(`\b[OACNUX][A-Z2-7]{55}\b`)
+	jwtPattern       = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)
+
+	systemAccountPattern = regexp.MustCompile(`\bsystem_account\s*[:=]?\s*["']?(A[A-Z2-7]{55})\b`)
+)
+
+func NewConfigLint() datasource.DataSource {
+	return &ConfigLint{}
+}
+
+// ConfigLint defines the data source implementation.
+type ConfigLint struct {
+}
+
+// ConfigLintModel describes the data source data model.
+type ConfigLintModel struct {
+	Path           types.String `tfsdk:"path"`
+	KnownKeys      types.Set    `tfsdk:"known_keys"`
+	JWTs           types.Map    `tfsdk:"jwts"`
+	ReferencedKeys types.Set    `tfsdk:"referenced_keys"`
+	UnknownKeys    types.Set    `tfsdk:"unknown_keys"`
+	StaleJWTs      types.Set    `tfsdk:"stale_jwts"`
+	Valid          types.Bool   `tfsdk:"valid"`
+}
+
+func (d *ConfigLint) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_config_lint"
+}
+
+func (d *ConfigLint) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Checks the nkeys and JWTs referenced by a nats-server configuration file, e.g. in `users`, " +
+			"`operator` and `resolver_preload`, against the keys managed in Terraform. Warns when its `system_account` does not " +
+			"resolve or differs from the one of the operator JWT.",
+
+		Attributes: map[string]schema.Attribute{
+			"path": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Path of the nats-server configuration file",
+			},
+			"known_keys": schema.SetAttribute{
+				Required:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Public keys that may be referenced by the configuration",
+			},
+			"jwts": schema.MapAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Map of public keys to their current JWT. Inline JWTs for these subjects that differ are reported as stale",
+			},
+			"referenced_keys": schema.SetAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Public keys referenced by the configuration, including the subjects of inline JWTs",
+			},
+			"unknown_keys": schema.SetAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Referenced public keys that are not in `known_keys`",
+			},
+			"stale_jwts": schema.SetAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Subjects of inline JWTs that differ from the JWT given in `jwts`",
+			},
+			"valid": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the configuration references neither unknown keys nor stale JWTs",
+			},
+		},
+	}
+}
+
+func (d *ConfigLint) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ConfigLintModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var known []string
+	jwts := map[string]string{}
+
+	resp.Diagnostics.Append(data.KnownKeys.ElementsAs(ctx, &known, false)...)
+	if !data.JWTs.IsNull() {
+		resp.Diagnostics.Append(data.JWTs.ElementsAs(ctx, &jwts, false)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	content, err := os.ReadFile(data.Path.ValueString())
+	if err != nil {
+		addError(&resp.Diagnostics, "Unable to read configuration", errorAt(path.Root("path"), err))
+		return
+	}
+
+	if redactSecrets(string(content)) != string(content) {
+		resp.Diagnostics.AddAttributeWarning(path.Root("path"), "Configuration contains secrets",
+			"The configuration file contains nkey seeds or private keys, which nats-server never needs.")
+	}
+
+	referenced := map[string]bool{}
+	stale := map[string]bool{}
+	preloaded := map[string]bool{}
+	var systemAccounts []string
+
+	for _, key := range publicKeyPattern.FindAllString(string(content), -1) {
+		if nkeys.IsValidPublicKey(key) {
+			referenced[key] = true
+		}
+	}
+
+	for _, token := range jwtPattern.FindAllString(string(content), -1) {
+		sub, err := jwtSubject(token)
+		if err != nil || !nkeys.IsValidPublicKey(sub) {
+			continue
+		}
+		referenced[sub] = true
+		preloaded[sub] = true
+
+		if operator, err := jwt.DecodeOperatorClaims(token); err == nil && operator.SystemAccount != "" {
+			systemAccounts = append(systemAccounts, operator.SystemAccount)
+		}
+
+		if current, ok := jwts[sub]; ok && current != token {
+			stale[sub] = true
+		}
+	}
+
+	isKnown := map[string]bool{}
+	for _, key := range known {
+		isKnown[key] = true
+	}
+
+	var unknown []string
+	for key := range referenced {
+		if !isKnown[key] {
+			unknown = append(unknown, key)
+		}
+	}
+
+	for _, key := range unknown {
+		resp.Diagnostics.AddAttributeWarning(path.Root("path"), "Unknown key referenced",
+			fmt.Sprintf("%s is referenced by %s but not managed", key, data.Path.ValueString()))
+	}
+
+	// The servers resolve the system account like any other, so its JWT has
+	// to be preloaded or among the managed ones pushed to the resolver.
+	if m := systemAccountPattern.FindStringSubmatch(string(content)); m != nil {
+		systemAccount := m[1]
+		if _, managed := jwts[systemAccount]; !managed && !preloaded[systemAccount] {
+			resp.Diagnostics.AddAttributeWarning(path.Root("path"), "System account does not resolve",
+				fmt.Sprintf("%s is the system account of %s, but neither its resolver_preload nor jwts has its JWT",
+					systemAccount, data.Path.ValueString()))
+		}
+		for _, operatorSystemAccount := range systemAccounts {
+			if operatorSystemAccount != systemAccount {
+				resp.Diagnostics.AddAttributeWarning(path.Root("path"), "System account mismatch",
+					fmt.Sprintf("%s is the system account of %s, but the operator JWT names %s",
+						systemAccount, data.Path.ValueString(), operatorSystemAccount))
+			}
+		}
+	}
+
+	data.ReferencedKeys = stringSet(sortedKeys(referenced))
+	data.UnknownKeys = stringSet(unknown)
+	data.StaleJWTs = stringSet(sortedKeys(stale))
+	data.Valid = types.BoolValue(len(unknown) == 0 && len(stale) == 0)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// stringSet converts values into a set value, an empty set if there are none.
+func stringSet(values []string) types.Set {
+	sort.Strings(values)
+
+	elements := make([]attr.Value, len(values))
+	for i, v := range values {
+		elements[i] = types.StringValue(v)
+	}
+
+	return types.SetValueMust(types.StringType, elements)
+}
@@ -0,0 +1,93 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestConfigLintSystemAccount(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	operator, _ := nkeys.CreateOperator()
+	operatorKey, _ := operator.PublicKey()
+	system, _ := nkeys.CreateAccount()
+	systemKey, _ := system.PublicKey()
+	other, _ := nkeys.CreateAccount()
+	otherKey, _ := other.PublicKey()
+
+	systemJWT, err := jwt.NewAccountClaims(systemKey).Encode(operator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	operatorClaims := jwt.NewOperatorClaims(operatorKey)
+	operatorClaims.SystemAccount = systemKey
+	operatorJWT, err := operatorClaims.Encode(operator)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		systemAccount string
+		preload       string
+		jwts          map[string]tftypes.Value
+		// unresolved and mismatch report whether the respective warnings
+		// are expected.
+		unresolved, mismatch bool
+	}{
+		"preloaded": {
+			systemAccount: systemKey,
+			preload:       fmt.Sprintf("%s: %s", systemKey, systemJWT),
+		},
+		"managed": {
+			systemAccount: systemKey,
+			jwts:          map[string]tftypes.Value{systemKey: stringValue(systemJWT)},
+		},
+		"unresolved": {
+			systemAccount: systemKey,
+			unresolved:    true,
+		},
+		"mismatch": {
+			systemAccount: otherKey,
+			preload:       fmt.Sprintf("%s: %s", systemKey, systemJWT),
+			unresolved:    true,
+			mismatch:      true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "nats.conf")
+			content := fmt.Sprintf("operator: %s\nsystem_account: %s\nresolver: MEMORY\nresolver_preload: {\n  %s\n}\n",
+				operatorJWT, test.systemAccount, test.preload)
+			if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, diags := p.read("nkey_config_lint", map[string]tftypes.Value{
+				"path": stringValue(file),
+				"known_keys": tftypes.NewValue(tftypes.Set{ElementType: tftypes.String}, []tftypes.Value{
+					stringValue(operatorKey), stringValue(systemKey), stringValue(otherKey),
+				}),
+				"jwts": tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, test.jwts),
+			})
+			checkDiagnostics(t, diags)
+
+			if got := hasWarning(diags, "System account does not resolve"); got != test.unresolved {
+				t.Errorf("unresolved system account warning = %t, want %t", got, test.unresolved)
+			}
+			if got := hasWarning(diags, "System account mismatch"); got != test.mismatch {
+				t.Errorf("system account mismatch warning = %t, want %t", got, test.mismatch)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// ConnectionTLSModel describes the TLS settings clients connect with.
+type ConnectionTLSModel struct {
+	CACert     types.String `tfsdk:"ca_cert"`
+	ServerName types.String `tfsdk:"server_name"`
+}
+
+// ConnectionModel describes everything a client needs to connect as a user.
+type ConnectionModel struct {
+	Servers     types.List   `tfsdk:"servers"`
+	URL         types.String `tfsdk:"url"`
+	JWT         types.String `tfsdk:"jwt"`
+	Seed        types.String `tfsdk:"seed"`
+	Creds       types.String `tfsdk:"creds"`
+	TLSRequired types.Bool   `tfsdk:"tls_required"`
+	CACert      types.String `tfsdk:"ca_cert"`
+	ServerName  types.String `tfsdk:"tls_server_name"`
+}
+
+// connectionAttrTypes are the attribute types of the connection object.
+var connectionAttrTypes = map[string]attr.Type{
+	"servers":         types.ListType{ElemType: types.StringType},
+	"url":             types.StringType,
+	"jwt":             types.StringType,
+	"seed":            types.StringType,
+	"creds":           types.StringType,
+	"tls_required":    types.BoolType,
+	"ca_cert":         types.StringType,
+	"tls_server_name": types.StringType,
+}
+
+// connectionServersAttribute returns the servers attribute of the resources
+// with a connection object.
+func connectionServersAttribute() schema.ListAttribute {
+	return schema.ListAttribute{
+		Optional:            true,
+		ElementType:         types.StringType,
+		MarkdownDescription: "NATS server URLs clients connect to, passed on in `connection_info`",
+	}
+}
+
+// connectionTLSAttribute returns the tls attribute of the resources with a
+// connection object.
+func connectionTLSAttribute() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Optional:            true,
+		MarkdownDescription: "TLS settings clients connect with, passed on in `connection_info`",
+		Attributes: map[string]schema.Attribute{
+			"ca_cert": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "PEM encoded CA certificates the server certificates are verified with",
+			},
+			"server_name": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Name the server certificates are verified for, if it differs from the host of the URLs",
+			},
+		},
+	}
+}
+
+// connectionAttribute returns the connection_info attribute of the resources
+// issuing creds.
+func connectionAttribute() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Computed: true,
+		MarkdownDescription: "Everything a client needs to connect as the user in one object, to hand to application " +
+			"modules instead of wiring each attribute",
+		Sensitive: true,
+		Attributes: map[string]schema.Attribute{
+			"servers": schema.ListAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "NATS server URLs to connect to",
+			},
+			"url": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The server URLs separated by commas, as NATS clients accept them in a single URL",
+			},
+			"jwt": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The encoded user JWT",
+			},
+			"seed": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Seed of the user",
+			},
+			"creds": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Creds file content of the user",
+			},
+			"tls_required": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether clients must connect with TLS: if a URL has the `tls` or `wss` scheme or a CA certificate is set",
+			},
+			"ca_cert": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "PEM encoded CA certificates to verify the servers with",
+			},
+			"tls_server_name": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Name to verify the server certificates for",
+			},
+		},
+	}
+}
+
+// connectionValue returns the connection object of the user with the token,
+// seed and creds connecting to servers with tls.
+func connectionValue(ctx context.Context, servers types.List, tls *ConnectionTLSModel, token, seed, creds types.String, diags *diag.Diagnostics) types.Object {
+	var urls []string
+	if !servers.IsNull() && !servers.IsUnknown() {
+		diags.Append(servers.ElementsAs(ctx, &urls, false)...)
+	}
+
+	conn := ConnectionModel{
+		Servers:     types.ListNull(types.StringType),
+		URL:         types.StringNull(),
+		JWT:         token,
+		Seed:        seed,
+		Creds:       creds,
+		TLSRequired: types.BoolValue(false),
+		CACert:      types.StringNull(),
+		ServerName:  types.StringNull(),
+	}
+	if len(urls) > 0 {
+		conn.Servers = servers
+		conn.URL = types.StringValue(strings.Join(urls, ","))
+	}
+	for _, server := range urls {
+		if u, err := url.Parse(server); err == nil && (u.Scheme == "tls" || u.Scheme == "wss") {
+			conn.TLSRequired = types.BoolValue(true)
+		}
+	}
+	if tls != nil {
+		conn.CACert = tls.CACert
+		conn.ServerName = tls.ServerName
+		if !tls.CACert.IsNull() {
+			conn.TLSRequired = types.BoolValue(true)
+		}
+	}
+
+	v, d := types.ObjectValueFrom(ctx, connectionAttrTypes, conn)
+	diags.Append(d...)
+	return v
+}
@@ -0,0 +1,180 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &Creds{}
+
+func NewCreds() resource.Resource {
+	return &Creds{}
+}
+
+// Creds defines the resource implementation.
+type Creds struct {
+}
+
+// CredsModel describes the resource data model.
+type CredsModel struct {
+	JWT        types.String        `tfsdk:"jwt"`
+	Seed       types.String        `tfsdk:"seed"`
+	Servers    types.List          `tfsdk:"servers"`
+	TLS        *ConnectionTLSModel `tfsdk:"tls"`
+	Creds      types.String        `tfsdk:"creds"`
+	Connection types.Object        `tfsdk:"connection_info"`
+}
+
+func (r *Creds) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_creds"
+}
+
+func (r *Creds) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Content of a NATS creds file, the decorated user JWT followed by the decorated user seed, " +
+			"as expected by NATS clients and the `nats` CLI.",
+
+		Attributes: map[string]schema.Attribute{
+			"jwt": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The encoded user JWT",
+				Sensitive:           true,
+			},
+			"seed": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Seed of the user the JWT was issued for",
+				Sensitive:           true,
+			},
+			"servers": connectionServersAttribute(),
+			"tls":     connectionTLSAttribute(),
+			"creds": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The creds file content",
+				Sensitive:           true,
+			},
+			"connection_info": connectionAttribute(),
+		},
+	}
+}
+
+func (r *Creds) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var data CredsModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.render(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Trace(ctx, "created creds resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Creds) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data CredsModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Creds) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var plan CredsModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.render(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *Creds) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Nothing to do here as the creds only exist in state
+}
+
+// render sets the creds file content and connection of m, checking that the
+// seed belongs to the user the JWT was issued for.
+func (m *CredsModel) render(ctx context.Context, diags *diag.Diagnostics) {
+	token := strings.TrimSpace(m.JWT.ValueString())
+	claims, err := jwt.DecodeUserClaims(token)
+	if err != nil {
+		addError(diags, "Invalid user JWT", errorAt(path.Root("jwt"), err))
+		return
+	}
+
+	keys, err := jwtSigner(m.Seed.ValueString(), path.Root("seed"), nkeys.PrefixByteUser)
+	if err != nil {
+		addError(diags, "Invalid user seed", err)
+		return
+	}
+	defer keys.Wipe()
+
+	pubKey, err := keys.PublicKey()
+	if err != nil {
+		addError(diags, "Invalid user seed", errorAt(path.Root("seed"), err))
+		return
+	}
+	if pubKey != claims.Subject {
+		addError(diags, "Invalid user seed", errorAt(path.Root("seed"),
+			fmt.Errorf("the seed belongs to %s, but the JWT was issued for %s", pubKey, claims.Subject)))
+		return
+	}
+
+	seed, err := keys.Seed()
+	if err != nil {
+		addError(diags, "Invalid user seed", errorAt(path.Root("seed"), err))
+		return
+	}
+	defer clear(seed)
+
+	creds, err := jwt.FormatUserConfig(token, seed)
+	if err != nil {
+		addError(diags, "Unable to render creds", err)
+		return
+	}
+	defer clear(creds)
+
+	m.Creds = types.StringValue(string(creds))
+	m.Connection = connectionValue(ctx, m.Servers, m.TLS, types.StringValue(token), types.StringValue(string(seed)), m.Creds, diags)
+}
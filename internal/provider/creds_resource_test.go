@@ -0,0 +1,172 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestCreds(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	account, _ := nkeys.CreateAccount()
+	accountKey, _ := account.PublicKey()
+	user, _ := nkeys.CreateUser()
+	userKey, _ := user.PublicKey()
+	userSeed, _ := user.Seed()
+	other, _ := nkeys.CreateUser()
+	otherSeed, _ := other.Seed()
+
+	claims := jwt.NewUserClaims(userKey)
+	claims.Name = "alice"
+	claims.Pub.Allow.Add("orders.>")
+	token, err := claims.Encode(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The JWT is taken as pasted from a file, with a trailing newline.
+	state := p.create("nkey_creds", map[string]tftypes.Value{
+		"jwt":  stringValue(token + "\n"),
+		"seed": stringValue(string(userSeed)),
+	})
+
+	creds := []byte(stringAttribute(t, state, "creds"))
+	decorated, err := jwt.ParseDecoratedJWT(creds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifySignature(t, decorated, accountKey)
+
+	decoded, err := jwt.DecodeUserClaims(decorated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Subject != userKey || decoded.Issuer != accountKey {
+		t.Errorf("creds JWT of %s issued by %s, want %s issued by %s", decoded.Subject, decoded.Issuer, userKey, accountKey)
+	}
+	if decoded.Name != "alice" || !decoded.Pub.Allow.Contains("orders.>") {
+		t.Errorf("creds JWT claims = %s, %v", decoded.Name, decoded.Pub.Allow)
+	}
+
+	kp, err := jwt.ParseDecoratedUserNKey(creds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key, _ := kp.PublicKey(); key != userKey {
+		t.Errorf("creds hold the seed of %s, want %s", key, userKey)
+	}
+
+	operator, _ := nkeys.CreateOperator()
+	accountToken, err := jwt.NewAccountClaims(accountKey).Encode(operator)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, test := range map[string]struct {
+		token, seed string
+		err         string
+	}{
+		"other user":  {token, string(otherSeed), "Invalid user seed"},
+		"account JWT": {accountToken, string(userSeed), "Invalid user JWT"},
+		"malformed":   {"not a JWT", string(userSeed), "Invalid user JWT"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, diags := p.tryApply("nkey_creds", tftypes.Value{}, map[string]tftypes.Value{
+				"jwt":  stringValue(test.token),
+				"seed": stringValue(test.seed),
+			})
+			if !hasError(diags, test.err) {
+				t.Errorf("expected %q, got %v", test.err, diags)
+			}
+		})
+	}
+}
+
+func TestCredsConnectionInfo(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	account, _ := nkeys.CreateAccount()
+	user, _ := nkeys.CreateUser()
+	userKey, _ := user.PublicKey()
+	userSeed, _ := user.Seed()
+	token, err := jwt.NewUserClaims(userKey).Encode(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tlsType := p.resourceSchema("nkey_creds").ValueType().(tftypes.Object).AttributeTypes["tls"]
+	servers := func(urls ...string) tftypes.Value {
+		values := make([]tftypes.Value, len(urls))
+		for i, u := range urls {
+			values[i] = stringValue(u)
+		}
+		return tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, values)
+	}
+
+	tests := map[string]struct {
+		servers, tls tftypes.Value
+		url          string
+		tlsRequired  bool
+	}{
+		"no servers": {
+			servers: tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, nil),
+			tls:     tftypes.NewValue(tlsType, nil),
+		},
+		"plain": {
+			servers: servers("nats://a:4222", "nats://b:4222"),
+			tls:     tftypes.NewValue(tlsType, nil),
+			url:     "nats://a:4222,nats://b:4222",
+		},
+		"tls scheme": {
+			servers:     servers("tls://a:4222"),
+			tls:         tftypes.NewValue(tlsType, nil),
+			url:         "tls://a:4222",
+			tlsRequired: true,
+		},
+		"ca cert": {
+			servers: servers("nats://a:4222"),
+			tls: tftypes.NewValue(tlsType, map[string]tftypes.Value{
+				"ca_cert":     stringValue("-----BEGIN CERTIFICATE-----"),
+				"server_name": stringValue("nats.example.com"),
+			}),
+			url:         "nats://a:4222",
+			tlsRequired: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := p.create("nkey_creds", map[string]tftypes.Value{
+				"jwt":     stringValue(token),
+				"seed":    stringValue(string(userSeed)),
+				"servers": test.servers,
+				"tls":     test.tls,
+			})
+
+			conn := attribute(t, state, "connection_info")
+			if got := stringAttribute(t, conn, "url"); got != test.url {
+				t.Errorf("url = %q, want %q", got, test.url)
+			}
+			if stringAttribute(t, conn, "jwt") != token || stringAttribute(t, conn, "seed") != string(userSeed) {
+				t.Error("connection_info does not carry the JWT and seed")
+			}
+			if stringAttribute(t, conn, "creds") != stringAttribute(t, state, "creds") {
+				t.Error("connection_info does not carry the creds")
+			}
+			var tlsRequired bool
+			if err := attribute(t, conn, "tls_required").As(&tlsRequired); err != nil {
+				t.Fatal(err)
+			}
+			if tlsRequired != test.tlsRequired {
+				t.Errorf("tls_required = %t, want %t", tlsRequired, test.tlsRequired)
+			}
+		})
+	}
+}
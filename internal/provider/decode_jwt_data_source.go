@@ -0,0 +1,281 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/nats-io/jwt/v2"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &DecodeJWT{}
+
+func NewDecodeJWT() datasource.DataSource {
+	return &DecodeJWT{}
+}
+
+// DecodeJWT defines the data source implementation.
+type DecodeJWT struct {
+}
+
+// DecodeJWTModel describes the data source data model.
+type DecodeJWTModel struct {
+	JWT           types.String      `tfsdk:"jwt"`
+	ClaimType     types.String      `tfsdk:"type"`
+	Name          types.String      `tfsdk:"name"`
+	Subject       types.String      `tfsdk:"subject"`
+	Issuer        types.String      `tfsdk:"issuer"`
+	IssuerAccount types.String      `tfsdk:"issuer_account"`
+	IssuedAt      types.String      `tfsdk:"issued_at"`
+	ExpiresAt     types.String      `tfsdk:"expires_at"`
+	NotBefore     types.String      `tfsdk:"not_before"`
+	Tags          []string          `tfsdk:"tags"`
+	Permissions   *PermissionsModel `tfsdk:"permissions"`
+	Limits        *JWTLimitsModel   `tfsdk:"limits"`
+	Claims        types.String      `tfsdk:"claims"`
+}
+
+// JWTLimitsModel holds the limits of a user or account JWT, -1 is unlimited.
+type JWTLimitsModel struct {
+	Subscriptions types.Int64 `tfsdk:"subscriptions"`
+	Data          types.Int64 `tfsdk:"data"`
+	Payload       types.Int64 `tfsdk:"payload"`
+	Connections   types.Int64 `tfsdk:"connections"`
+}
+
+func (d *DecodeJWT) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_decode_jwt"
+}
+
+// decodedPermissionsAttributes returns the computed attributes of a
+// PermissionsModel.
+func decodedPermissionsAttributes() map[string]schema.Attribute {
+	permission := func(action string) schema.SingleNestedAttribute {
+		return schema.SingleNestedAttribute{
+			Computed:            true,
+			MarkdownDescription: "Subjects the user may " + action + " to",
+			Attributes: map[string]schema.Attribute{
+				"allow": schema.ListAttribute{
+					Computed:            true,
+					ElementType:         types.StringType,
+					MarkdownDescription: "Subjects allowed, all subjects are allowed if empty",
+				},
+				"deny": schema.ListAttribute{
+					Computed:            true,
+					ElementType:         types.StringType,
+					MarkdownDescription: "Subjects denied, takes precedence over `allow`",
+				},
+			},
+		}
+	}
+
+	return map[string]schema.Attribute{
+		"publish":   permission("publish"),
+		"subscribe": permission("subscribe"),
+	}
+}
+
+func (d *DecodeJWT) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "The claims of an existing JWT, e.g. issued by `nsc` or another Terraform state, as " +
+			"attributes. The signature of the JWT is verified.",
+
+		Attributes: map[string]schema.Attribute{
+			"jwt": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The encoded JWT",
+				Sensitive:           true,
+			},
+			"type": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Type of the JWT, e.g. operator|account|user|activation|generic",
+			},
+			"name": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Name of the JWT, if set",
+			},
+			"subject": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Subject of the JWT, the public key of the operator, account or user",
+			},
+			"issuer": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key the JWT was signed with",
+			},
+			"issuer_account": schema.StringAttribute{
+				Computed: true,
+				MarkdownDescription: "Account of user and activation JWTs signed with an account signing key. Not set if " +
+					"signed by the account itself",
+			},
+			"issued_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Time the JWT was issued at, in RFC 3339 format",
+			},
+			"expires_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Time the JWT expires at, in RFC 3339 format. Not set if it does not expire",
+			},
+			"not_before": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Time the JWT is valid from, in RFC 3339 format. Not set if it is valid from issue",
+			},
+			"tags": schema.ListAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Tags of the JWT",
+			},
+			"permissions": schema.SingleNestedAttribute{
+				Computed: true,
+				MarkdownDescription: "Permissions of a user JWT, or the default permissions of an account JWT. Not set " +
+					"for other JWTs or if empty",
+				Attributes: decodedPermissionsAttributes(),
+			},
+			"limits": schema.SingleNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "Limits of a user or account JWT, -1 is unlimited. Not set for other JWTs",
+				Attributes: map[string]schema.Attribute{
+					"subscriptions": schema.Int64Attribute{
+						Computed:            true,
+						MarkdownDescription: "Maximum number of subscriptions",
+					},
+					"data": schema.Int64Attribute{
+						Computed:            true,
+						MarkdownDescription: "Maximum number of bytes",
+					},
+					"payload": schema.Int64Attribute{
+						Computed:            true,
+						MarkdownDescription: "Maximum message payload in bytes",
+					},
+					"connections": schema.Int64Attribute{
+						Computed:            true,
+						MarkdownDescription: "Maximum number of connections of an account. Not set for user JWTs",
+					},
+				},
+			},
+			"claims": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "All claims of the JWT as JSON, to be read with `jsondecode`",
+			},
+		},
+	}
+}
+
+func (d *DecodeJWT) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var data DecodeJWTModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	claims, err := jwt.Decode(data.JWT.ValueString())
+	if err != nil {
+		addError(&resp.Diagnostics, "Invalid JWT", errorAt(path.Root("jwt"), err))
+		return
+	}
+
+	var payload struct {
+		Nats struct {
+			Tags []string `json:"tags"`
+		} `json:"nats"`
+	}
+	var raw json.RawMessage
+	if err := jwtClaims(data.JWT.ValueString(), &raw); err == nil {
+		err = json.Unmarshal(raw, &payload)
+	}
+	if err != nil {
+		addError(&resp.Diagnostics, "Invalid JWT", errorAt(path.Root("jwt"), err))
+		return
+	}
+
+	c := claims.Claims()
+	data.ClaimType = types.StringValue(string(claims.ClaimType()))
+	data.Name = optionalString(c.Name)
+	data.Subject = types.StringValue(c.Subject)
+	data.Issuer = types.StringValue(c.Issuer)
+	data.IssuerAccount = types.StringNull()
+	data.IssuedAt = unixTime(c.IssuedAt)
+	data.ExpiresAt = unixTime(c.Expires)
+	data.NotBefore = unixTime(c.NotBefore)
+	data.Tags = payload.Nats.Tags
+	if data.Tags == nil {
+		data.Tags = []string{}
+	}
+	data.Claims = types.StringValue(string(raw))
+
+	switch c := claims.(type) {
+	case *jwt.UserClaims:
+		data.IssuerAccount = optionalString(c.IssuerAccount)
+		data.Permissions = decodedPermissions(c.Permissions)
+		data.Limits = &JWTLimitsModel{
+			Subscriptions: types.Int64Value(c.Limits.Subs),
+			Data:          types.Int64Value(c.Limits.Data),
+			Payload:       types.Int64Value(c.Limits.Payload),
+			Connections:   types.Int64Null(),
+		}
+	case *jwt.AccountClaims:
+		data.Permissions = decodedPermissions(c.DefaultPermissions)
+		data.Limits = &JWTLimitsModel{
+			Subscriptions: types.Int64Value(c.Limits.Subs),
+			Data:          types.Int64Value(c.Limits.Data),
+			Payload:       types.Int64Value(c.Limits.Payload),
+			Connections:   types.Int64Value(c.Limits.Conn),
+		}
+	case *jwt.ActivationClaims:
+		data.IssuerAccount = optionalString(c.IssuerAccount)
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// decodedPermissions returns perms as a PermissionsModel, or nil if they are
+// empty.
+func decodedPermissions(perms jwt.Permissions) *PermissionsModel {
+	permission := func(p jwt.Permission) *PermissionModel {
+		if len(p.Allow) == 0 && len(p.Deny) == 0 {
+			return nil
+		}
+		return &PermissionModel{Allow: p.Allow, Deny: p.Deny}
+	}
+
+	p := &PermissionsModel{
+		Publish:   permission(perms.Pub),
+		Subscribe: permission(perms.Sub),
+	}
+	if p.empty() {
+		return nil
+	}
+	return p
+}
+
+// optionalString returns s, or null if it is empty.
+func optionalString(s string) types.String {
+	if s == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(s)
+}
+
+// unixTime returns the Unix time t in RFC 3339 format, or null if it is 0.
+func unixTime(t int64) types.String {
+	if t == 0 {
+		return types.StringNull()
+	}
+	return types.StringValue(time.Unix(t, 0).UTC().Format(time.RFC3339))
+}
@@ -0,0 +1,46 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/nats-io/nkeys"
+	"golang.org/x/crypto/hkdf"
+)
+
+// minMasterSeedLength is the minimum length of the provider master seed in
+// bytes, the size of the derived ed25519 and X25519 seeds.
+const minMasterSeedLength = 32
+
+// derivationSalt separates the keys derived by this provider from other
+// uses of the same master secret.
+const derivationSalt = "terraform-provider-nkey"
+
+// deriveSeed derives the seed of an nkey of type prefix from the master
+// seed. The raw seed is HKDF-SHA256 with the master seed as input key
+// material, derivationSalt as salt and "<type>/<derivation path>" as info,
+// so the same path yields independent keys for every type.
+func (p *providerData) deriveSeed(prefix nkeys.PrefixByte, derivationPath string) (string, error) {
+	if p == nil || len(p.masterSeed) == 0 {
+		return "", errors.New("derivation_path requires master_seed to be set in the provider configuration")
+	}
+
+	raw := make([]byte, minMasterSeedLength)
+	kdf := hkdf.New(sha256.New, p.masterSeed, []byte(derivationSalt), []byte(keyTypeName(prefix)+"/"+derivationPath))
+	if _, err := io.ReadFull(kdf, raw); err != nil {
+		return "", fmt.Errorf("unable to derive seed: %w", err)
+	}
+	defer clear(raw)
+
+	seed, err := nkeys.EncodeSeed(prefix, raw)
+	if err != nil {
+		return "", err
+	}
+
+	return string(seed), nil
+}
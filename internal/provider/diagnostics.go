@@ -0,0 +1,52 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"errors"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// attributeError associates an error with the attribute that caused it, so
+// the diagnostic is rendered next to the offending configuration.
+type attributeError struct {
+	path path.Path
+	err  error
+}
+
+func (e *attributeError) Error() string {
+	return e.path.String() + ": " + e.err.Error()
+}
+
+func (e *attributeError) Unwrap() error {
+	return e.err
+}
+
+// errorAt wraps err so that it is reported at the attribute p. A nil err
+// stays nil, and an error that already carries a path keeps the innermost one.
+func errorAt(p path.Path, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var ae *attributeError
+	if errors.As(err, &ae) {
+		return err
+	}
+
+	return &attributeError{path: p, err: err}
+}
+
+// addError appends err to diags, attached to its attribute path if known.
+func addError(diags *diag.Diagnostics, summary string, err error) {
+	var ae *attributeError
+	if errors.As(err, &ae) {
+		diags.AddAttributeError(ae.path, summary, ae.err.Error())
+		return
+	}
+
+	diags.AddError(summary, err.Error())
+}
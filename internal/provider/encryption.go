@@ -0,0 +1,57 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// encryptSeed encrypts seed to either an age passphrase or recipient and
+// returns the ASCII armored ciphertext. It can be decrypted with
+// `age --decrypt`.
+func encryptSeed(seed []byte, passphrase, recipient string) (string, error) {
+	var (
+		r   age.Recipient
+		err error
+	)
+
+	switch {
+	case passphrase != "":
+		r, err = age.NewScryptRecipient(passphrase)
+		err = errorAt(path.Root("encryption_passphrase"), err)
+	case recipient != "":
+		r, err = age.ParseX25519Recipient(recipient)
+		err = errorAt(path.Root("encryption_recipient"), err)
+	default:
+		err = errors.New("neither a passphrase nor a recipient is configured")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+
+	aw := armor.NewWriter(&buf)
+	w, err := age.Encrypt(aw, r)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(w, bytes.NewReader(seed)); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	if err := aw.Close(); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,23 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"crypto/rand"
+
+	"github.com/nats-io/nkeys"
+)
+
+// createKeyPair generates a new key pair for prefix. Entropy is read from
+// crypto/rand directly, which is safe for concurrent use, so no random bytes
+// are kept around in buffers after a key was generated. BenchmarkCreateKeyPair
+// shows that deriving the key dominates: a pool of buffered readers saves
+// about a tenth of the time per key at any parallelism, which is not worth
+// keeping future seeds in memory.
+func createKeyPair(prefix nkeys.PrefixByte) (nkeys.KeyPair, error) {
+	if prefix == nkeys.PrefixByteCurve {
+		return nkeys.CreateCurveKeysWithRand(rand.Reader)
+	}
+	return nkeys.CreatePairWithRand(prefix, rand.Reader)
+}
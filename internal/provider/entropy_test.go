@@ -0,0 +1,45 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bufio"
+	"crypto/rand"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/nats-io/nkeys"
+)
+
+// BenchmarkCreateKeyPair compares createKeyPair with key generation from a
+// pool of buffered crypto/rand readers, run with as many goroutines as
+// Terraform applies resources in parallel:
+//
+//	go test -run - -bench CreateKeyPair -cpu 1,4,50 ./internal/provider
+func BenchmarkCreateKeyPair(b *testing.B) {
+	b.Run("crypto/rand", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if _, err := createKeyPair(nkeys.PrefixByteUser); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	})
+
+	b.Run("pooled", func(b *testing.B) {
+		pool := sync.Pool{New: func() any { return bufio.NewReaderSize(rand.Reader, 4096) }}
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				r := pool.Get().(io.Reader)
+				_, err := nkeys.CreatePairWithRand(nkeys.PrefixByteUser, r)
+				pool.Put(r)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	})
+}
@@ -0,0 +1,22 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import "fmt"
+
+// fipsCapable reports whether the binary was built against the FIPS 140
+// validated BoringCrypto module (GOEXPERIMENT=boringcrypto). In that build
+// crypto/rand, and with it the entropy of all key generation, SHA-256, HMAC
+// and TLS are served by the validated module. ed25519, HKDF and the Shamir
+// split are not part of the module and remain Go implementations.
+var fipsCapable = boringEnabled()
+
+// checkFIPS returns an error if feature is used while FIPS mode is enabled.
+// It refuses features without any FIPS approved counterpart.
+func (p *providerData) checkFIPS(feature string) error {
+	if p == nil || !p.fipsMode {
+		return nil
+	}
+	return fmt.Errorf("%s is not FIPS 140 compliant and is refused while the provider runs in FIPS mode", feature)
+}
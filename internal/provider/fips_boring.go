@@ -0,0 +1,17 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build boringcrypto
+
+package provider
+
+import (
+	"crypto/boring"
+
+	// Restrict TLS to FIPS approved settings.
+	_ "crypto/tls/fipsonly"
+)
+
+func boringEnabled() bool {
+	return boring.Enabled()
+}
@@ -0,0 +1,10 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !boringcrypto
+
+package provider
+
+func boringEnabled() bool {
+	return false
+}
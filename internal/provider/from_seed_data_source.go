@@ -0,0 +1,112 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &FromSeed{}
+
+func NewFromSeed() datasource.DataSource {
+	return &FromSeed{}
+}
+
+// FromSeed defines the data source implementation.
+type FromSeed struct {
+}
+
+// FromSeedModel describes the data source data model.
+type FromSeedModel struct {
+	Seed       types.String `tfsdk:"seed"`
+	KeyType    types.String `tfsdk:"type"`
+	PublicKey  types.String `tfsdk:"public_key"`
+	PrivateKey types.String `tfsdk:"private_key"`
+}
+
+func (d *FromSeed) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_from_seed"
+}
+
+func (d *FromSeed) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "The key pair of an existing seed, e.g. one kept in Vault or SOPS, without managing it " +
+			"as an `nkey_nkey` resource.",
+
+		Attributes: map[string]schema.Attribute{
+			"seed": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Seed of the nkey (`S...`), or the content of a `.nk` file as written by `nsc`",
+				Sensitive:           true,
+			},
+			"type": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Type of the nkey, one of user|account|server|cluster|operator|curve",
+			},
+			"public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the nkey",
+			},
+			"private_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Raw private key of the nkey (`P...`)",
+				Sensitive:           true,
+			},
+		},
+	}
+}
+
+func (d *FromSeed) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var data FromSeedModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Decorated .nk files are accepted as well.
+	keys, err := nkeys.ParseDecoratedNKey([]byte(data.Seed.ValueString()))
+	if err != nil {
+		keys, err = nkeys.FromSeed([]byte(strings.TrimSpace(data.Seed.ValueString())))
+	}
+	if err != nil {
+		addError(&resp.Diagnostics, "Invalid seed", errorAt(path.Root("seed"), err))
+		return
+	}
+	defer keys.Wipe()
+
+	pubKey, err := keys.PublicKey()
+	if err != nil {
+		addError(&resp.Diagnostics, "Invalid seed", errorAt(path.Root("seed"), err))
+		return
+	}
+	privKey, err := keys.PrivateKey()
+	if err != nil {
+		addError(&resp.Diagnostics, "Invalid seed", errorAt(path.Root("seed"), err))
+		return
+	}
+	defer clear(privKey)
+
+	data.KeyType = types.StringValue(keyTypeName(nkeys.Prefix(pubKey)))
+	data.PublicKey = types.StringValue(pubKey)
+	data.PrivateKey = types.StringValue(string(privKey))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,268 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &GenericJWT{}
+var _ resource.ResourceWithModifyPlan = &GenericJWT{}
+
+func NewGenericJWT() resource.Resource {
+	return &GenericJWT{}
+}
+
+// GenericJWT defines the resource implementation.
+type GenericJWT struct {
+	provider *providerData
+}
+
+// GenericJWTModel describes the resource data model.
+type GenericJWTModel struct {
+	Subject         types.String `tfsdk:"subject"`
+	Claims          types.String `tfsdk:"claims"`
+	IssuerSeed      types.String `tfsdk:"issuer_seed"`
+	ExpiresAt       types.String `tfsdk:"expires_at"`
+	NotBefore       types.String `tfsdk:"not_before"`
+	RenewBefore     types.String `tfsdk:"renew_before"`
+	IssuedAt        types.String `tfsdk:"issued_at"`
+	DeterministicID types.Bool   `tfsdk:"deterministic_id"`
+	Issuer          types.String `tfsdk:"issuer"`
+	JWT             types.String `tfsdk:"jwt"`
+}
+
+func (r *GenericJWT) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_generic_jwt"
+}
+
+func (r *GenericJWT) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "A NATS-style JWT carrying arbitrary claims, signed by any nkey able to sign. The JWT is " +
+			"issued again whenever its claims change.",
+
+		Attributes: map[string]schema.Attribute{
+			"subject": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Subject (`sub`) of the JWT",
+			},
+			"claims": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "JSON object of custom claims, stored in the `nats` claim of the JWT, e.g. `jsonencode({ role = \"billing\" })`",
+			},
+			"issuer_seed": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Seed of the nkey the JWT is signed with. Curve keys cannot sign",
+				Sensitive:           true,
+			},
+			"expires_at":       expiresAtAttribute(),
+			"not_before":       notBeforeAttribute(),
+			"renew_before":     renewBeforeAttribute(),
+			"issued_at":        issuedAtAttribute(),
+			"deterministic_id": deterministicIDAttribute(),
+			"issuer": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the nkey the JWT was signed with",
+			},
+			"jwt": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The encoded JWT",
+				Sensitive:           true,
+			},
+		},
+	}
+}
+
+func (r *GenericJWT) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	// Nothing to compare against on create or destroy, or before all
+	// attributes are known
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() || !req.Config.Raw.IsFullyKnown() {
+		return
+	}
+
+	var plan, state GenericJWTModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The JWT is only signed again if its claims change. Errors are reported
+	// on apply.
+	var diags diag.Diagnostics
+	r.issue(&plan, &diags)
+	if !diags.HasError() && sameClaims(plan.JWT, state.JWT,
+		!plan.ExpiresAt.Equal(state.ExpiresAt) || !plan.NotBefore.Equal(state.NotBefore),
+		!plan.IssuedAt.Equal(state.IssuedAt) || !plan.DeterministicID.Equal(state.DeterministicID)) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("issuer"), state.Issuer)...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("jwt"), state.JWT)...)
+	}
+}
+
+func (r *GenericJWT) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.provider = data
+}
+
+func (r *GenericJWT) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var data GenericJWTModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.issue(&data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Trace(ctx, "created generic JWT resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.provider.register(ctx, "nkey_generic_jwt", data.JWT, &resp.Diagnostics)
+}
+
+func (r *GenericJWT) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data GenericJWTModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A JWT close to expiry is issued again on the next apply.
+	if renewalDue(data.JWT, data.RenewBefore) {
+		tflog.Info(ctx, "JWT is due for renewal, removing it from state", map[string]interface{}{
+			"renew_before": data.RenewBefore.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *GenericJWT) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var plan GenericJWTModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The JWT in state is kept if its claims did not change.
+	reissued := plan.JWT.IsUnknown()
+	if reissued {
+		r.issue(&plan, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if reissued {
+		r.provider.notify(ctx, jwtEvent("reissued", "nkey_generic_jwt", plan.JWT), &resp.Diagnostics)
+		r.provider.register(ctx, "nkey_generic_jwt", plan.JWT, &resp.Diagnostics)
+	}
+}
+
+func (r *GenericJWT) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Nothing to do here as the JWT only exists in state
+}
+
+// issue encodes the claims of data and signs them with the issuer seed.
+func (r *GenericJWT) issue(data *GenericJWTModel, diags *diag.Diagnostics) {
+	keys, err := jwtSigner(data.IssuerSeed.ValueString(), path.Root("issuer_seed"),
+		nkeys.PrefixByteOperator, nkeys.PrefixByteAccount, nkeys.PrefixByteUser, nkeys.PrefixByteServer, nkeys.PrefixByteCluster)
+	if err != nil {
+		addError(diags, "Invalid issuer seed", err)
+		return
+	}
+	defer keys.Wipe()
+
+	issuer, err := keys.PublicKey()
+	if err != nil {
+		addError(diags, "Invalid issuer seed", errorAt(path.Root("issuer_seed"), err))
+		return
+	}
+
+	claims := jwt.NewGenericClaims(data.Subject.ValueString())
+	if claims == nil {
+		addError(diags, "Invalid subject", errorAt(path.Root("subject"), errors.New("the subject must not be empty")))
+		return
+	}
+	if !data.Claims.IsNull() {
+		if err := json.Unmarshal([]byte(data.Claims.ValueString()), &claims.Data); err != nil {
+			addError(diags, "Invalid claims", errorAt(path.Root("claims"), fmt.Errorf("the claims must be a JSON object: %w", err)))
+		}
+	}
+
+	setValidity(&claims.ClaimsData, data.IssuedAt, data.ExpiresAt, data.NotBefore, data.RenewBefore, diags)
+
+	if diags.HasError() {
+		return
+	}
+
+	token, err := encodeJWT(claims, keys, data.DeterministicID.ValueBool(), diags)
+	if err != nil {
+		addError(diags, "Unable to issue JWT", err)
+		return
+	}
+
+	data.Issuer = types.StringValue(issuer)
+	data.JWT = types.StringValue(token)
+}
@@ -0,0 +1,87 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestGenericJWT(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	server, _ := nkeys.CreateServer()
+	serverKey, _ := server.PublicKey()
+	seed, _ := server.Seed()
+
+	config := func(claims string) map[string]tftypes.Value {
+		return map[string]tftypes.Value{
+			"subject":     stringValue("app-1"),
+			"claims":      stringValue(claims),
+			"issuer_seed": stringValue(string(seed)),
+			"expires_at":  stringValue("1h"),
+		}
+	}
+
+	state := p.create("nkey_generic_jwt", config(`{"role":"billing","replicas":2}`))
+
+	token := stringAttribute(t, state, "jwt")
+	verifySignature(t, token, serverKey)
+
+	claims, err := jwt.DecodeGeneric(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Issuer != serverKey || claims.Subject != "app-1" {
+		t.Errorf("JWT of %s issued by %s, want app-1 issued by %s", claims.Subject, claims.Issuer, serverKey)
+	}
+	if got := stringAttribute(t, state, "issuer"); got != serverKey {
+		t.Errorf("issuer = %s, want %s", got, serverKey)
+	}
+	if claims.Data["role"] != "billing" || claims.Data["replicas"] != float64(2) {
+		t.Errorf("custom claims = %v", claims.Data)
+	}
+	if got := claims.Expires - claims.IssuedAt; got != 60*60 {
+		t.Errorf("JWT expires %ds after issuance, want 1h", got)
+	}
+
+	for name, test := range map[string]struct {
+		claims   string
+		reissued bool
+	}{
+		"same claims":    {`{"replicas":2,"role":"billing"}`, false},
+		"changed claims": {`{"role":"billing","replicas":3}`, true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp := p.plan("nkey_generic_jwt", state, config(test.claims))
+			checkDiagnostics(t, resp.Diagnostics)
+			planned := p.value(p.resourceSchema("nkey_generic_jwt"), resp.PlannedState)
+			if known := attribute(t, planned, "jwt").IsKnown(); known == test.reissued {
+				t.Errorf("jwt is known = %t, want %t", known, !test.reissued)
+			}
+		})
+	}
+
+	curve, _ := nkeys.CreateCurveKeys()
+	curveSeed, _ := curve.Seed()
+
+	for name, test := range map[string]struct {
+		config map[string]tftypes.Value
+		err    string
+	}{
+		"curve seed":   {map[string]tftypes.Value{"subject": stringValue("app-1"), "issuer_seed": stringValue(string(curveSeed))}, "Invalid issuer seed"},
+		"claims array": {map[string]tftypes.Value{"subject": stringValue("app-1"), "issuer_seed": stringValue(string(seed)), "claims": stringValue("[1]")}, "Invalid claims"},
+		"no subject":   {map[string]tftypes.Value{"subject": stringValue(""), "issuer_seed": stringValue(string(seed))}, "Invalid subject"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, diags := p.tryApply("nkey_generic_jwt", tftypes.Value{}, test.config); !hasError(diags, test.err) {
+				t.Errorf("expected %q, got %v", test.err, diags)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// httpTimeout bounds every HTTP request made by the provider that is not
+// bounded by a timeouts block.
+const httpTimeout = 10 * time.Second
+
+// httpClient returns the client used for all HTTP requests. Proxies are
+// taken from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables
+// unless the provider configures an explicit proxy_url.
+func (p *providerData) httpClient() *http.Client {
+	var proxy *url.URL
+	if p != nil {
+		proxy = p.proxyURL
+	}
+
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return &http.Client{}
+	}
+	transport = transport.Clone()
+	if proxy != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	return &http.Client{Transport: transport}
+}
+
+// getJSON fetches url with client and decodes the JSON response body into v.
+func getJSON(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	ctx, cancel := withDefaultTimeout(ctx, httpTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %s", url, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
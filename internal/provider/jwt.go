@@ -0,0 +1,421 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/jwt/v2"
+	jwtv1 "github.com/nats-io/jwt/v2/v1compat"
+	"github.com/nats-io/nkeys"
+)
+
+// jwtSigner returns the key pair of the seed at p, which must be of one of
+// the given types. The caller must wipe the key pair.
+func jwtSigner(seed string, p path.Path, prefixes ...nkeys.PrefixByte) (nkeys.KeyPair, error) {
+	keys, err := nkeys.FromSeed([]byte(strings.TrimSpace(seed)))
+	if err != nil {
+		return nil, errorAt(p, err)
+	}
+
+	pubKey, err := keys.PublicKey()
+	if err != nil {
+		keys.Wipe()
+		return nil, errorAt(p, err)
+	}
+
+	names := make([]string, len(prefixes))
+	for i, prefix := range prefixes {
+		if nkeys.Prefix(pubKey) == prefix {
+			return keys, nil
+		}
+		names[i] = keyTypeName(prefix)
+	}
+	keys.Wipe()
+
+	return nil, errorAt(p, fmt.Errorf("the seed is of type %s, expected %s", keyTypeName(nkeys.Prefix(pubKey)), strings.Join(names, " or ")))
+}
+
+// encodeJWT validates claims and signs them with keys. Validation warnings
+// are added to diags. If setValidity pinned the iat claim or deterministicID
+// is set, the JWT is encoded reproducibly, see reproducibleJWT.
+func encodeJWT(claims jwt.Claims, keys nkeys.KeyPair, deterministicID bool, diags *diag.Diagnostics) (string, error) {
+	vr := jwt.CreateValidationResults()
+	claims.Validate(vr)
+
+	for _, warning := range vr.Warnings() {
+		diags.AddWarning("JWT validation warning", warning)
+	}
+	if errs := vr.Errors(); len(errs) > 0 {
+		return "", errors.Join(errs...)
+	}
+
+	// Encode sets iat to the current time.
+	issuedAt := claims.Claims().IssuedAt
+	token, err := claims.Encode(keys)
+	if err != nil || (issuedAt == 0 && !deterministicID) {
+		return token, err
+	}
+
+	return reproducibleJWT(token, issuedAt, deterministicID, keys)
+}
+
+// reproducibleJWT returns token signed again with keys, with the iat claim
+// set to issuedAt unless it is 0 and the jti claim set to the hash of the
+// other claims, excluding iat if deterministicID is set. The JWTs of the
+// same claims issued at the same time are byte-identical, as ed25519
+// signatures are deterministic.
+func reproducibleJWT(token string, issuedAt int64, deterministicID bool, keys nkeys.KeyPair) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errors.New("malformed JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", err
+	}
+
+	// Numbers are kept as they are, they may exceed the precision of a
+	// float64.
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	var claims map[string]interface{}
+	if err := dec.Decode(&claims); err != nil {
+		return "", err
+	}
+
+	if issuedAt != 0 {
+		claims["iat"] = issuedAt
+	}
+	delete(claims, "jti")
+
+	hashed := claims
+	if deterministicID {
+		hashed = make(map[string]interface{}, len(claims))
+		for k, v := range claims {
+			hashed[k] = v
+		}
+		delete(hashed, "iat")
+	}
+	// Maps are encoded with sorted keys.
+	data, err := json.Marshal(hashed)
+	if err != nil {
+		return "", err
+	}
+	hash := sha512.Sum512_256(data)
+	claims["jti"] = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(hash[:])
+
+	if payload, err = json.Marshal(claims); err != nil {
+		return "", err
+	}
+
+	signed := parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload)
+	signingInput := signed
+	// The jwt v1 layout only signs the payload.
+	var header struct {
+		Algorithm string `json:"alg"`
+	}
+	if h, err := base64.RawURLEncoding.DecodeString(parts[0]); err == nil && json.Unmarshal(h, &header) == nil &&
+		header.Algorithm == jwtv1.AlgorithmNkey {
+		signingInput = base64.RawURLEncoding.EncodeToString(payload)
+	}
+	sig, err := keys.Sign([]byte(signingInput))
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// jwtTime returns the unix time of the RFC 3339 timestamp at p, or 0 if it
+// is null.
+func jwtTime(v types.String, p path.Path) (int64, error) {
+	if v.IsNull() {
+		return 0, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v.ValueString())
+	if err != nil {
+		return 0, errorAt(p, err)
+	}
+
+	return t.Unix(), nil
+}
+
+// validityTime returns the unix time of the RFC 3339 timestamp or duration
+// from now at p, or 0 if it is null.
+func validityTime(v types.String, p path.Path, now time.Time) (int64, error) {
+	if d, err := time.ParseDuration(v.ValueString()); err == nil {
+		return now.Add(d).Unix(), nil
+	}
+
+	t, err := jwtTime(v, p)
+	if err != nil {
+		return 0, errorAt(p, errors.New("expected an RFC 3339 timestamp or a duration such as \"720h\""))
+	}
+	return t, nil
+}
+
+// issuerAccount returns the account of a user or activation JWT signed by
+// issuer, the public key of account if it is set, or "" if the JWT is
+// signed by the account itself.
+func issuerAccount(account types.String, issuer string, diags *diag.Diagnostics) string {
+	// The issuer account is only set for JWTs signed by a signing key.
+	if account.IsNull() || account.ValueString() == issuer {
+		return ""
+	}
+	if err := checkPublicKey(account.ValueString(), nkeys.PrefixByteAccount); err != nil {
+		addError(diags, "Invalid issuer account", errorAt(path.Root("issuer_account"), err))
+		return ""
+	}
+	return account.ValueString()
+}
+
+// issuerAccountAttribute returns the issuer_account attribute of the user
+// and activation JWT resources, whose JWTs belong to the given party.
+func issuerAccountAttribute(party string) schema.StringAttribute {
+	return schema.StringAttribute{
+		Optional: true,
+		MarkdownDescription: "Public key of the account " + party + ". Required if `issuer_seed` is an account " +
+			"signing key, so the account identity seed can be kept offline",
+	}
+}
+
+// setValidity sets the expiry and start of validity of claims from the
+// expires_at and not_before attributes, relative to the issued_at attribute
+// if it pins the iat claim, and checks the renew_before window.
+func setValidity(claims *jwt.ClaimsData, issuedAt, expiresAt, notBefore, renewBefore types.String, diags *diag.Diagnostics) {
+	now := time.Now()
+
+	if !issuedAt.IsNull() {
+		at, err := issuanceTime(issuedAt, path.Root("issued_at"), now)
+		if err != nil {
+			addError(diags, "Invalid time of issuance", err)
+		}
+		claims.IssuedAt = at.Unix()
+		now = at
+	}
+
+	expires, err := validityTime(expiresAt, path.Root("expires_at"), now)
+	if err != nil {
+		addError(diags, "Invalid expiry", err)
+	}
+	claims.Expires = expires
+
+	start, err := validityTime(notBefore, path.Root("not_before"), now)
+	if err != nil {
+		addError(diags, "Invalid start of validity", err)
+	}
+	claims.NotBefore = start
+
+	if !renewBefore.IsNull() && !renewBefore.IsUnknown() {
+		if _, err := time.ParseDuration(renewBefore.ValueString()); err != nil {
+			addError(diags, "Invalid renewal window", errorAt(path.Root("renew_before"), err))
+		}
+	}
+}
+
+// issuanceTime returns the RFC 3339 timestamp at p, or now rounded down to
+// a multiple of the duration at p.
+func issuanceTime(v types.String, p path.Path, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(v.ValueString()); err == nil {
+		if d <= 0 {
+			return now, errorAt(p, errors.New("the duration must be positive"))
+		}
+		return now.Truncate(d), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v.ValueString())
+	if err != nil {
+		return now, errorAt(p, errors.New("expected an RFC 3339 timestamp or a duration such as \"24h\""))
+	}
+	return t, nil
+}
+
+// renewalDue reports whether token expires within the renew_before window.
+func renewalDue(token, renewBefore types.String) bool {
+	window, err := time.ParseDuration(renewBefore.ValueString())
+	if err != nil || token.IsNull() {
+		return false
+	}
+
+	var claims jwt.ClaimsData
+	if err := jwtClaims(token.ValueString(), &claims); err != nil || claims.Expires == 0 {
+		return false
+	}
+
+	return time.Until(time.Unix(claims.Expires, 0)) <= window
+}
+
+// claimsKnown reports whether all attributes of config are known, apart from
+// those in ignored, which do not end up in the claims of the JWT.
+func claimsKnown(config tftypes.Value, ignored ...string) bool {
+	var attrs map[string]tftypes.Value
+	if err := config.As(&attrs); err != nil {
+		return false
+	}
+	for name, v := range attrs {
+		if !v.IsFullyKnown() && !slices.Contains(ignored, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameClaims reports whether the JWTs issued and state carry the same
+// claims. Unless issuanceChanged, the time of issuance and the ID are not
+// compared, as they change whenever a JWT is signed again. Unless
+// validityChanged, the validity times are not compared either, as relative
+// ones such as `720h` move with the issuance. Tags of the provenance keys are
+// never compared, as they describe the run that issued the JWT.
+func sameClaims(issued, state types.String, validityChanged, issuanceChanged bool, provenance ...string) bool {
+	var a, b map[string]interface{}
+	if jwtClaims(issued.ValueString(), &a) != nil || jwtClaims(state.ValueString(), &b) != nil {
+		return false
+	}
+
+	var ignored []string
+	if !issuanceChanged {
+		ignored = append(ignored, "iat", "jti")
+	}
+	if !validityChanged {
+		ignored = append(ignored, "exp", "nbf")
+	}
+	for _, claim := range ignored {
+		delete(a, claim)
+		delete(b, claim)
+	}
+	withoutTags(a, provenance)
+	withoutTags(b, provenance)
+
+	return reflect.DeepEqual(a, b)
+}
+
+// withoutTags removes the tags of the keys from the decoded claims.
+func withoutTags(claims map[string]interface{}, keys []string) {
+	nats, _ := claims["nats"].(map[string]interface{})
+	tags, _ := nats["tags"].([]interface{})
+	if len(keys) == 0 || len(tags) == 0 {
+		return
+	}
+
+	var kept []interface{}
+	for _, tag := range tags {
+		key, _, _ := strings.Cut(fmt.Sprint(tag), ":")
+		if !slices.Contains(keys, key) {
+			kept = append(kept, tag)
+		}
+	}
+	if len(kept) == 0 {
+		delete(nats, "tags")
+		return
+	}
+	nats["tags"] = kept
+}
+
+// expiresAtAttribute returns the expires_at attribute of the JWT resources.
+func expiresAtAttribute() schema.StringAttribute {
+	return schema.StringAttribute{
+		Optional: true,
+		MarkdownDescription: "RFC 3339 timestamp or duration from issuance, e.g. `720h`, at which the JWT expires. " +
+			"Never expires if not set",
+	}
+}
+
+// issuedAtAttribute returns the issued_at attribute of the JWT resources.
+func issuedAtAttribute() schema.StringAttribute {
+	return schema.StringAttribute{
+		Optional: true,
+		MarkdownDescription: "RFC 3339 timestamp to pin the `iat` claim to, e.g. the time of the build, or a duration " +
+			"such as `24h` to round it down to. Relative `expires_at` and `not_before` are counted from it. With a " +
+			"pinned `iat` the JWT of the same claims is byte-identical whenever it is issued. Defaults to the current time",
+	}
+}
+
+// deterministicIDAttribute returns the deterministic_id attribute of the
+// JWT resources.
+func deterministicIDAttribute() schema.BoolAttribute {
+	return schema.BoolAttribute{
+		Optional: true,
+		MarkdownDescription: "Derive the `jti` claim from the hash of the other claims except `iat`, so the ID " +
+			"only changes with the claims. By default it is derived from the claims including `iat`",
+	}
+}
+
+// notBeforeAttribute returns the not_before attribute of the JWT resources.
+func notBeforeAttribute() schema.StringAttribute {
+	return schema.StringAttribute{
+		Optional:            true,
+		MarkdownDescription: "RFC 3339 timestamp or duration from issuance before which the JWT is not valid",
+	}
+}
+
+// renewBeforeAttribute returns the renew_before attribute of the JWT
+// resources.
+func renewBeforeAttribute() schema.StringAttribute {
+	return schema.StringAttribute{
+		Optional: true,
+		MarkdownDescription: "Duration before expiry, e.g. `168h`, from which on the JWT is issued again on the next " +
+			"apply. The JWT is removed from state on refresh once the window is reached",
+		Validators: []validator.String{
+			stringvalidator.AlsoRequires(path.MatchRoot("expires_at")),
+		},
+	}
+}
+
+// publicKeys returns the elements of the set at p, which must all be public
+// keys of type prefix.
+func publicKeys(ctx context.Context, set types.Set, p path.Path, prefix nkeys.PrefixByte, diags *diag.Diagnostics) []string {
+	var keys []string
+	diags.Append(set.ElementsAs(ctx, &keys, false)...)
+
+	for _, key := range keys {
+		if err := checkPublicKey(key, prefix); err != nil {
+			addError(diags, "Invalid public key", errorAt(p.AtSetValue(types.StringValue(key)), err))
+		}
+	}
+
+	return keys
+}
+
+// tagList returns the elements of the tags set.
+func tagList(ctx context.Context, set types.Set, diags *diag.Diagnostics) []string {
+	var tags []string
+	diags.Append(set.ElementsAs(ctx, &tags, false)...)
+	return tags
+}
+
+// checkPublicKey returns an error if key is not a public key of type prefix.
+func checkPublicKey(key string, prefix nkeys.PrefixByte) error {
+	if nkeys.Prefix(key) != prefix || !nkeys.IsValidPublicKey(key) {
+		return fmt.Errorf("%q is not %s public key", key, withArticle(keyTypeName(prefix)))
+	}
+	return nil
+}
+
+// withArticle prefixes the key type name s with its indefinite article.
+func withArticle(s string) string {
+	if strings.ContainsAny(s[:1], "aeio") {
+		return "an " + s
+	}
+	return "a " + s
+}
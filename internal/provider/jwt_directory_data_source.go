@@ -0,0 +1,110 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &JWTDirectory{}
+
+func NewJWTDirectory() datasource.DataSource {
+	return &JWTDirectory{}
+}
+
+// JWTDirectory defines the data source implementation.
+type JWTDirectory struct {
+}
+
+// JWTDirectoryModel describes the data source data model.
+type JWTDirectoryModel struct {
+	AccountJWTs []string          `tfsdk:"account_jwts"`
+	Layout      types.String      `tfsdk:"layout"`
+	Prefix      types.String      `tfsdk:"prefix"`
+	Files       map[string]string `tfsdk:"files"`
+}
+
+func (d *JWTDirectory) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_jwt_directory"
+}
+
+func (d *JWTDirectory) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Lays out account JWTs as the files of a nats-server `full` resolver directory, to be " +
+			"uploaded with the object resources of a cloud provider, e.g. `aws_s3_object` with `for_each`.",
+
+		Attributes: map[string]schema.Attribute{
+			"account_jwts": schema.ListAttribute{
+				Required:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Account JWTs to include",
+			},
+			"layout": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "Must be one of flat|sharded. `flat` writes `<account>.jwt`, `sharded` writes " +
+					"`<last two characters of account>/<account>.jwt` like a sharded directory resolver. Defaults to flat",
+			},
+			"prefix": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Prefix of all file names, e.g. `jwt/`",
+			},
+			"files": schema.MapAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Map of file names to JWTs",
+			},
+		},
+	}
+}
+
+func (d *JWTDirectory) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data JWTDirectoryModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	layout := strings.ToLower(data.Layout.ValueString())
+	if layout != "" && layout != "flat" && layout != "sharded" {
+		resp.Diagnostics.AddAttributeError(path.Root("layout"), "Invalid layout",
+			fmt.Sprintf("unsupported layout %q, must be one of flat|sharded", data.Layout.ValueString()))
+		return
+	}
+
+	data.Files = map[string]string{}
+
+	for i, token := range data.AccountJWTs {
+		account, err := jwtSubject(token)
+		if err == nil && !nkeys.IsValidPublicAccountKey(account) {
+			err = fmt.Errorf("subject %q is not an account public key", account)
+		}
+		if err != nil {
+			addError(&resp.Diagnostics, "Invalid account JWT", errorAt(path.Root("account_jwts").AtListIndex(i), err))
+			return
+		}
+
+		name := account + ".jwt"
+		if layout == "sharded" {
+			name = account[len(account)-2:] + "/" + name
+		}
+		data.Files[data.Prefix.ValueString()+name] = token
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,95 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestReproducibleJWT(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	operator, _ := nkeys.CreateOperator()
+	seed, _ := operator.Seed()
+
+	config := func(issuedAt string, deterministicID bool) map[string]tftypes.Value {
+		return map[string]tftypes.Value{
+			"seed":             stringValue(string(seed)),
+			"name":             stringValue("example"),
+			"expires_at":       stringValue("720h"),
+			"issued_at":        stringValue(issuedAt),
+			"deterministic_id": boolValue(deterministicID),
+		}
+	}
+	decode := func(t *testing.T, state tftypes.Value) *jwt.OperatorClaims {
+		t.Helper()
+		claims, err := jwt.DecodeOperatorClaims(stringAttribute(t, state, "jwt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return claims
+	}
+
+	const issuedAt = "2026-01-01T00:00:00Z"
+	pinned, _ := time.Parse(time.RFC3339, issuedAt)
+
+	first := p.create("nkey_operator_jwt", config(issuedAt, false))
+	claims := decode(t, first)
+	if claims.IssuedAt != pinned.Unix() {
+		t.Errorf("iat = %d, want %d", claims.IssuedAt, pinned.Unix())
+	}
+	if want := pinned.Add(720 * time.Hour).Unix(); claims.Expires != want {
+		t.Errorf("exp = %d, want %d counted from iat", claims.Expires, want)
+	}
+
+	time.Sleep(time.Second)
+	if second := p.create("nkey_operator_jwt", config(issuedAt, false)); stringAttribute(t, second, "jwt") != stringAttribute(t, first, "jwt") {
+		t.Error("JWTs of the same claims and iat differ")
+	}
+
+	// The JWT is issued again for another time of issuance.
+	resp := p.plan("nkey_operator_jwt", first, config("2026-01-02T00:00:00Z", false))
+	checkDiagnostics(t, resp.Diagnostics)
+	if attribute(t, p.value(p.resourceSchema("nkey_operator_jwt"), resp.PlannedState), "jwt").IsKnown() {
+		t.Error("jwt is kept although issued_at changed")
+	}
+
+	t.Run("deterministic ID", func(t *testing.T) {
+		// Without an expiry relative to iat, only iat differs.
+		issue := func(issuedAt string, deterministicID bool) *jwt.OperatorClaims {
+			c := config(issuedAt, deterministicID)
+			delete(c, "expires_at")
+			return decode(t, p.create("nkey_operator_jwt", c))
+		}
+
+		a := issue("2026-01-01T00:00:00Z", true)
+		b := issue("2026-01-02T00:00:00Z", true)
+		if a.ID != b.ID {
+			t.Errorf("jti = %s and %s, want the same for the same claims", a.ID, b.ID)
+		}
+		if c := issue("2026-01-02T00:00:00Z", false); c.ID == b.ID {
+			t.Error("jti does not depend on iat without deterministic_id")
+		}
+	})
+
+	t.Run("rounded", func(t *testing.T) {
+		claims := decode(t, p.create("nkey_operator_jwt", config("24h", false)))
+		if want := time.Now().Truncate(24 * time.Hour).Unix(); claims.IssuedAt != want {
+			t.Errorf("iat = %d, want %d", claims.IssuedAt, want)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, diags := p.tryApply("nkey_operator_jwt", tftypes.Value{}, config("yesterday", false))
+		if !hasError(diags, "Invalid time of issuance") {
+			t.Errorf("expected an invalid time of issuance error, got %v", diags)
+		}
+	})
+}
@@ -0,0 +1,118 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &KeyFile{}
+
+func NewKeyFile() datasource.DataSource {
+	return &KeyFile{}
+}
+
+// KeyFile defines the data source implementation.
+type KeyFile struct {
+}
+
+// KeyFileModel describes the data source data model.
+type KeyFileModel struct {
+	Seed      types.String `tfsdk:"seed"`
+	PublicKey types.String `tfsdk:"public_key"`
+	Content   types.String `tfsdk:"content"`
+	NscPath   types.String `tfsdk:"nsc_path"`
+}
+
+func (d *KeyFile) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_key_file"
+}
+
+func (d *KeyFile) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Renders the content of a single key `.nk` file as read by `nsc` and the `nats` CLI.",
+
+		Attributes: map[string]schema.Attribute{
+			"seed": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Seed of the nkey",
+				Sensitive:           true,
+			},
+			"public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the nkey",
+			},
+			"content": schema.StringAttribute{
+				Computed: true,
+				MarkdownDescription: "Content of the `.nk` file. Operator, account and user seeds are wrapped in the " +
+					"`-----BEGIN ... NKEY SEED-----` block, other seeds are written as is",
+				Sensitive: true,
+			},
+			"nsc_path": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Path of the file relative to the `keys` directory of an nsc key store",
+			},
+		},
+	}
+}
+
+func (d *KeyFile) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var data KeyFileModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	seed := strings.TrimSpace(data.Seed.ValueString())
+
+	keys, err := nkeys.FromSeed([]byte(seed))
+	if err != nil {
+		addError(&resp.Diagnostics, "Invalid seed", errorAt(path.Root("seed"), err))
+		return
+	}
+	defer keys.Wipe()
+
+	pubKey, err := keys.PublicKey()
+	if err != nil {
+		addError(&resp.Diagnostics, "Invalid seed", errorAt(path.Root("seed"), err))
+		return
+	}
+
+	data.PublicKey = types.StringValue(pubKey)
+	data.Content = types.StringValue(decorateSeed(seed, nkeys.Prefix(pubKey)))
+	data.NscPath = types.StringValue(filepath.ToSlash(filepath.Join(pubKey[0:1], pubKey[1:3], pubKey+".nk")))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// decorateSeed renders seed the way nsc writes key files. Only the seed
+// types recognized by nkeys.ParseDecoratedNKey are wrapped.
+func decorateSeed(seed string, prefix nkeys.PrefixByte) string {
+	switch prefix {
+	case nkeys.PrefixByteOperator, nkeys.PrefixByteAccount, nkeys.PrefixByteUser:
+		kind := strings.ToUpper(keyTypeName(prefix))
+		return fmt.Sprintf("-----BEGIN %s NKEY SEED-----\n%s\n------END %s NKEY SEED------\n", kind, seed, kind)
+	default:
+		return seed + "\n"
+	}
+}
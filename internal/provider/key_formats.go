@@ -0,0 +1,168 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/nats-io/nkeys"
+	"golang.org/x/crypto/ssh"
+)
+
+// okpJWK is an RFC 8037 octet key pair JWK. The key ID is the nkey public
+// key.
+type okpJWK struct {
+	KeyType string `json:"kty"`
+	Curve   string `json:"crv"`
+	KeyID   string `json:"kid"`
+	X       string `json:"x"`
+	D       string `json:"d,omitempty"`
+}
+
+// rawPublicKey returns the ed25519 public key of an nkey, or the X25519
+// public key of a curve key, and its raw bytes.
+func rawPublicKey(pubKey string) (crypto.PublicKey, []byte, error) {
+	prefix := nkeys.Prefix(pubKey)
+
+	raw, err := nkeys.Decode(prefix, []byte(pubKey))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if prefix == nkeys.PrefixByteCurve {
+		pub, err := ecdh.X25519().NewPublicKey(raw)
+		return pub, raw, err
+	}
+	return ed25519.PublicKey(raw), raw, nil
+}
+
+// rawPrivateKey returns the ed25519 private key of an nkey seed, or the
+// X25519 private key of a curve seed, and the raw 32 byte seed.
+func rawPrivateKey(seed string) (crypto.PrivateKey, []byte, error) {
+	prefix, raw, err := nkeys.DecodeSeed([]byte(seed))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if prefix == nkeys.PrefixByteCurve {
+		priv, err := ecdh.X25519().NewPrivateKey(raw)
+		return priv, raw, err
+	}
+	return ed25519.NewKeyFromSeed(raw), raw, nil
+}
+
+// setKeyFormats renders the public key, and the seed if it is stored, in
+// the formats understood by tooling outside of NATS. Curve keys have no
+// OpenSSH format. The OpenSSH private key contains a random check value, so
+// it is only rendered if not already set.
+func (m *NkeyModel) setKeyFormats() error {
+	pub, rawPub, err := rawPublicKey(m.PublicKey.ValueString())
+	if err != nil {
+		return err
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return err
+	}
+	m.PublicKeyPEM = types.StringValue(string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})))
+	m.PublicKeyHex = types.StringValue(hex.EncodeToString(rawPub))
+	m.PublicKeyBase64 = types.StringValue(base64.StdEncoding.EncodeToString(rawPub))
+	m.Fingerprint = types.StringValue(fingerprint(rawPub))
+
+	jwk := okpJWK{KeyType: "OKP", Curve: "Ed25519", KeyID: m.PublicKey.ValueString(), X: base64.RawURLEncoding.EncodeToString(rawPub)}
+	if nkeys.Prefix(m.PublicKey.ValueString()) == nkeys.PrefixByteCurve {
+		jwk.Curve = "X25519"
+	}
+	encoded, err := json.Marshal(jwk)
+	if err != nil {
+		return err
+	}
+	m.PublicKeyJWK = types.StringValue(string(encoded))
+
+	curve := nkeys.Prefix(m.PublicKey.ValueString()) == nkeys.PrefixByteCurve
+
+	m.PublicKeyOpenSSH = types.StringNull()
+	if !curve {
+		sshPub, err := ssh.NewPublicKey(pub)
+		if err != nil {
+			return err
+		}
+		m.PublicKeyOpenSSH = types.StringValue(strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPub))) + " " + m.PublicKey.ValueString())
+	}
+
+	if m.Seed.IsNull() || m.Seed.IsUnknown() {
+		m.PrivateKeyPEM = types.StringNull()
+		m.PrivateKeyJWK = types.StringNull()
+		m.PrivateKeyOpenSSH = types.StringNull()
+		m.PrivateKeyHex = types.StringNull()
+		m.PrivateKeyBase64 = types.StringNull()
+		return nil
+	}
+
+	priv, rawSeed, err := rawPrivateKey(m.Seed.ValueString())
+	if err != nil {
+		return err
+	}
+	defer clear(rawSeed)
+
+	der, err = x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return err
+	}
+	m.PrivateKeyPEM = types.StringValue(string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})))
+
+	rawPriv := rawSeed
+	if key, ok := priv.(ed25519.PrivateKey); ok {
+		rawPriv = key
+		defer clear(key)
+	}
+	m.PrivateKeyHex = types.StringValue(hex.EncodeToString(rawPriv))
+	m.PrivateKeyBase64 = types.StringValue(base64.StdEncoding.EncodeToString(rawPriv))
+
+	jwk.D = base64.RawURLEncoding.EncodeToString(rawSeed)
+	encoded, err = json.Marshal(jwk)
+	if err != nil {
+		return err
+	}
+	m.PrivateKeyJWK = types.StringValue(string(encoded))
+
+	switch {
+	case curve:
+		m.PrivateKeyOpenSSH = types.StringNull()
+	case m.PrivateKeyOpenSSH.IsNull() || m.PrivateKeyOpenSSH.IsUnknown():
+		block, err := ssh.MarshalPrivateKey(priv, m.PublicKey.ValueString())
+		if err != nil {
+			return err
+		}
+		m.PrivateKeyOpenSSH = types.StringValue(string(pem.EncodeToMemory(block)))
+	}
+
+	return nil
+}
+
+// fingerprint returns the colon separated hex encoded SHA-256 hash of a raw
+// public key.
+func fingerprint(rawPub []byte) string {
+	sum := sha256.Sum256(rawPub)
+
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02x", b)
+	}
+
+	return strings.Join(parts, ":")
+}
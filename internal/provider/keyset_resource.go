@@ -0,0 +1,364 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &Keyset{}
+var _ resource.ResourceWithModifyPlan = &Keyset{}
+
+func NewKeyset() resource.Resource {
+	return &Keyset{}
+}
+
+// Keyset defines the resource implementation.
+type Keyset struct {
+	provider *providerData
+}
+
+// KeysetModel describes the resource data model.
+type KeysetModel struct {
+	KeyType     types.String `tfsdk:"type"`
+	Names       types.Set    `tfsdk:"names"`
+	PublicKeys  types.Map    `tfsdk:"public_keys"`
+	PrivateKeys types.Map    `tfsdk:"private_keys"`
+	Seeds       types.Map    `tfsdk:"seeds"`
+}
+
+// keysetKey is the key material of a single entry of a keyset.
+type keysetKey struct {
+	publicKey  string
+	privateKey string
+	seed       string
+}
+
+func (r *Keyset) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_keyset"
+}
+
+func (r *Keyset) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "A set of nkeys of the same type, one per name. Adding a name generates a key pair for it, " +
+			"removing a name drops its key pair, the key pairs of the other names are kept.",
+
+		Attributes: map[string]schema.Attribute{
+			"type": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("account"),
+				MarkdownDescription: "The type of nkeys to generate. Must be one of user|account|server|cluster|operator|curve",
+				PlanModifiers: []planmodifier.String{
+					keyTypeRequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.OneOfCaseInsensitive("user", "account", "server", "cluster", "operator", "curve"),
+				},
+			},
+			"names": schema.SetAttribute{
+				Required:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Names to generate a key pair for",
+				Validators: []validator.Set{
+					setvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
+			"public_keys": schema.MapAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Map of names to the public key of their nkey",
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"private_keys": schema.MapAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Map of names to the raw private key of their nkey (`P...`)",
+				Sensitive:           true,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"seeds": schema.MapAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Map of names to the seed of their nkey (`S...`) to be given to the clients",
+				Sensitive:           true,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *Keyset) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.provider = data
+}
+
+func (r *Keyset) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to do on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan KeysetModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if strings.EqualFold(plan.KeyType.ValueString(), "curve") {
+		if err := r.provider.checkFIPS("X25519 curve key generation"); err != nil {
+			addError(&resp.Diagnostics, "Operation refused in FIPS mode", errorAt(path.Root("type"), err))
+			return
+		}
+	}
+
+	// Nothing to compare against on create
+	if req.State.Raw.IsNull() {
+		return
+	}
+
+	var state KeysetModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !plan.Names.Equal(state.Names) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("public_keys"), types.MapUnknown(types.StringType))...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("private_keys"), types.MapUnknown(types.StringType))...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("seeds"), types.MapUnknown(types.StringType))...)
+	}
+}
+
+func (r *Keyset) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var data KeysetModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	created := r.generate(ctx, &data, nil, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Trace(ctx, "created keyset resource", map[string]interface{}{"keys": len(created)})
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	for _, key := range created {
+		r.provider.notify(ctx, data.event("created", key), &resp.Diagnostics)
+	}
+}
+
+func (r *Keyset) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data KeysetModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Keyset) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var plan, state KeysetModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	previous := state.keys(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	created := r.generate(ctx, &plan, previous, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, key := range created {
+		r.provider.notify(ctx, plan.event("created", key), &resp.Diagnostics)
+	}
+	kept := plan.keys(ctx, &resp.Diagnostics)
+	for _, name := range sortedKeys(previous) {
+		if _, ok := kept[name]; !ok {
+			r.provider.notify(ctx, state.event("deleted", previous[name].publicKey), &resp.Diagnostics)
+		}
+	}
+}
+
+func (r *Keyset) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data KeysetModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	keys := data.keys(ctx, &resp.Diagnostics)
+	for _, name := range sortedKeys(keys) {
+		r.provider.notify(ctx, data.event("deleted", keys[name].publicKey), &resp.Diagnostics)
+	}
+}
+
+// generate sets the key maps of data, keeping the key pairs in previous
+// and generating one for every other name. It returns the public keys of
+// the generated key pairs.
+func (r *Keyset) generate(ctx context.Context, data *KeysetModel, previous map[string]keysetKey, diags *diag.Diagnostics) []string {
+	var names []string
+	diags.Append(data.Names.ElementsAs(ctx, &names, false)...)
+	if diags.HasError() {
+		return nil
+	}
+
+	prefix, ok := keyTypes[strings.ToLower(data.KeyType.ValueString())]
+	if !ok {
+		prefix = nkeys.PrefixByteAccount
+	}
+
+	var created []string
+	publicKeys := map[string]attr.Value{}
+	privateKeys := map[string]attr.Value{}
+	seeds := map[string]attr.Value{}
+
+	for _, name := range names {
+		key, ok := previous[name]
+		if !ok {
+			var err error
+			key, err = newKeysetKey(prefix)
+			if err != nil {
+				addError(diags, "Unable to generate nkey", errorAt(path.Root("names"), err))
+				return nil
+			}
+			created = append(created, key.publicKey)
+		}
+
+		publicKeys[name] = types.StringValue(key.publicKey)
+		privateKeys[name] = types.StringValue(key.privateKey)
+		seeds[name] = types.StringValue(key.seed)
+	}
+
+	data.PublicKeys = types.MapValueMust(types.StringType, publicKeys)
+	data.PrivateKeys = types.MapValueMust(types.StringType, privateKeys)
+	data.Seeds = types.MapValueMust(types.StringType, seeds)
+
+	return created
+}
+
+func newKeysetKey(prefix nkeys.PrefixByte) (keysetKey, error) {
+	keys, err := createKeyPair(prefix)
+	if err != nil {
+		return keysetKey{}, err
+	}
+	defer keys.Wipe()
+
+	pubKey, err := keys.PublicKey()
+	if err != nil {
+		return keysetKey{}, err
+	}
+	privKey, err := keys.PrivateKey()
+	if err != nil {
+		return keysetKey{}, err
+	}
+	defer clear(privKey)
+	seed, err := keys.Seed()
+	if err != nil {
+		return keysetKey{}, err
+	}
+	defer clear(seed)
+
+	return keysetKey{publicKey: pubKey, privateKey: string(privKey), seed: string(seed)}, nil
+}
+
+// keys returns the key material in state by name.
+func (m *KeysetModel) keys(ctx context.Context, diags *diag.Diagnostics) map[string]keysetKey {
+	publicKeys := map[string]string{}
+	privateKeys := map[string]string{}
+	seeds := map[string]string{}
+
+	diags.Append(m.PublicKeys.ElementsAs(ctx, &publicKeys, false)...)
+	diags.Append(m.PrivateKeys.ElementsAs(ctx, &privateKeys, false)...)
+	diags.Append(m.Seeds.ElementsAs(ctx, &seeds, false)...)
+
+	keys := make(map[string]keysetKey, len(publicKeys))
+	for name, pubKey := range publicKeys {
+		keys[name] = keysetKey{publicKey: pubKey, privateKey: privateKeys[name], seed: seeds[name]}
+	}
+
+	return keys
+}
+
+func (m *KeysetModel) event(name, pubKey string) webhookEvent {
+	return webhookEvent{
+		Event:     name,
+		Resource:  "nkey_keyset",
+		Type:      keyTypeName(nkeys.Prefix(pubKey)),
+		PublicKey: pubKey,
+	}
+}
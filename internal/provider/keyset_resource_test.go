@@ -0,0 +1,82 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/nkeys"
+)
+
+func TestKeyset(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	config := func(names ...string) map[string]tftypes.Value {
+		return map[string]tftypes.Value{
+			"type":  stringValue("user"),
+			"names": stringSetValue(names...),
+		}
+	}
+
+	// check verifies that the key pairs of state belong together and are
+	// of the configured type, and returns the public keys.
+	check := func(t *testing.T, state tftypes.Value, names ...string) map[string]string {
+		t.Helper()
+
+		publicKeys := stringMapAttribute(t, state, "public_keys")
+		privateKeys := stringMapAttribute(t, state, "private_keys")
+		seeds := stringMapAttribute(t, state, "seeds")
+		if len(publicKeys) != len(names) || len(privateKeys) != len(names) || len(seeds) != len(names) {
+			t.Fatalf("%d public keys, %d private keys and %d seeds, want %d each", len(publicKeys), len(privateKeys), len(seeds), len(names))
+		}
+
+		for _, name := range names {
+			kp, err := nkeys.FromSeed([]byte(seeds[name]))
+			if err != nil {
+				t.Fatalf("%s: %s", name, err)
+			}
+			if pubKey, _ := kp.PublicKey(); pubKey != publicKeys[name] || nkeys.Prefix(pubKey) != nkeys.PrefixByteUser {
+				t.Errorf("%s: seed of %s, public key %s", name, pubKey, publicKeys[name])
+			}
+			if privateKey, _ := kp.PrivateKey(); string(privateKey) != privateKeys[name] {
+				t.Errorf("%s: private key does not belong to the seed", name)
+			}
+
+			// A signature made with the seed verifies with the public key.
+			sig, err := kp.Sign([]byte(name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			verifier, err := nkeys.FromPublicKey(publicKeys[name])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := verifier.Verify([]byte(name), sig); err != nil {
+				t.Errorf("%s: %s", name, err)
+			}
+		}
+		return publicKeys
+	}
+
+	state := p.create("nkey_keyset", config("billing", "orders"))
+	created := check(t, state, "billing", "orders")
+	if created["billing"] == created["orders"] {
+		t.Error("both names got the same key pair")
+	}
+
+	state = p.apply("nkey_keyset", state, config("orders", "shipping"))
+	updated := check(t, state, "orders", "shipping")
+	if updated["orders"] != created["orders"] {
+		t.Error("the key pair of orders changed")
+	}
+	if updated["shipping"] == created["billing"] {
+		t.Error("shipping got the key pair of the removed billing")
+	}
+
+	if diags := p.validate("nkey_keyset", config("")); !hasError(diags, "Invalid Attribute Value Length") {
+		t.Errorf("expected a validation error for an empty name, got %v", diags)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &LeafnodeAuthorization{}
+
+func NewLeafnodeAuthorization() datasource.DataSource {
+	return &LeafnodeAuthorization{}
+}
+
+// LeafnodeAuthorization defines the data source implementation.
+type LeafnodeAuthorization struct {
+}
+
+// LeafnodeAuthorizationModel describes the data source data model.
+type LeafnodeAuthorizationModel struct {
+	Port     types.Int64  `tfsdk:"port"`
+	Accounts types.Map    `tfsdk:"accounts"`
+	Config   types.String `tfsdk:"config"`
+}
+
+func (d *LeafnodeAuthorization) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_leafnode_authorization"
+}
+
+func (d *LeafnodeAuthorization) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Renders the hub side nats-server configuration accepting leafnode connections authenticated by user nkeys. " +
+			"The `leafnodes` block carries no `authorization` of its own, as nats-server only supports nkeys through the " +
+			"users of the `accounts` block, which also binds every leafnode connection to its account.",
+
+		Attributes: map[string]schema.Attribute{
+			"port": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Port to listen on for leafnode connections. Defaults to 7422",
+			},
+			"accounts": schema.MapAttribute{
+				Required:            true,
+				ElementType:         types.ListType{ElemType: types.StringType},
+				MarkdownDescription: "Map of account names to the user public keys leafnodes of that account connect with",
+			},
+			"config": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The rendered configuration",
+			},
+		},
+	}
+}
+
+func (d *LeafnodeAuthorization) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data LeafnodeAuthorizationModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	accounts := map[string][]string{}
+	resp.Diagnostics.Append(data.Accounts.ElementsAs(ctx, &accounts, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	port := int64(7422)
+	if !data.Port.IsNull() {
+		port = data.Port.ValueInt64()
+	}
+
+	w := &confWriter{}
+
+	w.open("leafnodes")
+	w.line("port: %d", port)
+	w.close()
+	w.line("")
+	w.open("accounts")
+
+	for _, name := range sortedKeys(accounts) {
+		w.open(quote(name))
+		w.line("users: [")
+		w.depth++
+
+		for i, user := range accounts[name] {
+			if !nkeys.IsValidPublicUserKey(user) {
+				resp.Diagnostics.AddAttributeError(path.Root("accounts").AtMapKey(name).AtListIndex(i),
+					"Invalid user public key", fmt.Sprintf("%q is not a user public key", user))
+				continue
+			}
+			w.line("{nkey: %s}", quote(user))
+		}
+
+		w.depth--
+		w.line("]")
+		w.close()
+	}
+
+	w.close()
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Config = types.StringValue(w.String())
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
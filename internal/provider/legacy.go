@@ -0,0 +1,207 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/nats-io/jwt/v2"
+	jwtv1 "github.com/nats-io/jwt/v2/v1compat"
+	"github.com/nats-io/nkeys"
+)
+
+// jwtVersionAttribute returns the jwt_version attribute of the JWT resources.
+func jwtVersionAttribute() schema.Int64Attribute {
+	return schema.Int64Attribute{
+		Optional: true,
+		MarkdownDescription: "Layout of the JWT, `2` or `1` for nats-server 2.1 and older, which only understand " +
+			"the jwt v1 layout. Claims the v1 layout cannot carry, such as JetStream limits, fail the apply. Newer " +
+			"servers accept both. Defaults to `2`",
+		Validators: []validator.Int64{
+			int64validator.OneOf(1, 2),
+		},
+	}
+}
+
+// jwtEncoder returns the function encoding JWTs of the jwt_version.
+func jwtEncoder(version types.Int64) func(jwt.Claims, nkeys.KeyPair, bool, *diag.Diagnostics) (string, error) {
+	if version.ValueInt64() == 1 {
+		return encodeLegacyJWT
+	}
+	return encodeJWT
+}
+
+// encodeLegacyJWT is encodeJWT for the jwt v1 layout. It returns an error if
+// the claims hold anything the v1 layout cannot carry, which servers of the
+// time would silently ignore.
+func encodeLegacyJWT(claims jwt.Claims, keys nkeys.KeyPair, deterministicID bool, diags *diag.Diagnostics) (string, error) {
+	vr := jwt.CreateValidationResults()
+	claims.Validate(vr)
+
+	for _, warning := range vr.Warnings() {
+		diags.AddWarning("JWT validation warning", warning)
+	}
+	if errs := vr.Errors(); len(errs) > 0 {
+		return "", errors.Join(errs...)
+	}
+
+	var unsupported []string
+	legacy := legacyClaims(claims, &unsupported)
+	if len(unsupported) > 0 {
+		return "", fmt.Errorf("the jwt v1 layout cannot carry %s", strings.Join(unsupported, ", "))
+	}
+	if legacy == nil {
+		return "", fmt.Errorf("the jwt v1 layout has no %s claims", claims.ClaimType())
+	}
+
+	issuedAt := claims.Claims().IssuedAt
+	token, err := legacy.Encode(keys)
+	if err != nil || (issuedAt == 0 && !deterministicID) {
+		return token, err
+	}
+
+	return reproducibleJWT(token, issuedAt, deterministicID, keys)
+}
+
+// legacyClaims returns claims in the jwt v1 layout, adding the claims it
+// cannot carry to unsupported.
+func legacyClaims(claims jwt.Claims, unsupported *[]string) interface {
+	Encode(nkeys.KeyPair) (string, error)
+} {
+	notSupported := func(set bool, name string) {
+		if set {
+			*unsupported = append(*unsupported, name)
+		}
+	}
+
+	switch c := claims.(type) {
+	case *jwt.OperatorClaims:
+		v1 := jwtv1.NewOperatorClaims(c.Subject)
+		legacyClaimsData(&v1.ClaimsData, c.ClaimsData, c.Tags)
+		v1.SigningKeys.Add(c.SigningKeys...)
+		v1.AccountServerURL = c.AccountServerURL
+		v1.OperatorServiceURLs.Add(c.OperatorServiceURLs...)
+		v1.SystemAccount = c.SystemAccount
+
+		notSupported(c.AssertServerVersion != "", "assert_server_version")
+		notSupported(c.StrictSigningKeyUsage, "strict_signing_key_usage")
+		return v1
+
+	case *jwt.AccountClaims:
+		v1 := jwtv1.NewAccountClaims(c.Subject)
+		legacyClaimsData(&v1.ClaimsData, c.ClaimsData, c.Tags)
+		for _, imp := range c.Imports {
+			v1.Imports.Add(&jwtv1.Import{
+				Name:    imp.Name,
+				Subject: jwtv1.Subject(imp.Subject),
+				Account: imp.Account,
+				Token:   imp.Token,
+				To:      jwtv1.Subject(imp.To),
+				Type:    jwtv1.ExportType(imp.Type),
+			})
+			notSupported(imp.LocalSubject != "", "local_subject of imports")
+			notSupported(imp.Share, "share of imports")
+			notSupported(imp.AllowTrace, "allow_trace of imports")
+		}
+		for _, exp := range c.Exports {
+			v1Export := &jwtv1.Export{
+				Name:                 exp.Name,
+				Subject:              jwtv1.Subject(exp.Subject),
+				Type:                 jwtv1.ExportType(exp.Type),
+				TokenReq:             exp.TokenReq,
+				Revocations:          jwtv1.RevocationList(exp.Revocations),
+				ResponseType:         jwtv1.ResponseType(exp.ResponseType),
+				AccountTokenPosition: exp.AccountTokenPosition,
+			}
+			if exp.Latency != nil {
+				v1Export.Latency = &jwtv1.ServiceLatency{Sampling: int(exp.Latency.Sampling), Results: jwtv1.Subject(exp.Latency.Results)}
+				notSupported(exp.Latency.Sampling == jwt.Headers, "header sampling of service latency")
+			}
+			v1.Exports.Add(v1Export)
+			notSupported(exp.ResponseThreshold != 0, "response_threshold of exports")
+			notSupported(exp.Advertise, "advertise of exports")
+			notSupported(exp.AllowTrace, "allow_trace of exports")
+			notSupported(exp.Description != "" || exp.InfoURL != "", "description and info_url of exports")
+		}
+		v1.Limits = jwtv1.OperatorLimits{
+			Subs:            c.Limits.Subs,
+			Conn:            c.Limits.Conn,
+			LeafNodeConn:    c.Limits.LeafNodeConn,
+			Imports:         c.Limits.Imports,
+			Exports:         c.Limits.Exports,
+			Data:            c.Limits.Data,
+			Payload:         c.Limits.Payload,
+			WildcardExports: c.Limits.WildcardExports,
+		}
+		for key, scope := range c.SigningKeys {
+			v1.SigningKeys.Add(key)
+			notSupported(scope != nil, "scoped signing keys")
+		}
+		if len(c.Revocations) > 0 {
+			v1.Revocations = jwtv1.RevocationList(c.Revocations)
+		}
+
+		notSupported(c.Limits.JetStreamLimits != jwt.JetStreamLimits{} || len(c.Limits.JetStreamTieredLimits) > 0, "JetStream limits")
+		notSupported(c.Limits.DisallowBearer, "disallow_bearer")
+		notSupported(!c.DefaultPermissions.Pub.Empty() || !c.DefaultPermissions.Sub.Empty() || c.DefaultPermissions.Resp != nil,
+			"default permissions")
+		notSupported(len(c.Mappings) > 0, "mappings")
+		notSupported(c.Authorization.IsEnabled(), "authorization")
+		notSupported(c.Trace != nil, "trace")
+		notSupported(c.ClusterTraffic != "", "cluster_traffic")
+		notSupported(c.Description != "" || c.InfoURL != "", "description and info_url")
+		return v1
+
+	case *jwt.UserClaims:
+		v1 := jwtv1.NewUserClaims(c.Subject)
+		legacyClaimsData(&v1.ClaimsData, c.ClaimsData, c.Tags)
+		v1.Pub.Allow.Add(c.Pub.Allow...)
+		v1.Pub.Deny.Add(c.Pub.Deny...)
+		v1.Sub.Allow.Add(c.Sub.Allow...)
+		v1.Sub.Deny.Add(c.Sub.Deny...)
+		if c.Resp != nil {
+			v1.Resp = &jwtv1.ResponsePermission{MaxMsgs: c.Resp.MaxMsgs, Expires: c.Resp.Expires}
+		}
+		v1.Limits.Payload = c.Limits.Payload
+		v1.Src = strings.Join(c.Src, ",")
+		for _, tr := range c.Times {
+			v1.Times = append(v1.Times, jwtv1.TimeRange{Start: tr.Start, End: tr.End})
+		}
+		v1.BearerToken = c.BearerToken
+		v1.IssuerAccount = c.IssuerAccount
+
+		notSupported(c.Limits.Subs != jwt.NoLimit || c.Limits.Data != jwt.NoLimit, "subscription and data limits")
+		notSupported(c.Locale != "", "times_location")
+		notSupported(len(c.AllowedConnectionTypes) > 0, "allowed_connection_types")
+		return v1
+
+	case *jwt.ActivationClaims:
+		v1 := jwtv1.NewActivationClaims(c.Subject)
+		legacyClaimsData(&v1.ClaimsData, c.ClaimsData, c.Tags)
+		v1.ImportSubject = jwtv1.Subject(c.ImportSubject)
+		v1.ImportType = jwtv1.ExportType(c.ImportType)
+		v1.IssuerAccount = c.IssuerAccount
+		return v1
+	}
+	return nil
+}
+
+// legacyClaimsData copies the registered claims and tags into the jwt v1
+// layout, which keeps the tags among them.
+func legacyClaimsData(v1 *jwtv1.ClaimsData, c jwt.ClaimsData, tags jwt.TagList) {
+	v1.Audience = c.Audience
+	v1.Expires = c.Expires
+	v1.IssuedAt = c.IssuedAt
+	v1.Name = c.Name
+	v1.NotBefore = c.NotBefore
+	v1.Tags.Add(tags...)
+}
@@ -0,0 +1,108 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/jwt/v2"
+	jwtv1 "github.com/nats-io/jwt/v2/v1compat"
+	"github.com/nats-io/nkeys"
+)
+
+func TestLegacyJWT(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	operator, _ := nkeys.CreateOperator()
+	operatorKey, _ := operator.PublicKey()
+	operatorSeed, _ := operator.Seed()
+	account, _ := nkeys.CreateAccount()
+	accountKey, _ := account.PublicKey()
+	accountSeed, _ := account.Seed()
+	user, _ := nkeys.CreateUser()
+	userKey, _ := user.PublicKey()
+
+	tags := tftypes.NewValue(tftypes.Set{ElementType: tftypes.String}, []tftypes.Value{stringValue("team:billing")})
+
+	t.Run("account", func(t *testing.T) {
+		state := p.create("nkey_account_jwt", map[string]tftypes.Value{
+			"public_key":  stringValue(accountKey),
+			"issuer_seed": stringValue(string(operatorSeed)),
+			"name":        stringValue("billing"),
+			"tags":        tags,
+			"jwt_version": numberValue(1),
+		})
+		token := stringAttribute(t, state, "jwt")
+
+		header, _, _ := strings.Cut(token, ".")
+		if decoded, _ := base64.RawURLEncoding.DecodeString(header); !strings.Contains(string(decoded), `"alg":"ed25519"`) {
+			t.Errorf("header = %s, want the v1 algorithm", decoded)
+		}
+
+		claims, err := jwtv1.DecodeAccountClaims(token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if claims.Issuer != operatorKey || claims.Subject != accountKey || claims.Name != "billing" {
+			t.Errorf("iss = %s, sub = %s, name = %s, want the operator, account and billing", claims.Issuer, claims.Subject, claims.Name)
+		}
+		if !claims.Tags.Contains("team:billing") {
+			t.Errorf("tags = %v, want team:billing", claims.Tags)
+		}
+
+		// Newer servers read the v1 layout as well.
+		if v2, err := jwt.DecodeAccountClaims(token); err != nil || v2.Version != 1 {
+			t.Errorf("jwt v2 decodes version %v, %v, want 1", v2, err)
+		}
+	})
+
+	t.Run("user", func(t *testing.T) {
+		state := p.create("nkey_user_jwt", map[string]tftypes.Value{
+			"public_key":  stringValue(userKey),
+			"issuer_seed": stringValue(string(accountSeed)),
+			"name":        stringValue("gateway"),
+			"jwt_version": numberValue(1),
+			"issued_at":   stringValue("2026-01-01T00:00:00Z"),
+		})
+
+		claims, err := jwtv1.DecodeUserClaims(stringAttribute(t, state, "jwt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if claims.Issuer != accountKey || claims.Subject != userKey {
+			t.Errorf("iss = %s, sub = %s, want the account and user", claims.Issuer, claims.Subject)
+		}
+		if claims.IssuedAt != 1767225600 {
+			t.Errorf("iat = %d, want the pinned time", claims.IssuedAt)
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, diags := p.tryApply("nkey_account_jwt", tftypes.Value{}, map[string]tftypes.Value{
+			"public_key":      stringValue(accountKey),
+			"issuer_seed":     stringValue(string(operatorSeed)),
+			"name":            stringValue("billing"),
+			"cluster_traffic": stringValue("owner"),
+			"jwt_version":     numberValue(1),
+		})
+		if !hasError(diags, "Unable to issue account JWT") {
+			t.Errorf("expected an error for cluster_traffic, got %v", diags)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		diags := p.validate("nkey_operator_jwt", map[string]tftypes.Value{
+			"seed":        stringValue(string(operatorSeed)),
+			"name":        stringValue("example"),
+			"jwt_version": numberValue(3),
+		})
+		if !hasError(diags, "Invalid Attribute Value Match") {
+			t.Errorf("expected a validation error, got %v", diags)
+		}
+	})
+}
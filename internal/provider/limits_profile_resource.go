@@ -0,0 +1,123 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/objectvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &LimitsProfile{}
+
+func NewLimitsProfile() resource.Resource {
+	return &LimitsProfile{}
+}
+
+// LimitsProfile defines the resource implementation.
+type LimitsProfile struct{}
+
+// LimitsProfileModel describes the resource data model.
+type LimitsProfileModel struct {
+	Name           types.String                    `tfsdk:"name"`
+	Limits         *AccountLimitsModel             `tfsdk:"limits"`
+	JetStream      *JetStreamLimitsModel           `tfsdk:"jetstream_limits"`
+	JetStreamTiers map[string]JetStreamLimitsModel `tfsdk:"jetstream_tiered_limits"`
+}
+
+func (r *LimitsProfile) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_limits_profile"
+}
+
+func (r *LimitsProfile) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "A named profile of account limits, such as the quota tier of a tenant, defined once and " +
+			"referenced by any number of accounts, e.g. `limits = nkey_limits_profile.small.limits` in " +
+			"`nkey_account_jwt` or `nkey_tenant`. The attributes have the same shape as those of the accounts. Changing " +
+			"the profile issues the JWTs of all accounts referencing it again. The profile only exists in state.",
+
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Name of the profile",
+			},
+			"limits": schema.SingleNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "Limits of the accounts, see `nkey_account_jwt`. Unset limits are unlimited",
+				Attributes:          accountLimitsAttributes(),
+			},
+			"jetstream_limits": schema.SingleNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "JetStream limits of the accounts. Unset limits are unlimited",
+				Attributes:          jetStreamLimitsAttributes(),
+				Validators: []validator.Object{
+					objectvalidator.ConflictsWith(path.MatchRoot("jetstream_tiered_limits")),
+				},
+			},
+			"jetstream_tiered_limits": schema.MapNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "JetStream limits of the accounts per replication tier, e.g. `R1` and `R3`",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: jetStreamLimitsAttributes(),
+				},
+			},
+		},
+	}
+}
+
+func (r *LimitsProfile) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data LimitsProfileModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Trace(ctx, "created limits profile resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *LimitsProfile) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data LimitsProfileModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *LimitsProfile) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data LimitsProfileModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *LimitsProfile) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Nothing to do here as the profile only exists in state.
+}
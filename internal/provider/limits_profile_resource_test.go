@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestLimitsProfile(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	operator, _ := nkeys.CreateOperator()
+	seed, _ := operator.Seed()
+
+	typ := p.resourceSchema("nkey_limits_profile").ValueType().(tftypes.Object)
+	limitsType := typ.AttributeTypes["limits"].(tftypes.Object)
+	tiersType := typ.AttributeTypes["jetstream_tiered_limits"].(tftypes.Map)
+	tierType := tiersType.ElementType.(tftypes.Object)
+
+	limits := map[string]tftypes.Value{}
+	for name, t := range limitsType.AttributeTypes {
+		limits[name] = tftypes.NewValue(t, nil)
+	}
+	limits["connections"] = numberValue(100)
+	limits["imports"] = numberValue(5)
+	tier := map[string]tftypes.Value{}
+	for name, t := range tierType.AttributeTypes {
+		tier[name] = tftypes.NewValue(t, nil)
+	}
+	tier["disk_storage"] = numberValue(1 << 30)
+
+	profile := p.create("nkey_limits_profile", map[string]tftypes.Value{
+		"name":   stringValue("small"),
+		"limits": tftypes.NewValue(limitsType, limits),
+		"jetstream_tiered_limits": tftypes.NewValue(tiersType, map[string]tftypes.Value{
+			"R1": tftypes.NewValue(tierType, tier),
+		}),
+	})
+
+	// Accounts reference the attributes of the profile as they are.
+	for _, name := range []string{"billing", "reporting"} {
+		account, _ := nkeys.CreateAccount()
+		accountKey, _ := account.PublicKey()
+
+		state := p.create("nkey_account_jwt", map[string]tftypes.Value{
+			"public_key":              stringValue(accountKey),
+			"issuer_seed":             stringValue(string(seed)),
+			"name":                    stringValue(name),
+			"limits":                  attribute(t, profile, "limits"),
+			"jetstream_tiered_limits": attribute(t, profile, "jetstream_tiered_limits"),
+		})
+
+		claims, err := jwt.DecodeAccountClaims(stringAttribute(t, state, "jwt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if claims.Limits.Conn != 100 || claims.Limits.Imports != 5 || claims.Limits.Exports != jwt.NoLimit {
+			t.Errorf("%s: limits = %+v", name, claims.Limits.AccountLimits)
+		}
+		if r1 := claims.Limits.JetStreamTieredLimits["R1"]; r1.DiskStorage != 1<<30 || r1.MemoryStorage != jwt.NoLimit {
+			t.Errorf("%s: R1 limits = %+v", name, r1)
+		}
+	}
+}
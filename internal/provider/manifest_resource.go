@@ -0,0 +1,284 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &Manifest{}
+
+func NewManifest() resource.Resource {
+	return &Manifest{}
+}
+
+// Manifest defines the resource implementation.
+type Manifest struct {
+}
+
+// ManifestModel describes the resource data model.
+type ManifestModel struct {
+	Path       types.String `tfsdk:"path"`
+	Keys       types.Map    `tfsdk:"keys"`
+	JWTs       types.Map    `tfsdk:"jwts"`
+	SigningKey types.String `tfsdk:"signing_key"`
+	Signer     types.String `tfsdk:"signer"`
+	Content    types.String `tfsdk:"content"`
+	SHA256     types.String `tfsdk:"sha256"`
+}
+
+// manifestPayload is the signed part of the manifest.
+type manifestPayload struct {
+	Version int               `json:"version"`
+	Keys    map[string]string `json:"keys"`
+	JWTs    map[string]string `json:"jwts,omitempty"`
+}
+
+// signedManifest is the document written to disk. The manifest is kept as
+// raw bytes so the signature can be verified over exactly what was signed.
+type signedManifest struct {
+	Manifest  json.RawMessage `json:"manifest"`
+	Signer    string          `json:"signer"`
+	Signature string          `json:"signature"`
+}
+
+func (r *Manifest) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_manifest"
+}
+
+func (r *Manifest) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "A signed JSON manifest of public keys and JWTs, written to a local file on apply.",
+
+		Attributes: map[string]schema.Attribute{
+			"path": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Path of the file the manifest is written to",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"keys": schema.MapAttribute{
+				Required:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Map of names to nkey public keys to include in the manifest",
+			},
+			"jwts": schema.MapAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				MarkdownDescription: "Map of names to encoded JWTs to include in the manifest. Creds files and " +
+					"anything else with a seed are rejected",
+			},
+			"signing_key": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Seed of the nkey the manifest is signed with",
+				Sensitive:           true,
+			},
+			"signer": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the nkey the manifest was signed with",
+			},
+			"content": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Content of the manifest file",
+			},
+			"sha256": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Hex encoded SHA-256 checksum of the manifest file",
+			},
+		},
+	}
+}
+
+func (r *Manifest) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Nothing to do here as the manifest is written locally
+}
+
+func (r *Manifest) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var data ManifestModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.write(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Trace(ctx, "created manifest resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Manifest) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data ManifestModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A manifest that was removed or modified outside of Terraform is
+	// written again on the next apply.
+	content, err := os.ReadFile(data.Path.ValueString())
+	if errors.Is(err, os.ErrNotExist) || (err == nil && string(content) != data.Content.ValueString()) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addError(&resp.Diagnostics, "Unable to read manifest", errorAt(path.Root("path"), err))
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Manifest) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var plan ManifestModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.write(ctx, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *Manifest) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data ManifestModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := os.Remove(data.Path.ValueString()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		addError(&resp.Diagnostics, "Unable to delete manifest", errorAt(path.Root("path"), err))
+	}
+}
+
+func (r *Manifest) write(ctx context.Context, data *ManifestModel, diags *diag.Diagnostics) {
+	payload := manifestPayload{Version: 1}
+
+	diags.Append(data.Keys.ElementsAs(ctx, &payload.Keys, false)...)
+	if !data.JWTs.IsNull() {
+		diags.Append(data.JWTs.ElementsAs(ctx, &payload.JWTs, false)...)
+	}
+	if diags.HasError() {
+		return
+	}
+
+	for name, key := range payload.Keys {
+		if !nkeys.IsValidPublicKey(key) {
+			diags.AddAttributeError(path.Root("keys").AtMapKey(name), "Invalid public key",
+				fmt.Sprintf("%q is not an nkey public key; only public data may be added to a manifest", name))
+		}
+	}
+	for name, token := range payload.JWTs {
+		if _, err := jwt.DecodeGeneric(token); err != nil {
+			diags.AddAttributeError(path.Root("jwts").AtMapKey(name), "Invalid JWT",
+				fmt.Sprintf("%q is not an encoded JWT: %s", name, err))
+		} else if redactSecrets(token) != token {
+			diags.AddAttributeError(path.Root("jwts").AtMapKey(name), "Invalid JWT",
+				fmt.Sprintf("%q contains a seed or private key; only public data may be added to a manifest", name))
+		}
+	}
+	if diags.HasError() {
+		return
+	}
+
+	content, signer, err := signManifest(payload, data.SigningKey.ValueString())
+	if err != nil {
+		addError(diags, "Unable to sign manifest", err)
+		return
+	}
+
+	if err := os.WriteFile(data.Path.ValueString(), content, 0o644); err != nil {
+		addError(diags, "Unable to write manifest", errorAt(path.Root("path"), err))
+		return
+	}
+
+	sum := sha256.Sum256(content)
+
+	data.Signer = types.StringValue(signer)
+	data.Content = types.StringValue(string(content))
+	data.SHA256 = types.StringValue(hex.EncodeToString(sum[:]))
+}
+
+func signManifest(payload manifestPayload, seed string) (content []byte, signer string, err error) {
+	keys, err := nkeys.FromSeed([]byte(seed))
+	if err != nil {
+		return nil, "", errorAt(path.Root("signing_key"), err)
+	}
+	defer keys.Wipe()
+
+	manifest, err := json.Marshal(payload)
+	if err != nil {
+		return nil, "", err
+	}
+
+	sig, err := keys.Sign(manifest)
+	if err != nil {
+		return nil, "", errorAt(path.Root("signing_key"), err)
+	}
+
+	signer, err = keys.PublicKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Not indented, so the manifest bytes stay exactly as signed.
+	content, err = json.Marshal(signedManifest{
+		Manifest:  manifest,
+		Signer:    signer,
+		Signature: base64.RawURLEncoding.EncodeToString(sig),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return append(content, '\n'), signer, nil
+}
@@ -0,0 +1,123 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestManifest(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	signer, _ := nkeys.CreateOperator()
+	signerKey, _ := signer.PublicKey()
+	signerSeed, _ := signer.Seed()
+	account, _ := nkeys.CreateAccount()
+	accountKey, _ := account.PublicKey()
+	accountSeed, _ := account.Seed()
+	token, err := jwt.NewAccountClaims(accountKey).Encode(signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name := filepath.Join(t.TempDir(), "manifest.json")
+	config := map[string]tftypes.Value{
+		"path":        stringValue(name),
+		"keys":        tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{"billing": stringValue(accountKey)}),
+		"jwts":        tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{"billing": stringValue(token)}),
+		"signing_key": stringValue(string(signerSeed)),
+	}
+	state := p.create("nkey_manifest", config)
+
+	content, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != stringAttribute(t, state, "content") {
+		t.Error("the manifest file differs from content")
+	}
+	sum := sha256.Sum256(content)
+	if got := stringAttribute(t, state, "sha256"); got != hex.EncodeToString(sum[:]) {
+		t.Errorf("sha256 = %s, want the checksum of the file", got)
+	}
+
+	var signed signedManifest
+	if err := json.Unmarshal(content, &signed); err != nil {
+		t.Fatal(err)
+	}
+	if signed.Signer != signerKey || stringAttribute(t, state, "signer") != signerKey {
+		t.Errorf("manifest signed by %s, want %s", signed.Signer, signerKey)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signed.Signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, _ := nkeys.FromPublicKey(signerKey)
+	if err := verifier.Verify(signed.Manifest, sig); err != nil {
+		t.Errorf("invalid manifest signature: %s", err)
+	}
+
+	var payload manifestPayload
+	if err := json.Unmarshal(signed.Manifest, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Version != 1 || payload.Keys["billing"] != accountKey {
+		t.Errorf("manifest = %+v", payload)
+	}
+	verifySignature(t, payload.JWTs["billing"], signerKey)
+	if claims, err := jwt.DecodeAccountClaims(payload.JWTs["billing"]); err != nil || claims.Subject != accountKey {
+		t.Errorf("manifest JWT of %v: %v", claims, err)
+	}
+
+	// A manifest modified outside of Terraform is written again.
+	if err := os.WriteFile(name, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if refreshed := p.refresh("nkey_manifest", state); !refreshed.IsNull() {
+		t.Error("the modified manifest was kept in state")
+	}
+
+	config["keys"] = tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{"billing": stringValue(string(accountSeed))})
+	if _, diags := p.tryApply("nkey_manifest", tftypes.Value{}, config); !hasError(diags, "Invalid public key") {
+		t.Errorf("expected an error for a seed in keys, got %v", diags)
+	}
+	config["keys"] = tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{"billing": stringValue(accountKey)})
+
+	// A user JWT and seed in a creds file are not a JWT on their own.
+	user, _ := nkeys.CreateUser()
+	userKey, _ := user.PublicKey()
+	userSeed, _ := user.Seed()
+	userJWT, err := jwt.NewUserClaims(userKey).Encode(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds, err := jwt.FormatUserConfig(userJWT, userSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, value := range []string{string(creds), "not a JWT", token + string(accountSeed)} {
+		config["jwts"] = tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{"billing": stringValue(value)})
+		_, diags := p.tryApply("nkey_manifest", tftypes.Value{}, config)
+		if !hasError(diags, "Invalid JWT") {
+			t.Errorf("expected an invalid JWT error, got %v", diags)
+		}
+		want := tftypes.NewAttributePath().WithAttributeName("jwts").WithElementKeyString("billing")
+		for _, d := range diags {
+			if d.Attribute == nil || !d.Attribute.Equal(want) {
+				t.Errorf("diagnostic at %v, want %v", d.Attribute, want)
+			}
+		}
+	}
+}
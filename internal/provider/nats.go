@@ -0,0 +1,128 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nkeys"
+)
+
+// natsRequestTimeout bounds every request sent to the system account that is
+// not bounded by a timeouts block.
+const natsRequestTimeout = 5 * time.Second
+
+// connectNATS connects to servers, authenticating with the given creds file
+// content if it is not empty. Errors about the credentials are reported at
+// the credentials attribute.
+func connectNATS(ctx context.Context, servers []string, creds string) (*nats.Conn, error) {
+	opts := []nats.Option{
+		nats.Name("terraform-provider-nkey"),
+		nats.NoReconnect(),
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		opts = append(opts, nats.Timeout(time.Until(deadline)))
+	}
+
+	// The user key pair is wiped once the connection is closed.
+	var keys nkeys.KeyPair
+	if creds != "" {
+		raw := []byte(creds)
+		defer clear(raw)
+
+		userJWT, err := nkeys.ParseDecoratedJWT(raw)
+		if err != nil {
+			return nil, errorAt(path.Root("credentials"), err)
+		}
+		// Unlike ParseDecoratedUserNKey, which leaves an intermediate copy
+		// of the key pair behind.
+		keys, err = nkeys.ParseDecoratedNKey(raw)
+		if err != nil {
+			return nil, errorAt(path.Root("credentials"), err)
+		}
+		if pubKey, err := keys.PublicKey(); err != nil || nkeys.Prefix(pubKey) != nkeys.PrefixByteUser {
+			keys.Wipe()
+			return nil, errorAt(path.Root("credentials"), nkeys.ErrInvalidUserSeed)
+		}
+
+		opts = append(opts,
+			nats.UserJWT(
+				func() (string, error) { return userJWT, nil },
+				func(nonce []byte) ([]byte, error) { return keys.Sign(nonce) },
+			),
+			nats.ClosedHandler(func(*nats.Conn) { keys.Wipe() }),
+		)
+	}
+
+	nc, err := nats.Connect(strings.Join(servers, ","), opts...)
+	if err != nil {
+		if keys != nil {
+			keys.Wipe()
+		}
+		return nil, errorAt(path.Root("servers"), fmt.Errorf("connecting to %s: %w", strings.Join(servers, ","), err))
+	}
+
+	return nc, nil
+}
+
+// lookupAccountJWT asks the resolver of the connected cluster for the JWT of
+// account. An empty string is returned if the account is unknown.
+func lookupAccountJWT(ctx context.Context, nc *nats.Conn, account string) (string, error) {
+	ctx, cancel := withDefaultTimeout(ctx, natsRequestTimeout)
+	defer cancel()
+
+	msg, err := nc.RequestWithContext(ctx, fmt.Sprintf("$SYS.REQ.ACCOUNT.%s.CLAIMS.LOOKUP", account), nil)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(msg.Data)), nil
+}
+
+// claimsUpdateResponse is the reply of the account resolver to a claims
+// update request.
+type claimsUpdateResponse struct {
+	Data *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"data"`
+	Error *struct {
+		Code        int    `json:"code"`
+		Description string `json:"description"`
+	} `json:"error"`
+}
+
+// pushAccountJWT sends token to the account resolver of the connected
+// cluster and returns the message of the resolver on success.
+func pushAccountJWT(ctx context.Context, nc *nats.Conn, token string) (string, error) {
+	ctx, cancel := withDefaultTimeout(ctx, natsRequestTimeout)
+	defer cancel()
+
+	msg, err := nc.RequestWithContext(ctx, "$SYS.REQ.CLAIMS.UPDATE", []byte(token))
+	if err != nil {
+		return "", err
+	}
+
+	var resp claimsUpdateResponse
+	if err := json.Unmarshal(msg.Data, &resp); err != nil {
+		return "", fmt.Errorf("decoding resolver response: %w", err)
+	}
+
+	switch {
+	case resp.Error != nil:
+		return "", fmt.Errorf("resolver returned %d: %s", resp.Error.Code, resp.Error.Description)
+	case resp.Data == nil:
+		return "", errors.New("empty resolver response")
+	}
+
+	return resp.Data.Message, nil
+}
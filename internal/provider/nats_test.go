@@ -0,0 +1,176 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// fakeNATSServer accepts a single client, sends it a nonce and reports the
+// CONNECT options the client answered with.
+func fakeNATSServer(t *testing.T, nonce string) (string, <-chan map[string]interface{}) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	connects := make(chan map[string]interface{}, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		fmt.Fprintf(conn, "INFO {\"server_id\":\"test\",\"version\":\"2.10.0\",\"proto\":1,\"max_payload\":1048576,\"auth_required\":true,\"nonce\":%q}\r\n", nonce)
+
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch {
+			case strings.HasPrefix(line, "CONNECT "):
+				var opts map[string]interface{}
+				_ = json.Unmarshal([]byte(strings.TrimPrefix(line, "CONNECT ")), &opts)
+				connects <- opts
+			case strings.HasPrefix(line, "PING"):
+				fmt.Fprint(conn, "PONG\r\n")
+			}
+		}
+	}()
+
+	return "nats://" + listener.Addr().String(), connects
+}
+
+// fakeResolver serves the requests of any number of clients with handle,
+// which returns the reply to a request on subject, or nil for none.
+func fakeResolver(t *testing.T, handle func(subject string, data []byte) []byte) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	serve := func(conn net.Conn) {
+		defer conn.Close()
+
+		fmt.Fprint(conn, "INFO {\"server_id\":\"test\",\"version\":\"2.10.0\",\"proto\":1,\"max_payload\":1048576}\r\n")
+
+		// subs maps subscribed subjects, with a trailing wildcard removed,
+		// to their sid.
+		subs := map[string]string{}
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			fields := strings.Fields(line)
+			switch {
+			case len(fields) == 0:
+			case fields[0] == "PING":
+				fmt.Fprint(conn, "PONG\r\n")
+			case fields[0] == "SUB" && len(fields) == 3:
+				subs[strings.TrimSuffix(fields[1], "*")] = fields[2]
+			case fields[0] == "PUB" && len(fields) == 4:
+				var size int
+				fmt.Sscan(fields[3], &size)
+				payload := make([]byte, size+2)
+				if _, err := io.ReadFull(r, payload); err != nil {
+					return
+				}
+				reply := handle(fields[1], payload[:size])
+				if reply == nil {
+					continue
+				}
+				for subject, sid := range subs {
+					if strings.HasPrefix(fields[2], subject) {
+						fmt.Fprintf(conn, "MSG %s %s %d\r\n%s\r\n", fields[2], sid, len(reply), reply)
+					}
+				}
+			}
+		}
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serve(conn)
+		}
+	}()
+
+	return "nats://" + listener.Addr().String()
+}
+
+func TestConnectNATSCredentials(t *testing.T) {
+	account, _ := nkeys.CreateAccount()
+	user, _ := nkeys.CreateUser()
+	userKey, _ := user.PublicKey()
+	userSeed, _ := user.Seed()
+	accountSeed, _ := account.Seed()
+
+	userJWT, err := jwt.NewUserClaims(userKey).Encode(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds, err := jwt.FormatUserConfig(userJWT, userSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const nonce = "ve9wt5UJbbcyis0"
+	url, connects := fakeNATSServer(t, nonce)
+
+	nc, err := connectNATS(context.Background(), []string{url}, string(creds))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nc.Close()
+
+	opts := <-connects
+	if opts["jwt"] != userJWT {
+		t.Errorf("CONNECT jwt = %v, want the user JWT", opts["jwt"])
+	}
+	sig, _ := opts["sig"].(string)
+	raw, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := user.Verify([]byte(nonce), raw); err != nil {
+		t.Errorf("nonce not signed by the user: %s", err)
+	}
+
+	for name, invalid := range map[string]string{
+		"not creds":    "not a creds file",
+		"account seed": strings.Replace(string(creds), string(userSeed), string(accountSeed), 1),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := connectNATS(context.Background(), []string{url}, invalid)
+			if err == nil || !strings.HasPrefix(err.Error(), "credentials: ") {
+				t.Errorf("expected an error at credentials, got %v", err)
+			}
+		})
+	}
+}
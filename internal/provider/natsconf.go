@@ -0,0 +1,51 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// confWriter renders nats-server configuration. Strings are always quoted
+// so keys and values never clash with the config grammar.
+type confWriter struct {
+	b     strings.Builder
+	depth int
+}
+
+func (w *confWriter) line(format string, args ...interface{}) {
+	w.b.WriteString(strings.Repeat("  ", w.depth))
+	fmt.Fprintf(&w.b, format, args...)
+	w.b.WriteString("\n")
+}
+
+func (w *confWriter) open(name string) {
+	w.line("%s {", name)
+	w.depth++
+}
+
+func (w *confWriter) close() {
+	w.depth--
+	w.line("}")
+}
+
+func (w *confWriter) String() string {
+	return w.b.String()
+}
+
+func quote(s string) string {
+	return strconv.Quote(s)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -5,14 +5,29 @@ package provider
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
 	"strings"
+	"terraform-provider-nkey/internal/shamir"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 
@@ -22,6 +37,9 @@ import (
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &Nkey{}
 var _ resource.ResourceWithImportState = &Nkey{}
+var _ resource.ResourceWithValidateConfig = &Nkey{}
+var _ resource.ResourceWithModifyPlan = &Nkey{}
+var _ resource.ResourceWithUpgradeState = &Nkey{}
 
 func NewNkey() resource.Resource {
 	return &Nkey{}
@@ -29,14 +47,58 @@ func NewNkey() resource.Resource {
 
 // Nkey defines the resource implementation.
 type Nkey struct {
+	provider *providerData
 }
 
 // NkeyModel describes the resource data model.
 type NkeyModel struct {
+	ID         types.String `tfsdk:"id"`
 	KeyType    types.String `tfsdk:"type"`
 	PublicKey  types.String `tfsdk:"public_key"`
 	PrivateKey types.String `tfsdk:"private_key"`
 	Seed       types.String `tfsdk:"seed"`
+
+	PublicKeyPEM  types.String `tfsdk:"public_key_pem"`
+	PrivateKeyPEM types.String `tfsdk:"private_key_pem"`
+	PublicKeyJWK  types.String `tfsdk:"public_key_jwk"`
+	PrivateKeyJWK types.String `tfsdk:"private_key_jwk"`
+
+	PublicKeyOpenSSH  types.String `tfsdk:"public_key_openssh"`
+	PrivateKeyOpenSSH types.String `tfsdk:"private_key_openssh"`
+
+	PublicKeyHex     types.String `tfsdk:"public_key_hex"`
+	PublicKeyBase64  types.String `tfsdk:"public_key_base64"`
+	PrivateKeyHex    types.String `tfsdk:"private_key_hex"`
+	PrivateKeyBase64 types.String `tfsdk:"private_key_base64"`
+
+	Fingerprint types.String `tfsdk:"fingerprint"`
+
+	DerivationPath types.String `tfsdk:"derivation_path"`
+
+	Shares         types.Int64 `tfsdk:"shares"`
+	ShareThreshold types.Int64 `tfsdk:"share_threshold"`
+	SeedShares     types.List  `tfsdk:"seed_shares"`
+
+	EncryptionPassphrase types.String `tfsdk:"encryption_passphrase"`
+	EncryptionRecipient  types.String `tfsdk:"encryption_recipient"`
+	EncryptedPrivateKey  types.String `tfsdk:"encrypted_private_key"`
+	SuppressPlaintext    types.Bool   `tfsdk:"suppress_plaintext"`
+	SeedFile             types.String `tfsdk:"seed_file"`
+
+	Keepers types.Map `tfsdk:"keepers"`
+
+	RotationDays types.Int64  `tfsdk:"rotation_days"`
+	CreatedAt    types.String `tfsdk:"created_at"`
+	ExpiresAt    types.String `tfsdk:"expires_at"`
+
+	RotationOverlapDays types.Int64  `tfsdk:"rotation_overlap_days"`
+	PreviousPublicKey   types.String `tfsdk:"previous_public_key"`
+	PreviousPrivateKey  types.String `tfsdk:"previous_private_key"`
+	PreviousSeed        types.String `tfsdk:"previous_seed"`
+	PreviousExpiresAt   types.String `tfsdk:"previous_expires_at"`
+
+	KeyHistory        types.Int64 `tfsdk:"key_history"`
+	RetiredPublicKeys types.List  `tfsdk:"retired_public_keys"`
 }
 
 func (r *Nkey) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -48,39 +110,490 @@ func (r *Nkey) Schema(ctx context.Context, req resource.SchemaRequest, resp *res
 		// This description is used by the documentation generator and the language server.
 		MarkdownDescription: "An nkey is an ed25519 key pair formatted for use with NATS.",
 
+		// Version 1 records the id, creation time and key formats that state
+		// written by earlier versions lacks, see UpgradeState.
+		Version: 1,
+
 		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Identifier of the nkey, the same as `public_key`",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
 			"type": schema.StringAttribute{
 				Optional:    true,
 				Computed:    true,
 				Default:     stringdefault.StaticString("account"),
 				Description: "The type of nkey to generate. Must be one of user|account|server|cluster|operator|curve",
 				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
+					keyTypeRequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.OneOfCaseInsensitive("user", "account", "server", "cluster", "operator", "curve"),
 				},
 			},
 			"public_key": schema.StringAttribute{
 				Computed:            true,
 				MarkdownDescription: "Public key of the nkey to be given in config to the nats server",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"fingerprint": schema.StringAttribute{
+				Computed: true,
+				MarkdownDescription: "Colon separated hex encoded SHA-256 hash of the raw public key, e.g. " +
+					"`3f:a2:...`, for inventories and allowlists",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"private_key": schema.StringAttribute{
 				Computed:            true,
-				MarkdownDescription: "Private key of the nkey to be given to the client for authentication",
+				MarkdownDescription: "Raw private key of the nkey (`P...`). NATS clients and creds files expect the `seed` instead",
+				Sensitive:           true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"public_key_pem": schema.StringAttribute{
+				Computed: true,
+				MarkdownDescription: "Public key in PEM encoded SPKI format, ed25519 for nkeys and X25519 for curve keys, " +
+					"e.g. for TLS tooling",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"private_key_pem": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Private key in PEM encoded PKCS#8 format. Null if `suppress_plaintext` is set",
 				Sensitive:           true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"public_key_jwk": schema.StringAttribute{
+				Computed: true,
+				MarkdownDescription: "Public key as an RFC 8037 `OKP` JWK with the `Ed25519` curve, or `X25519` for curve " +
+					"keys, and the public key as `kid`, e.g. to be published in a JWKS",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"private_key_jwk": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Private key as an RFC 8037 `OKP` JWK. Null if `suppress_plaintext` is set",
+				Sensitive:           true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"public_key_openssh": schema.StringAttribute{
+				Computed: true,
+				MarkdownDescription: "Public key in the OpenSSH `authorized_keys` format, with the nkey public key as " +
+					"comment. Null for curve keys",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"private_key_openssh": schema.StringAttribute{
+				Computed: true,
+				MarkdownDescription: "Private key in the OpenSSH format, e.g. to use the key as SSH host or user key. Null " +
+					"for curve keys and if `suppress_plaintext` is set",
+				Sensitive: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"public_key_hex": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Hex encoded raw 32 byte public key",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"public_key_base64": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Base64 encoded raw 32 byte public key",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"private_key_hex": schema.StringAttribute{
+				Computed: true,
+				MarkdownDescription: "Hex encoded raw private key, 64 bytes (seed followed by public key) for nkeys and " +
+					"32 bytes for curve keys. Null if `suppress_plaintext` is set",
+				Sensitive: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"private_key_base64": schema.StringAttribute{
+				Computed: true,
+				MarkdownDescription: "Base64 encoded raw private key, in the same layout as `private_key_hex`. Null if " +
+					"`suppress_plaintext` is set",
+				Sensitive: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"seed": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				MarkdownDescription: "Seed of the nkey (`S...`) to be given to the client for authentication, e.g. in a creds file. " +
+					"If set, the key pair is derived from this seed instead of being generated, e.g. to bring keys created by " +
+					"`nsc` under management. Its type must match `type`",
+				Sensitive: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					seedRequiresReplace(),
+				},
+			},
+			"derivation_path": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "Derive the key pair from the provider `master_seed` instead of generating it, so the " +
+					"same path always yields the same key, e.g. `prod/accounts/billing`. The seed is derived with " +
+					"HKDF-SHA256 over the master seed, with `terraform-provider-nkey` as salt and `<type>/<derivation_path>` " +
+					"as info. Conflicts with `seed` and `rotation_days`",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"shares": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Split the seed into this many Shamir secret shares. Requires `share_threshold`",
+			},
+			"share_threshold": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Number of shares required to recombine the seed, between 2 and `shares`",
+			},
+			"seed_shares": schema.ListAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Hex encoded Shamir secret shares of the seed, to be recombined with the `nkey_seed_from_shares` data source",
+				Sensitive:           true,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"encryption_passphrase": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Passphrase the seed is encrypted with into `encrypted_private_key`. Conflicts with `encryption_recipient`",
+				Sensitive:           true,
+			},
+			"encryption_recipient": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "age X25519 recipient (`age1...`) the seed is encrypted to into `encrypted_private_key`. Conflicts with `encryption_passphrase`",
+			},
+			"encrypted_private_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "ASCII armored age ciphertext of the seed, decrypt with `age --decrypt`",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"suppress_plaintext": schema.BoolAttribute{
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+				MarkdownDescription: "Do not store `private_key`, `seed` and the other private key formats in state. Requires " +
+					"an encryption passphrase or recipient, a `seed_file` or a `derivation_path`, otherwise the seed is lost",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
+			},
+			"seed_file": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "Path of a local file the seed is written to once when the key pair is generated, in " +
+					"the `.nk` format of `nsc`, with mode 0600. Together with `suppress_plaintext` the private key is " +
+					"delivered through this file only and never stored in state. The file is not removed on destroy",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"keepers": schema.MapAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Arbitrary map of values that, when changed, will trigger a new key pair to be generated",
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+			"rotation_days": schema.Int64Attribute{
+				Optional: true,
+				MarkdownDescription: "Number of days after which the key expires. An expired key is removed from state on " +
+					"refresh and a new key pair is generated on the next apply, unless `rotation_overlap_days` is set. " +
+					"Conflicts with `seed`",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"created_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "RFC 3339 timestamp of the key generation",
+			},
+			"expires_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "RFC 3339 timestamp at which the key expires, if `rotation_days` is set",
+			},
+			"rotation_overlap_days": schema.Int64Attribute{
+				Optional: true,
+				MarkdownDescription: "Rotate an expired key in place on the next apply and keep the previous key pair as " +
+					"`previous_public_key`, `previous_private_key` and `previous_seed` for this many days, so servers and " +
+					"clients can be moved to the new key without an outage. Must be less than `rotation_days`",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+					int64validator.AlsoRequires(path.MatchRoot("rotation_days")),
+				},
+			},
+			"previous_public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the key pair before the last rotation, until `previous_expires_at`",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"previous_private_key": schema.StringAttribute{
 				Computed:            true,
-				MarkdownDescription: "Seed of the nkey to be given to the client for authentication",
+				MarkdownDescription: "Raw private key of the key pair before the last rotation, until `previous_expires_at`",
 				Sensitive:           true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"previous_seed": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Seed of the key pair before the last rotation, until `previous_expires_at`",
+				Sensitive:           true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"previous_expires_at": schema.StringAttribute{
+				Computed: true,
+				MarkdownDescription: "RFC 3339 timestamp at which the overlap ends. The previous key pair is removed on " +
+					"the first apply after",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"key_history": schema.Int64Attribute{
+				Optional: true,
+				MarkdownDescription: "Number of retired public keys to keep in `retired_public_keys`. Like " +
+					"`rotation_overlap_days`, an expired key is then rotated in place instead of being removed from state. " +
+					"Keys replaced otherwise, e.g. by changing `keepers`, start a new history",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+					int64validator.AlsoRequires(path.MatchRoot("rotation_days")),
+				},
+			},
+			"retired_public_keys": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				MarkdownDescription: "Public keys retired by rotation, the most recent first, e.g. for the revocations " +
+					"of an account JWT or a server deny list",
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
 			},
 		},
 	}
 }
 
+func (r *Nkey) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data NkeyModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	encrypted := !data.EncryptionPassphrase.IsNull() || !data.EncryptionRecipient.IsNull()
+
+	if !data.EncryptionPassphrase.IsNull() && !data.EncryptionRecipient.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("encryption_recipient"), "Conflicting encryption configuration",
+			"only one of encryption_passphrase and encryption_recipient can be set")
+	}
+
+	if data.SuppressPlaintext.ValueBool() {
+		if !encrypted && data.SeedFile.IsNull() && data.DerivationPath.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("suppress_plaintext"), "Missing encryption configuration",
+				"suppress_plaintext requires encryption_passphrase, encryption_recipient, seed_file or derivation_path, "+
+					"otherwise the seed is lost")
+		}
+		if !data.Shares.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("shares"), "Conflicting plaintext configuration",
+				"seed shares allow recombining the plaintext seed and cannot be used with suppress_plaintext")
+		}
+		if !data.Seed.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("seed"), "Conflicting plaintext configuration",
+				"a configured seed is always stored in state and cannot be used with suppress_plaintext")
+		}
+	}
+
+	if !data.RotationDays.IsNull() && !data.Seed.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("rotation_days"), "Conflicting rotation configuration",
+			"a key derived from a configured seed cannot be rotated")
+	}
+
+	if !data.RotationOverlapDays.IsNull() && !data.RotationOverlapDays.IsUnknown() && !data.RotationDays.IsUnknown() &&
+		data.RotationOverlapDays.ValueInt64() >= data.RotationDays.ValueInt64() {
+		resp.Diagnostics.AddAttributeError(path.Root("rotation_overlap_days"), "Invalid rotation overlap",
+			"rotation_overlap_days must be less than rotation_days, otherwise the previous key is still in use at the next rotation")
+	}
+
+	if !data.DerivationPath.IsNull() {
+		if !data.Seed.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("derivation_path"), "Conflicting key configuration",
+				"only one of seed and derivation_path can be set")
+		}
+		if !data.RotationDays.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("rotation_days"), "Conflicting rotation configuration",
+				"a derived key is the same on every generation and cannot be rotated")
+		}
+	}
+
+	if !data.Seed.IsNull() && !data.Seed.IsUnknown() && !data.KeyType.IsUnknown() {
+		if err := data.checkSeed(); err != nil {
+			addError(&resp.Diagnostics, "Invalid seed", err)
+		}
+	}
+
+	if data.Shares.IsUnknown() || data.ShareThreshold.IsUnknown() {
+		return
+	}
+
+	if data.Shares.IsNull() != data.ShareThreshold.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("share_threshold"), "Incomplete secret sharing configuration",
+			"shares and share_threshold must be set together")
+		return
+	}
+
+	if data.Shares.IsNull() {
+		return
+	}
+
+	shares, threshold := data.Shares.ValueInt64(), data.ShareThreshold.ValueInt64()
+	if shares > 255 {
+		resp.Diagnostics.AddAttributeError(path.Root("shares"), "Invalid number of shares",
+			fmt.Sprintf("at most 255 shares are supported, got %d", shares))
+	}
+	if threshold < 2 || threshold > shares {
+		resp.Diagnostics.AddAttributeError(path.Root("share_threshold"), "Invalid share threshold",
+			fmt.Sprintf("share_threshold must be between 2 and shares (%d), got %d", shares, threshold))
+	}
+}
+
+func (r *Nkey) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	// Nothing to do on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan NkeyModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if strings.EqualFold(plan.KeyType.ValueString(), "curve") {
+		if err := r.provider.checkFIPS("X25519 curve key generation"); err != nil {
+			addError(&resp.Diagnostics, "Operation refused in FIPS mode", errorAt(path.Root("type"), err))
+		}
+	}
+	if !plan.EncryptionPassphrase.IsNull() || !plan.EncryptionRecipient.IsNull() {
+		if err := r.provider.checkFIPS("age encryption"); err != nil {
+			addError(&resp.Diagnostics, "Operation refused in FIPS mode", err)
+		}
+	}
+
+	var derivedSeed string
+	if !plan.DerivationPath.IsNull() && !plan.DerivationPath.IsUnknown() && !plan.KeyType.IsUnknown() {
+		seed, err := r.provider.deriveSeed(plan.prefix(), plan.DerivationPath.ValueString())
+		if err != nil {
+			addError(&resp.Diagnostics, "Unable to derive nkey", errorAt(path.Root("derivation_path"), err))
+		}
+		derivedSeed = seed
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state NkeyModel
+
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	createdAt, expiresAt := plan.planRotation(state)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("created_at"), createdAt)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("expires_at"), expiresAt)...)
+
+	// Nothing to compare against on create
+	if req.State.Raw.IsNull() {
+		return
+	}
+
+	rotating := expired(state.ExpiresAt) && plan.rotatesInPlace()
+	planRotationInPlace(ctx, plan, state, rotating, resp)
+
+	// The key is not replaced when the master seed changes, as that would
+	// replace every derived key at once.
+	if derivedSeed != "" && !state.Seed.IsNull() && derivedSeed != state.Seed.ValueString() {
+		resp.Diagnostics.AddAttributeWarning(path.Root("derivation_path"), "Derived key out of date",
+			fmt.Sprintf("The key %s no longer matches the derivation from the provider master_seed. It is kept as is, "+
+				"replace the resource to derive it again.", state.PublicKey.ValueString()))
+	}
+
+	// The seed itself is kept, only the shares are re-split.
+	if !plan.Shares.Equal(state.Shares) || !plan.ShareThreshold.Equal(state.ShareThreshold) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("seed_shares"), types.ListUnknown(types.StringType))...)
+	}
+
+	// The seed is re-encrypted from state, unless it never was stored.
+	if !plan.EncryptionPassphrase.Equal(state.EncryptionPassphrase) || !plan.EncryptionRecipient.Equal(state.EncryptionRecipient) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("encrypted_private_key"), types.StringUnknown())...)
+
+		if state.Seed.IsNull() {
+			resp.RequiresReplace = append(resp.RequiresReplace, path.Root("encryption_passphrase"), path.Root("encryption_recipient"))
+		}
+	}
+}
+
 func (r *Nkey) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	// Nothing to do here as nkeys are simply generated
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.provider = data
 }
 
 func (r *Nkey) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
 	var data NkeyModel
 
 	// Read Terraform plan data into the model
@@ -90,17 +603,51 @@ func (r *Nkey) Create(ctx context.Context, req resource.CreateRequest, resp *res
 		return
 	}
 
+	if !data.DerivationPath.IsNull() {
+		seed, err := r.provider.deriveSeed(data.prefix(), data.DerivationPath.ValueString())
+		if err != nil {
+			addError(&resp.Diagnostics, "Unable to derive nkey", errorAt(path.Root("derivation_path"), err))
+			return
+		}
+		data.Seed = types.StringValue(seed)
+	}
+
 	if err := data.generateKeys(); err != nil {
-		resp.Diagnostics.AddError("generating nkey", err.Error())
+		addError(&resp.Diagnostics, "Unable to generate nkey", err)
+		return
+	}
+	data.CreatedAt = types.StringValue(time.Now().UTC().Format(time.RFC3339))
+	data.ExpiresAt = data.expiry()
+	data.PreviousPublicKey = types.StringNull()
+	data.PreviousPrivateKey = types.StringNull()
+	data.PreviousSeed = types.StringNull()
+	data.PreviousExpiresAt = types.StringNull()
+	data.RetiredPublicKeys = data.retiredKeys(ctx, NkeyModel{}, false, &resp.Diagnostics)
+	if err := data.splitSeed(); err != nil {
+		addError(&resp.Diagnostics, "Unable to split nkey seed", err)
 		return
 	}
+	if err := data.encryptSeed(); err != nil {
+		addError(&resp.Diagnostics, "Unable to encrypt nkey seed", err)
+		return
+	}
+	if err := data.writeSeedFile(); err != nil {
+		addError(&resp.Diagnostics, "Unable to write nkey seed file", err)
+		return
+	}
+	data.dropPlaintext()
 	tflog.Trace(ctx, "created nkey resource")
 
 	// Save data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	r.provider.notify(ctx, data.event("created"), &resp.Diagnostics)
 }
 
 func (r *Nkey) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
 	var data NkeyModel
 
 	// Read Terraform prior state data into the model
@@ -110,53 +657,329 @@ func (r *Nkey) Read(ctx context.Context, req resource.ReadRequest, resp *resourc
 		return
 	}
 
+	if err := data.checkKeyPair(); err != nil {
+		addError(&resp.Diagnostics, "Inconsistent nkey in state", err)
+		return
+	}
+
+	// State written by older provider versions has no id and key formats yet.
+	data.ID = data.PublicKey
+	if err := data.setKeyFormats(); err != nil {
+		addError(&resp.Diagnostics, "Invalid nkey in state", err)
+		return
+	}
+
+	// An expired key is generated again on the next apply. Keys with a
+	// rotation overlap or key history are rotated in place instead, see
+	// ModifyPlan.
+	if expired(data.ExpiresAt) && !data.rotatesInPlace() {
+		tflog.Info(ctx, "nkey expired, removing it from state", map[string]interface{}{
+			"public_key": data.PublicKey.ValueString(),
+			"expires_at": data.ExpiresAt.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *Nkey) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var plan NkeyModel
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var plan, state NkeyModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	// Keys with a rotation overlap or key history are rotated in place, see
+	// ModifyPlan.
+	if plan.PublicKey.IsUnknown() {
+		if err := plan.rotate(); err != nil {
+			addError(&resp.Diagnostics, "Unable to rotate nkey", err)
+			return
+		}
+		tflog.Info(ctx, "rotated nkey", map[string]interface{}{
+			"public_key":          plan.PublicKey.ValueString(),
+			"previous_public_key": plan.PreviousPublicKey.ValueString(),
+		})
+
+		resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		r.provider.notify(ctx, plan.event("created"), &resp.Diagnostics)
+
+		// Without an overlap the old key pair is retired right away, and a
+		// previous key pair still in its overlap is replaced by it.
+		for _, retired := range []types.String{state.PreviousPublicKey, state.PublicKey} {
+			if !retired.IsNull() && !retired.Equal(plan.PreviousPublicKey) {
+				event := state.event("deleted")
+				event.PublicKey = retired.ValueString()
+				r.provider.notify(ctx, event, &resp.Diagnostics)
+			}
+		}
+		return
+	}
+
+	// The key material never changes in place otherwise
+	plan.ID = state.PublicKey
+	plan.PublicKey = state.PublicKey
+	plan.PrivateKey = state.PrivateKey
+	plan.Seed = state.Seed
+	if err := plan.setKeyFormats(); err != nil {
+		addError(&resp.Diagnostics, "Invalid nkey in state", err)
+		return
+	}
+
+	if plan.CreatedAt.IsUnknown() {
+		plan.CreatedAt = types.StringValue(time.Now().UTC().Format(time.RFC3339))
+	}
+	plan.ExpiresAt = plan.expiry()
+
+	if plan.SeedShares.IsUnknown() {
+		if err := plan.splitSeed(); err != nil {
+			addError(&resp.Diagnostics, "Unable to split nkey seed", err)
+			return
+		}
+	}
+	if plan.EncryptedPrivateKey.IsUnknown() {
+		if err := plan.encryptSeed(); err != nil {
+			addError(&resp.Diagnostics, "Unable to encrypt nkey seed", err)
+			return
+		}
+	}
+
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	// The previous key pair is dropped once the overlap ended.
+	if !state.PreviousPublicKey.IsNull() && plan.PreviousPublicKey.IsNull() {
+		event := state.event("deleted")
+		event.PublicKey = state.PreviousPublicKey.ValueString()
+		r.provider.notify(ctx, event, &resp.Diagnostics)
+	}
 }
 
 func (r *Nkey) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data NkeyModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.provider.notify(ctx, data.event("deleted"), &resp.Diagnostics)
 }
 
+// ImportState imports a key by its seed, the type is taken from the prefix.
 func (r *Nkey) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
-}
-
-func (m *NkeyModel) generateKeys() (err error) {
-	var keys nkeys.KeyPair
-
-	switch strings.ToLower(m.KeyType.ValueString()) {
-	case "user":
-		keys, err = nkeys.CreateUser()
-	case "account":
-		keys, err = nkeys.CreateAccount()
-	case "server":
-		keys, err = nkeys.CreateServer()
-	case "cluster":
-		keys, err = nkeys.CreateCluster()
-	case "operator":
-		keys, err = nkeys.CreateOperator()
-	case "curve":
-		keys, err = nkeys.CreateCurveKeys()
-	default:
-		keys, err = nkeys.CreateAccount()
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	// Decorated .nk files are accepted as well.
+	keys, err := nkeys.ParseDecoratedNKey([]byte(req.ID))
+	if err != nil {
+		keys, err = nkeys.FromSeed([]byte(strings.TrimSpace(req.ID)))
 	}
 	if err != nil {
-		return err
+		resp.Diagnostics.AddError("Invalid import ID", "The import ID must be the seed of an nkey: "+err.Error())
+		return
 	}
+	defer keys.Wipe()
+
+	pubKey, err := keys.PublicKey()
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", "The import ID must be the seed of an nkey: "+err.Error())
+		return
+	}
+	seed, err := keys.Seed()
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", "The import ID must be the seed of an nkey: "+err.Error())
+		return
+	}
+	defer clear(seed)
+
+	data := NkeyModel{
+		KeyType:           types.StringValue(keyTypeName(nkeys.Prefix(pubKey))),
+		Seed:              types.StringValue(string(seed)),
+		SeedShares:        types.ListNull(types.StringType),
+		SuppressPlaintext: types.BoolValue(false),
+		Keepers:           types.MapNull(types.StringType),
+		CreatedAt:         types.StringValue(time.Now().UTC().Format(time.RFC3339)),
+
+		PreviousPublicKey:  types.StringNull(),
+		PreviousPrivateKey: types.StringNull(),
+		PreviousSeed:       types.StringNull(),
+		PreviousExpiresAt:  types.StringNull(),
+		RetiredPublicKeys:  types.ListNull(types.StringType),
+	}
+
+	if err := data.generateKeys(); err != nil {
+		addError(&resp.Diagnostics, "Unable to import nkey", err)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// keyTypes maps the values accepted by the type attribute to nkey prefixes.
+var keyTypes = map[string]nkeys.PrefixByte{
+	"user":     nkeys.PrefixByteUser,
+	"account":  nkeys.PrefixByteAccount,
+	"server":   nkeys.PrefixByteServer,
+	"cluster":  nkeys.PrefixByteCluster,
+	"operator": nkeys.PrefixByteOperator,
+	"curve":    nkeys.PrefixByteCurve,
+}
+
+// keyTypeRequiresReplace replaces the resource when the type changes.
+// Changing only the case keeps the key pair.
+func keyTypeRequiresReplace() planmodifier.String {
+	return stringplanmodifier.RequiresReplaceIf(func(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+		resp.RequiresReplace = !strings.EqualFold(req.StateValue.ValueString(), req.PlanValue.ValueString())
+	}, "Changing the type generates a new key pair", "Changing the type generates a new key pair")
+}
+
+// seedRequiresReplace replaces the resource when a configured seed differs
+// from the seed in state. A generated seed, which is null in state if
+// suppress_plaintext is set, never replaces the key pair.
+func seedRequiresReplace() planmodifier.String {
+	return stringplanmodifier.RequiresReplaceIf(func(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+		resp.RequiresReplace = !req.ConfigValue.IsNull() && !req.ConfigValue.Equal(req.StateValue)
+	}, "Changing the seed replaces the key pair", "Changing the seed replaces the key pair")
+}
+
+// keyTypeName returns the type attribute value for prefix.
+func keyTypeName(prefix nkeys.PrefixByte) string {
+	for name, p := range keyTypes {
+		if p == prefix {
+			return name
+		}
+	}
+	return strings.ToLower(prefix.String())
+}
+
+// event returns the webhook event for the key.
+func (m *NkeyModel) event(name string) webhookEvent {
+	return webhookEvent{
+		Event:     name,
+		Resource:  "nkey_nkey",
+		Type:      keyTypeName(nkeys.Prefix(m.PublicKey.ValueString())),
+		PublicKey: m.PublicKey.ValueString(),
+	}
+}
+
+// prefix returns the nkey prefix of the configured type.
+func (m *NkeyModel) prefix() nkeys.PrefixByte {
+	prefix, ok := keyTypes[strings.ToLower(m.KeyType.ValueString())]
+	if !ok {
+		prefix = nkeys.PrefixByteAccount
+	}
+	return prefix
+}
+
+// checkSeed verifies that the configured seed is valid and of the
+// configured type.
+func (m *NkeyModel) checkSeed() error {
+	keys, err := nkeys.FromSeed([]byte(m.Seed.ValueString()))
+	if err != nil {
+		return errorAt(path.Root("seed"), err)
+	}
+	defer keys.Wipe()
+
+	pubKey, err := keys.PublicKey()
+	if err != nil {
+		return errorAt(path.Root("seed"), err)
+	}
+
+	if prefix := nkeys.Prefix(pubKey); prefix != m.prefix() {
+		return errorAt(path.Root("type"), fmt.Errorf("the seed is of type %s, set type = %q", keyTypeName(prefix), keyTypeName(prefix)))
+	}
+
+	return nil
+}
+
+// checkKeyPair verifies that the key material in state belongs together
+// and matches the configured type, to detect state that was modified or
+// corrupted outside of the provider.
+func (m *NkeyModel) checkKeyPair() error {
+	inconsistent := func(p path.Path, format string, a ...interface{}) error {
+		return errorAt(p, fmt.Errorf(format+". Replace the nkey with `terraform apply -replace` to generate a new key pair", a...))
+	}
+
+	pubKey := m.PublicKey.ValueString()
+	prefix := nkeys.Prefix(pubKey)
+	if prefix == nkeys.PrefixByteUnknown {
+		return inconsistent(path.Root("public_key"), "%q is not a valid public key", pubKey)
+	}
+	if prefix != m.prefix() {
+		return inconsistent(path.Root("public_key"), "the public key is of type %s, not %s", keyTypeName(prefix), keyTypeName(m.prefix()))
+	}
+
+	if m.Seed.IsNull() {
+		return nil
+	}
+
+	keys, err := nkeys.FromSeed([]byte(m.Seed.ValueString()))
+	if err != nil {
+		return inconsistent(path.Root("seed"), "the seed is invalid: %s", err)
+	}
+	defer keys.Wipe()
+
+	seedPubKey, err := keys.PublicKey()
+	if err != nil {
+		return inconsistent(path.Root("seed"), "the seed is invalid: %s", err)
+	}
+	if seedPubKey != pubKey {
+		return inconsistent(path.Root("seed"), "the seed belongs to %s, not to the public key %s", seedPubKey, pubKey)
+	}
+
+	if !m.PrivateKey.IsNull() {
+		privKey, err := keys.PrivateKey()
+		if err != nil {
+			return inconsistent(path.Root("private_key"), "the private key cannot be derived from the seed: %s", err)
+		}
+		defer clear(privKey)
+		if string(privKey) != m.PrivateKey.ValueString() {
+			return inconsistent(path.Root("private_key"), "the private key does not belong to the public key %s", pubKey)
+		}
+	}
+
+	return nil
+}
+
+// generateKeys fills the key attributes from the configured seed, or from
+// a newly generated key pair if no seed is configured.
+func (m *NkeyModel) generateKeys() error {
+	var (
+		keys nkeys.KeyPair
+		err  error
+	)
+
+	if !m.Seed.IsNull() && !m.Seed.IsUnknown() {
+		if err := m.checkSeed(); err != nil {
+			return err
+		}
+		keys, err = nkeys.FromSeed([]byte(m.Seed.ValueString()))
+		if err != nil {
+			return errorAt(path.Root("seed"), err)
+		}
+	} else {
+		keys, err = createKeyPair(m.prefix())
+		if err != nil {
+			return errorAt(path.Root("type"), err)
+		}
+	}
+	defer keys.Wipe()
 
 	pubKey, err := keys.PublicKey()
 	if err != nil {
@@ -166,14 +989,239 @@ func (m *NkeyModel) generateKeys() (err error) {
 	if err != nil {
 		return err
 	}
+	defer clear(privKey)
 	seed, err := keys.Seed()
 	if err != nil {
 		return err
 	}
+	defer clear(seed)
 
+	m.ID = types.StringValue(pubKey)
 	m.PublicKey = types.StringValue(pubKey)
 	m.PrivateKey = types.StringValue(string(privKey))
 	m.Seed = types.StringValue(string(seed))
 
+	return m.setKeyFormats()
+}
+
+// writeSeedFile writes the seed to the configured seed file, if any.
+func (m *NkeyModel) writeSeedFile() error {
+	if m.SeedFile.IsNull() {
+		return nil
+	}
+
+	content := decorateSeed(m.Seed.ValueString(), nkeys.Prefix(m.PublicKey.ValueString()))
+	if err := os.WriteFile(m.SeedFile.ValueString(), []byte(content), 0o600); err != nil {
+		return errorAt(path.Root("seed_file"), err)
+	}
+
+	return nil
+}
+
+// planRotation returns the planned created_at and expires_at. Keys created
+// by older provider versions have no creation time, it is only set once
+// rotation is enabled.
+func (m *NkeyModel) planRotation(state NkeyModel) (createdAt, expiresAt types.String) {
+	createdAt = state.CreatedAt
+	if state.PublicKey.IsNull() || (createdAt.IsNull() && !m.RotationDays.IsNull()) {
+		createdAt = types.StringUnknown()
+	}
+
+	if createdAt.IsUnknown() && !m.RotationDays.IsNull() {
+		return createdAt, types.StringUnknown()
+	}
+
+	m.CreatedAt = createdAt
+	return createdAt, m.expiry()
+}
+
+// keyAttributes are the attributes of the key pair, which are generated
+// again on rotation.
+var keyAttributes = []string{
+	"id", "public_key", "private_key", "seed", "fingerprint",
+	"public_key_pem", "private_key_pem", "public_key_jwk", "private_key_jwk",
+	"public_key_openssh", "private_key_openssh",
+	"public_key_hex", "public_key_base64", "private_key_hex", "private_key_base64",
+}
+
+// rotatesInPlace reports whether an expired key is rotated in place rather
+// than removed from state, to keep its previous keys.
+func (m *NkeyModel) rotatesInPlace() bool {
+	return !m.RotationOverlapDays.IsNull() || !m.KeyHistory.IsNull()
+}
+
+// planRotationInPlace plans the rotation in place of an expired key if
+// rotating, keeping the key pair in state as previous key pair if there is a
+// rotation overlap, or the removal of the previous key pair once the overlap
+// ended. The key in state is added to the retired keys on rotation.
+func planRotationInPlace(ctx context.Context, plan, state NkeyModel, rotating bool, resp *resource.ModifyPlanResponse) {
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("retired_public_keys"), plan.retiredKeys(ctx, state, rotating, &resp.Diagnostics))...)
+
+	if rotating {
+		for _, name := range keyAttributes {
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root(name), types.StringUnknown())...)
+		}
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("created_at"), types.StringUnknown())...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("expires_at"), types.StringUnknown())...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("seed_shares"), types.ListUnknown(types.StringType))...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("encrypted_private_key"), types.StringUnknown())...)
+
+		if !plan.RotationOverlapDays.IsNull() {
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("previous_public_key"), state.PublicKey)...)
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("previous_private_key"), state.PrivateKey)...)
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("previous_seed"), state.Seed)...)
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("previous_expires_at"), types.StringUnknown())...)
+			return
+		}
+	}
+
+	if rotating || expired(state.PreviousExpiresAt) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("previous_public_key"), types.StringNull())...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("previous_private_key"), types.StringNull())...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("previous_seed"), types.StringNull())...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("previous_expires_at"), types.StringNull())...)
+	}
+}
+
+// rotate generates a new key pair in place of the current one, which is
+// already planned as previous key pair, and secures its seed as on create.
+func (m *NkeyModel) rotate() error {
+	m.Seed = types.StringNull()
+	if err := m.generateKeys(); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	m.CreatedAt = types.StringValue(now.Format(time.RFC3339))
+	m.ExpiresAt = m.expiry()
+	if !m.RotationOverlapDays.IsNull() {
+		m.PreviousExpiresAt = types.StringValue(now.AddDate(0, 0, int(m.RotationOverlapDays.ValueInt64())).Format(time.RFC3339))
+	}
+
+	if err := m.splitSeed(); err != nil {
+		return err
+	}
+	if err := m.encryptSeed(); err != nil {
+		return err
+	}
+	if err := m.writeSeedFile(); err != nil {
+		return err
+	}
+	m.dropPlaintext()
+
+	return nil
+}
+
+// retiredKeys returns the planned retired public keys: the key in state if
+// rotating, followed by the keys retired before, up to key_history.
+func (m *NkeyModel) retiredKeys(ctx context.Context, state NkeyModel, rotating bool, diags *diag.Diagnostics) types.List {
+	if m.KeyHistory.IsNull() {
+		return types.ListNull(types.StringType)
+	}
+	if m.KeyHistory.IsUnknown() {
+		return types.ListUnknown(types.StringType)
+	}
+
+	keys := []string{}
+	if !state.RetiredPublicKeys.IsNull() {
+		diags.Append(state.RetiredPublicKeys.ElementsAs(ctx, &keys, false)...)
+	}
+	if rotating {
+		keys = append([]string{state.PublicKey.ValueString()}, keys...)
+	}
+	if n := int(m.KeyHistory.ValueInt64()); len(keys) > n {
+		keys = keys[:n]
+	}
+
+	list, d := types.ListValueFrom(ctx, types.StringType, keys)
+	diags.Append(d...)
+	return list
+}
+
+// dropPlaintext clears the private key formats and the seed if
+// suppress_plaintext is set.
+func (m *NkeyModel) dropPlaintext() {
+	if !m.SuppressPlaintext.ValueBool() {
+		return
+	}
+
+	m.PrivateKey = types.StringNull()
+	m.PrivateKeyPEM = types.StringNull()
+	m.PrivateKeyJWK = types.StringNull()
+	m.PrivateKeyOpenSSH = types.StringNull()
+	m.PrivateKeyHex = types.StringNull()
+	m.PrivateKeyBase64 = types.StringNull()
+	m.Seed = types.StringNull()
+}
+
+// expired reports whether the RFC 3339 timestamp v has passed. Null
+// timestamps never expire.
+func expired(v types.String) bool {
+	t, err := time.Parse(time.RFC3339, v.ValueString())
+	return err == nil && !time.Now().Before(t)
+}
+
+// expiry returns the expiry time of the key, or null if it does not expire.
+func (m *NkeyModel) expiry() types.String {
+	if m.RotationDays.IsNull() {
+		return types.StringNull()
+	}
+	if m.RotationDays.IsUnknown() {
+		return types.StringUnknown()
+	}
+
+	createdAt, err := time.Parse(time.RFC3339, m.CreatedAt.ValueString())
+	if err != nil {
+		return types.StringUnknown()
+	}
+
+	return types.StringValue(createdAt.AddDate(0, 0, int(m.RotationDays.ValueInt64())).Format(time.RFC3339))
+}
+
+// splitSeed fills SeedShares with Shamir secret shares of the seed, or
+// clears it when no sharing is configured.
+func (m *NkeyModel) splitSeed() error {
+	if m.Shares.IsNull() {
+		m.SeedShares = types.ListNull(types.StringType)
+		return nil
+	}
+
+	seed := []byte(m.Seed.ValueString())
+	defer clear(seed)
+
+	shares, err := shamir.Split(seed, int(m.Shares.ValueInt64()), int(m.ShareThreshold.ValueInt64()), rand.Reader)
+	if err != nil {
+		return errorAt(path.Root("shares"), err)
+	}
+
+	values := make([]attr.Value, len(shares))
+	for i, share := range shares {
+		values[i] = types.StringValue(hex.EncodeToString(share))
+		clear(share)
+	}
+
+	m.SeedShares = types.ListValueMust(types.StringType, values)
+
+	return nil
+}
+
+// encryptSeed fills EncryptedPrivateKey with the encrypted seed, or clears
+// it when no encryption is configured.
+func (m *NkeyModel) encryptSeed() error {
+	if m.EncryptionPassphrase.IsNull() && m.EncryptionRecipient.IsNull() {
+		m.EncryptedPrivateKey = types.StringNull()
+		return nil
+	}
+
+	seed := []byte(m.Seed.ValueString())
+	defer clear(seed)
+
+	encrypted, err := encryptSeed(seed, m.EncryptionPassphrase.ValueString(), m.EncryptionRecipient.ValueString())
+	if err != nil {
+		return err
+	}
+
+	m.EncryptedPrivateKey = types.StringValue(encrypted)
+
 	return nil
 }
@@ -0,0 +1,208 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/nkeys"
+)
+
+func TestNkeyImport(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	keys, err := nkeys.CreateOperator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey, _ := keys.PublicKey()
+	seed, _ := keys.Seed()
+
+	tests := map[string]string{
+		"seed":      string(seed),
+		"decorated": decorateSeed(string(seed), nkeys.PrefixByteOperator),
+	}
+
+	for name, id := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := p.importState("nkey_nkey", id)
+
+			if got := stringAttribute(t, state, "public_key"); got != pubKey {
+				t.Errorf("public_key = %q, want %q", got, pubKey)
+			}
+			if got := stringAttribute(t, state, "type"); got != "operator" {
+				t.Errorf("type = %q, want operator", got)
+			}
+			if !attribute(t, state, "retired_public_keys").IsNull() {
+				t.Error("retired_public_keys not null")
+			}
+
+			// The imported state plans without changes to the key.
+			resp := p.plan("nkey_nkey", state, map[string]tftypes.Value{"type": stringValue("operator")})
+			checkDiagnostics(t, resp.Diagnostics)
+			if len(resp.RequiresReplace) != 0 {
+				t.Errorf("imported key is replaced: %v", resp.RequiresReplace)
+			}
+		})
+	}
+}
+
+func TestNkeySuppressPlaintextUpdateInPlace(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	config := map[string]tftypes.Value{
+		"type":                  stringValue("user"),
+		"suppress_plaintext":    boolValue(true),
+		"encryption_passphrase": stringValue("correct horse battery staple"),
+	}
+	state := p.create("nkey_nkey", config)
+	if !attribute(t, state, "seed").IsNull() {
+		t.Fatal("seed stored in state despite suppress_plaintext")
+	}
+
+	// Unrelated attributes change in place, the key is kept.
+	for name, value := range map[string]tftypes.Value{
+		"rotation_days": numberValue(30),
+		"key_history":   numberValue(3),
+	} {
+		config[name] = value
+	}
+
+	resp := p.plan("nkey_nkey", state, config)
+	checkDiagnostics(t, resp.Diagnostics)
+	if len(resp.RequiresReplace) != 0 {
+		t.Fatalf("key is replaced: %v", resp.RequiresReplace)
+	}
+
+	updated := p.apply("nkey_nkey", state, config)
+	if got, want := stringAttribute(t, updated, "public_key"), stringAttribute(t, state, "public_key"); got != want {
+		t.Errorf("public_key = %q, want %q", got, want)
+	}
+	for _, name := range []string{"seed", "private_key", "private_key_pem"} {
+		if !attribute(t, updated, name).IsNull() {
+			t.Errorf("%s stored in state despite suppress_plaintext", name)
+		}
+	}
+}
+
+func TestNkeySeedChangeRequiresReplace(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	first, _ := nkeys.CreateUser()
+	second, _ := nkeys.CreateUser()
+	firstSeed, _ := first.Seed()
+	secondSeed, _ := second.Seed()
+
+	config := map[string]tftypes.Value{
+		"type": stringValue("user"),
+		"seed": stringValue(string(firstSeed)),
+	}
+	state := p.create("nkey_nkey", config)
+
+	tests := map[string]struct {
+		seed    tftypes.Value
+		replace bool
+	}{
+		"unchanged": {seed: stringValue(string(firstSeed))},
+		"removed":   {seed: tftypes.NewValue(tftypes.String, nil)},
+		"changed":   {seed: stringValue(string(secondSeed)), replace: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := p.plan("nkey_nkey", state, map[string]tftypes.Value{
+				"type": stringValue("user"),
+				"seed": test.seed,
+			})
+			checkDiagnostics(t, resp.Diagnostics)
+			if got := len(resp.RequiresReplace) != 0; got != test.replace {
+				t.Errorf("replace = %v, want %v (%v)", got, test.replace, resp.RequiresReplace)
+			}
+		})
+	}
+}
+
+func TestNkeyType(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	valid := map[string]nkeys.PrefixByte{
+		"user":     nkeys.PrefixByteUser,
+		"account":  nkeys.PrefixByteAccount,
+		"server":   nkeys.PrefixByteServer,
+		"cluster":  nkeys.PrefixByteCluster,
+		"operator": nkeys.PrefixByteOperator,
+		"curve":    nkeys.PrefixByteCurve,
+		"Operator": nkeys.PrefixByteOperator,
+		"USER":     nkeys.PrefixByteUser,
+	}
+
+	for keyType, prefix := range valid {
+		t.Run(keyType, func(t *testing.T) {
+			config := map[string]tftypes.Value{"type": stringValue(keyType)}
+			checkDiagnostics(t, p.validate("nkey_nkey", config))
+
+			state := p.create("nkey_nkey", config)
+			if got := nkeys.Prefix(stringAttribute(t, state, "public_key")); got != prefix {
+				t.Errorf("public key prefix = %s, want %s", got, prefix)
+			}
+		})
+	}
+
+	t.Run("default", func(t *testing.T) {
+		state := p.create("nkey_nkey", nil)
+		if got := stringAttribute(t, state, "type"); got != "account" {
+			t.Errorf("type = %q, want account", got)
+		}
+		if got := nkeys.Prefix(stringAttribute(t, state, "public_key")); got != nkeys.PrefixByteAccount {
+			t.Errorf("public key prefix = %s, want %s", got, nkeys.PrefixByteAccount)
+		}
+	})
+
+	for _, keyType := range []string{"", "usr", "accounts", "ed25519", "x25519", " user", "private"} {
+		t.Run("invalid "+keyType, func(t *testing.T) {
+			diags := p.validate("nkey_nkey", map[string]tftypes.Value{"type": stringValue(keyType)})
+			if !hasError(diags, "Invalid Attribute Value Match") {
+				t.Fatalf("expected a validation error, got %v", diags)
+			}
+			for _, d := range diags {
+				if d.Attribute == nil || !d.Attribute.Equal(tftypes.NewAttributePath().WithAttributeName("type")) {
+					t.Errorf("diagnostic at %v, want type", d.Attribute)
+				}
+				if !strings.Contains(d.Detail, `"`+keyType+`"`) {
+					t.Errorf("diagnostic %q does not name the invalid value", d.Detail)
+				}
+				for _, allowed := range []string{"user", "account", "server", "cluster", "operator", "curve"} {
+					if !strings.Contains(d.Detail, `"`+allowed+`"`) {
+						t.Errorf("diagnostic %q does not list %q", d.Detail, allowed)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestNkeyTypeChange(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	state := p.create("nkey_nkey", map[string]tftypes.Value{"type": stringValue("user")})
+
+	tests := map[string]bool{
+		"user":    false,
+		"USER":    false,
+		"account": true,
+	}
+
+	for keyType, replace := range tests {
+		t.Run(keyType, func(t *testing.T) {
+			resp := p.plan("nkey_nkey", state, map[string]tftypes.Value{"type": stringValue(keyType)})
+			checkDiagnostics(t, resp.Diagnostics)
+			if got := len(resp.RequiresReplace) != 0; got != replace {
+				t.Errorf("replace = %v, want %v", got, replace)
+			}
+		})
+	}
+}
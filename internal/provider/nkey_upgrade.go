@@ -0,0 +1,118 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// nkeyStateV0 is the state of nkey_nkey before the schema was versioned.
+// Attributes were added over time without a version bump, so any of them
+// may be missing.
+type nkeyStateV0 struct {
+	KeyType    *string `json:"type"`
+	PublicKey  *string `json:"public_key"`
+	PrivateKey *string `json:"private_key"`
+	Seed       *string `json:"seed"`
+
+	Shares         *int64   `json:"shares"`
+	ShareThreshold *int64   `json:"share_threshold"`
+	SeedShares     []string `json:"seed_shares"`
+
+	EncryptionPassphrase *string `json:"encryption_passphrase"`
+	EncryptionRecipient  *string `json:"encryption_recipient"`
+	EncryptedPrivateKey  *string `json:"encrypted_private_key"`
+	SuppressPlaintext    *bool   `json:"suppress_plaintext"`
+
+	Keepers map[string]string `json:"keepers"`
+
+	RotationDays *int64  `json:"rotation_days"`
+	CreatedAt    *string `json:"created_at"`
+	ExpiresAt    *string `json:"expires_at"`
+}
+
+func (r *Nkey) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {
+			StateUpgrader: r.upgradeStateV0,
+		},
+	}
+}
+
+// upgradeStateV0 fills the attributes that can be derived from the key
+// pair and defaults the rest, so the first plan after an upgrade does not
+// show spurious changes.
+func (r *Nkey) upgradeStateV0(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var prior nkeyStateV0
+	if err := json.Unmarshal(req.RawState.JSON, &prior); err != nil {
+		resp.Diagnostics.AddError("Unable to upgrade nkey state", err.Error())
+		return
+	}
+
+	data := NkeyModel{
+		ID:         types.StringPointerValue(prior.PublicKey),
+		KeyType:    types.StringPointerValue(prior.KeyType),
+		PublicKey:  types.StringPointerValue(prior.PublicKey),
+		PrivateKey: types.StringPointerValue(prior.PrivateKey),
+		Seed:       types.StringPointerValue(prior.Seed),
+
+		Shares:         types.Int64PointerValue(prior.Shares),
+		ShareThreshold: types.Int64PointerValue(prior.ShareThreshold),
+		SeedShares:     types.ListNull(types.StringType),
+
+		EncryptionPassphrase: types.StringPointerValue(prior.EncryptionPassphrase),
+		EncryptionRecipient:  types.StringPointerValue(prior.EncryptionRecipient),
+		EncryptedPrivateKey:  types.StringPointerValue(prior.EncryptedPrivateKey),
+		SuppressPlaintext:    types.BoolValue(prior.SuppressPlaintext != nil && *prior.SuppressPlaintext),
+
+		Keepers: types.MapNull(types.StringType),
+
+		RotationDays: types.Int64PointerValue(prior.RotationDays),
+		CreatedAt:    types.StringPointerValue(prior.CreatedAt),
+		ExpiresAt:    types.StringPointerValue(prior.ExpiresAt),
+
+		PreviousPublicKey:  types.StringNull(),
+		PreviousPrivateKey: types.StringNull(),
+		PreviousSeed:       types.StringNull(),
+		PreviousExpiresAt:  types.StringNull(),
+		RetiredPublicKeys:  types.ListNull(types.StringType),
+	}
+
+	// The type defaulted to account before it was recorded in state.
+	if data.KeyType.IsNull() {
+		data.KeyType = types.StringValue(keyTypeName(data.prefix()))
+	}
+
+	if prior.SeedShares != nil {
+		value, d := types.ListValueFrom(ctx, types.StringType, prior.SeedShares)
+		resp.Diagnostics.Append(d...)
+		data.SeedShares = value
+	}
+	if prior.Keepers != nil {
+		value, d := types.MapValueFrom(ctx, types.StringType, prior.Keepers)
+		resp.Diagnostics.Append(d...)
+		data.Keepers = value
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := data.checkKeyPair(); err != nil {
+		addError(&resp.Diagnostics, "Inconsistent nkey in state", err)
+		return
+	}
+	if err := data.setKeyFormats(); err != nil {
+		addError(&resp.Diagnostics, "Unable to upgrade nkey state", err)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,111 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+
+	"github.com/nats-io/nkeys"
+)
+
+func TestNkeyUpgradeStateV0(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	keys, err := nkeys.CreateUser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey, _ := keys.PublicKey()
+	privKey, _ := keys.PrivateKey()
+	seed, _ := keys.Seed()
+
+	tests := map[string]struct {
+		state    map[string]interface{}
+		wantType string
+	}{
+		"baseline": {
+			state: map[string]interface{}{
+				"type":        "user",
+				"public_key":  pubKey,
+				"private_key": string(privKey),
+				"seed":        string(seed),
+			},
+			wantType: "user",
+		},
+		"shares and keepers": {
+			state: map[string]interface{}{
+				"type":            "user",
+				"public_key":      pubKey,
+				"private_key":     string(privKey),
+				"seed":            string(seed),
+				"shares":          3,
+				"share_threshold": 2,
+				"seed_shares":     []string{"01", "02", "03"},
+				"keepers":         map[string]string{"a": "b"},
+				"rotation_days":   30,
+				"created_at":      "2024-01-01T00:00:00Z",
+				"expires_at":      "2024-01-31T00:00:00Z",
+			},
+			wantType: "user",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			raw, err := json.Marshal(test.state)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			state := p.upgrade("nkey_nkey", 0, string(raw))
+
+			if got := stringAttribute(t, state, "id"); got != pubKey {
+				t.Errorf("id = %q, want %q", got, pubKey)
+			}
+			if got := stringAttribute(t, state, "type"); got != test.wantType {
+				t.Errorf("type = %q, want %q", got, test.wantType)
+			}
+			if stringAttribute(t, state, "public_key_pem") == "" {
+				t.Error("public_key_pem not set")
+			}
+			if !attribute(t, state, "retired_public_keys").IsNull() {
+				t.Error("retired_public_keys not null")
+			}
+			if !attribute(t, state, "previous_public_key").IsNull() {
+				t.Error("previous_public_key not null")
+			}
+		})
+	}
+}
+
+func TestNkeyUpgradeStateV0Inconsistent(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	account, _ := nkeys.CreateAccount()
+	user, _ := nkeys.CreateUser()
+	pubKey, _ := account.PublicKey()
+	seed, _ := user.Seed()
+
+	raw, _ := json.Marshal(map[string]interface{}{
+		"type":       "account",
+		"public_key": pubKey,
+		"seed":       string(seed),
+	})
+
+	resp, err := p.server.UpgradeResourceState(context.Background(), &tfprotov6.UpgradeResourceStateRequest{
+		TypeName: "nkey_nkey",
+		Version:  0,
+		RawState: &tfprotov6.RawState{JSON: raw},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hasError(resp.Diagnostics, "Inconsistent nkey in state") {
+		t.Errorf("expected an inconsistent state error, got %v", resp.Diagnostics)
+	}
+}
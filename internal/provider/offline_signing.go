@@ -0,0 +1,203 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// offlineKeys is the public key of an issuer whose seed never leaves an
+// offline machine. It signs with an empty signature, so JWTs encoded with it
+// are signing requests: `header.payload.` without the signature.
+type offlineKeys struct {
+	nkeys.KeyPair
+}
+
+func (k offlineKeys) Sign(input []byte) ([]byte, error) {
+	return nil, nil
+}
+
+// offlineSigner returns the offline keys of the public key at p, which must
+// be of one of the given types. Surrounding whitespace, such as the newline
+// of a key read from a file, is ignored.
+//
+// Only the operator and account JWTs can be signed offline: user JWTs are
+// signed by the account or its signing keys, which are kept online to issue
+// them.
+func offlineSigner(pubKey string, p path.Path, prefixes ...nkeys.PrefixByte) (nkeys.KeyPair, error) {
+	pubKey = strings.TrimSpace(pubKey)
+	keys, err := nkeys.FromPublicKey(pubKey)
+	if err != nil {
+		return nil, errorAt(p, err)
+	}
+
+	names := make([]string, len(prefixes))
+	for i, prefix := range prefixes {
+		if nkeys.Prefix(pubKey) == prefix {
+			return offlineKeys{keys}, nil
+		}
+		names[i] = keyTypeName(prefix)
+	}
+
+	return nil, errorAt(p, fmt.Errorf("the public key is of type %s, expected %s", keyTypeName(nkeys.Prefix(pubKey)), strings.Join(names, " or ")))
+}
+
+// issuerSigner returns the key pair of the seed at seedPath or, if it is
+// null, the offline keys of the public key at pubKeyPath. The caller must
+// wipe the key pair.
+func issuerSigner(seed types.String, seedPath path.Path, pubKey types.String, pubKeyPath path.Path, prefixes ...nkeys.PrefixByte) (nkeys.KeyPair, error) {
+	if seed.IsNull() {
+		return offlineSigner(pubKey.ValueString(), pubKeyPath, prefixes...)
+	}
+	return jwtSigner(seed.ValueString(), seedPath, prefixes...)
+}
+
+// offlineJWT returns the JWT signed offline and the path it is set at: the
+// signed_jwt or, if signature is set, the signing request with the signature
+// appended.
+func offlineJWT(request, signed, signature types.String) (types.String, path.Path) {
+	if signature.IsNull() || request.IsNull() {
+		return signed, path.Root("signed_jwt")
+	}
+
+	// Signing tools print standard base64 as often as the base64url
+	// encoding JWTs use.
+	sig := strings.TrimSpace(signature.ValueString())
+	for _, enc := range []*base64.Encoding{base64.RawURLEncoding, base64.URLEncoding, base64.StdEncoding, base64.RawStdEncoding} {
+		if b, err := enc.DecodeString(sig); err == nil {
+			sig = base64.RawURLEncoding.EncodeToString(b)
+			break
+		}
+	}
+	return types.StringValue(request.ValueString() + sig), path.Root("signature")
+}
+
+// signingRequestHash returns the hex encoded SHA-256 hash of the signing
+// input `header.payload` of the signing request, or null if it is.
+func signingRequestHash(request types.String) types.String {
+	if request.IsNull() || request.IsUnknown() {
+		return types.StringNull()
+	}
+	sum := sha256.Sum256([]byte(strings.TrimSuffix(request.ValueString(), ".")))
+	return types.StringValue(hex.EncodeToString(sum[:]))
+}
+
+// errClaimsChanged is returned by signedJWT for a JWT signed for other
+// claims than those of the signing request, usually an outdated one.
+var errClaimsChanged = errors.New("the JWT does not carry the claims of signing_request")
+
+// signedJWT returns signed, set at p, if it is a JWT with the claims of the
+// signing request, signed by its issuer, or null if it is not set yet.
+func signedJWT(request, signed types.String, p path.Path) (types.String, error) {
+	if signed.IsNull() {
+		return types.StringNull(), nil
+	}
+
+	claims, err := jwt.Decode(signed.ValueString())
+	if err != nil {
+		return types.StringNull(), errorAt(p, fmt.Errorf("invalid JWT: %w", err))
+	}
+
+	var requested struct {
+		Issuer string `json:"iss"`
+	}
+	if err := jwtClaims(request.ValueString(), &requested); err != nil {
+		return types.StringNull(), errorAt(path.Root("signing_request"), fmt.Errorf("invalid signing request: %w", err))
+	}
+	if issuer := claims.Claims().Issuer; issuer != requested.Issuer {
+		return types.StringNull(), errorAt(p,
+			fmt.Errorf("the JWT is signed by %s instead of the issuer %s of signing_request", issuer, requested.Issuer))
+	}
+
+	if !sameClaims(signed, request, true, true) {
+		return types.StringNull(), errorAt(p, errClaimsChanged)
+	}
+
+	return signed, nil
+}
+
+// awaitSignature returns the signed JWT of the signing request, set at p, or
+// null with a warning while it has not been signed yet or was signed for
+// claims that changed since. A JWT that is invalid or signed by another key
+// is an error.
+func awaitSignature(request, signed types.String, p path.Path, diags *diag.Diagnostics) types.String {
+	token, err := signedJWT(request, signed, p)
+	switch {
+	case errors.Is(err, errClaimsChanged):
+		diags.AddAttributeWarning(p, "Signed JWT is outdated",
+			"The claims changed since the JWT was signed. Sign the new signing_request offline and set signed_jwt to "+
+				"the result or signature to its signature.")
+	case err != nil:
+		addError(diags, "Invalid signed JWT", err)
+	case token.IsNull():
+		diags.AddAttributeWarning(p, "JWT awaits offline signature",
+			"Sign signing_request offline and set signed_jwt to the result or signature to its signature, the jwt "+
+				"attribute is empty until then.")
+	}
+	return token
+}
+
+// signingRequestAttribute returns the signing_request attribute of the JWT
+// resources that can be signed offline.
+func signingRequestAttribute() schema.StringAttribute {
+	return schema.StringAttribute{
+		Computed: true,
+		MarkdownDescription: "The unsigned JWT, `header.payload.`, if the issuer seed is not set. Sign `header.payload` " +
+			"with the issuer seed on the offline machine, append the base64url encoded signature and set `signed_jwt` " +
+			"to the result, or set `signature` to the signature alone. Only changes if the claims change",
+	}
+}
+
+// signingRequestHashAttribute returns the signing_request_sha256 attribute
+// of the JWT resources that can be signed offline.
+func signingRequestHashAttribute() schema.StringAttribute {
+	return schema.StringAttribute{
+		Computed: true,
+		MarkdownDescription: "Hex encoded SHA-256 hash of `header.payload` of `signing_request`, the exact input the " +
+			"offline machine signs, to compare in the signing ceremony. Only changes if the claims change",
+	}
+}
+
+// signedJWTAttribute returns the signed_jwt attribute of the JWT resources
+// that can be signed offline, which conflicts with the seed attribute.
+func signedJWTAttribute(seed string) schema.StringAttribute {
+	return schema.StringAttribute{
+		Optional: true,
+		MarkdownDescription: "The `signing_request` signed offline. It is verified to be signed by the issuer and to " +
+			"carry the planned claims before it is used as `jwt`",
+		Validators: []validator.String{
+			stringvalidator.ConflictsWith(path.MatchRoot(seed)),
+		},
+	}
+}
+
+// signatureAttribute returns the signature attribute of the JWT resources
+// that can be signed offline, which conflicts with the seed and signed_jwt
+// attributes.
+func signatureAttribute(seed string) schema.StringAttribute {
+	return schema.StringAttribute{
+		Optional: true,
+		MarkdownDescription: "Base64 encoded Ed25519 signature of `header.payload` of `signing_request` made offline, " +
+			"instead of `signed_jwt`, for signing tools that only output the signature. The JWT assembled from " +
+			"`signing_request` and the signature is verified like `signed_jwt`",
+		Validators: []validator.String{
+			stringvalidator.ConflictsWith(path.MatchRoot(seed), path.MatchRoot("signed_jwt")),
+		},
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestSignedJWT(t *testing.T) {
+	operator, _ := nkeys.CreateOperator()
+	other, _ := nkeys.CreateOperator()
+	pubKey, _ := operator.PublicKey()
+
+	encode := func(name string, keys nkeys.KeyPair) string {
+		claims := jwt.NewOperatorClaims(pubKey)
+		claims.Name = name
+		token, err := claims.Encode(keys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	signed := encode("operator", operator)
+	request := types.StringValue(signed[:strings.LastIndex(signed, ".")+1])
+
+	tests := map[string]struct {
+		signed types.String
+		// err is part of the expected error of signedJWT, empty for none.
+		err     string
+		changed bool
+		// warning and errSummary are the expected diagnostics of
+		// awaitSignature.
+		warning    string
+		errSummary string
+	}{
+		"not signed yet": {
+			signed:  types.StringNull(),
+			warning: "JWT awaits offline signature",
+		},
+		"signed": {
+			signed: types.StringValue(signed),
+		},
+		"malformed": {
+			signed:     types.StringValue("not a JWT"),
+			err:        "invalid JWT",
+			errSummary: "Invalid signed JWT",
+		},
+		"bad signature": {
+			signed:     types.StringValue(signed[:len(signed)-4] + "AAAA"),
+			err:        "invalid JWT",
+			errSummary: "Invalid signed JWT",
+		},
+		"other issuer": {
+			signed:     types.StringValue(encode("operator", other)),
+			err:        "instead of the issuer " + pubKey,
+			errSummary: "Invalid signed JWT",
+		},
+		"claims changed": {
+			signed:  types.StringValue(encode("renamed", operator)),
+			err:     errClaimsChanged.Error(),
+			changed: true,
+			warning: "Signed JWT is outdated",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			token, err := signedJWT(request, test.signed, path.Root("signed_jwt"))
+			switch {
+			case test.err == "" && err != nil:
+				t.Fatalf("unexpected error: %s", err)
+			case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+				t.Fatalf("error = %v, want %q", err, test.err)
+			case errors.Is(err, errClaimsChanged) != test.changed:
+				t.Errorf("errors.Is(err, errClaimsChanged) = %t, want %t", !test.changed, test.changed)
+			}
+			if err == nil && !token.Equal(test.signed) {
+				t.Errorf("token = %s, want %s", token, test.signed)
+			}
+
+			var diags diag.Diagnostics
+			token = awaitSignature(request, test.signed, path.Root("signed_jwt"), &diags)
+			if test.err != "" || test.signed.IsNull() {
+				if !token.IsNull() {
+					t.Errorf("awaitSignature = %s, want null", token)
+				}
+			}
+
+			var warnings, errs []string
+			for _, d := range diags.Warnings() {
+				warnings = append(warnings, d.Summary())
+			}
+			for _, d := range diags.Errors() {
+				errs = append(errs, d.Summary())
+			}
+			if got := strings.Join(warnings, ", "); got != test.warning {
+				t.Errorf("warnings = %q, want %q", got, test.warning)
+			}
+			if got := strings.Join(errs, ", "); got != test.errSummary {
+				t.Errorf("errors = %q, want %q", got, test.errSummary)
+			}
+		})
+	}
+}
+
+func TestOfflineSignature(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	operator, _ := nkeys.CreateOperator()
+	operatorKey, _ := operator.PublicKey()
+	seed, _ := operator.Seed()
+	account, _ := nkeys.CreateAccount()
+	accountKey, _ := account.PublicKey()
+
+	config := map[string]tftypes.Value{
+		"public_key": stringValue(accountKey),
+		"issuer":     stringValue(operatorKey),
+		"name":       stringValue("billing"),
+	}
+	state := p.create("nkey_account_jwt", config)
+
+	request := stringAttribute(t, state, "signing_request")
+	input := strings.TrimSuffix(request, ".")
+	sum := sha256.Sum256([]byte(input))
+	if got := stringAttribute(t, state, "signing_request_sha256"); got != hex.EncodeToString(sum[:]) {
+		t.Errorf("signing_request_sha256 = %s, want the hash of %s", got, input)
+	}
+
+	// The ceremony signs the input with the seed that never reaches
+	// Terraform.
+	kp, _ := nkeys.FromSeed(seed)
+	sig, err := kp.Sign([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, encoding := range map[string]*base64.Encoding{
+		"base64url": base64.RawURLEncoding,
+		"base64":    base64.StdEncoding,
+	} {
+		t.Run(name, func(t *testing.T) {
+			config["signature"] = stringValue(encoding.EncodeToString(sig) + "\n")
+			signed := p.apply("nkey_account_jwt", state, config)
+
+			if got := stringAttribute(t, signed, "signing_request"); got != request {
+				t.Errorf("signing_request changed to %s", got)
+			}
+			claims, err := jwt.DecodeAccountClaims(stringAttribute(t, signed, "jwt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims.Issuer != operatorKey || claims.Subject != accountKey {
+				t.Errorf("JWT of %s issued by %s", claims.Subject, claims.Issuer)
+			}
+		})
+	}
+
+	other, _ := nkeys.CreateOperator()
+	sig, _ = other.Sign([]byte(input))
+	config["signature"] = stringValue(base64.RawURLEncoding.EncodeToString(sig))
+	if _, diags := p.tryApply("nkey_account_jwt", state, config); !hasError(diags, "Invalid signed JWT") {
+		t.Errorf("expected an invalid signature error, got %v", diags)
+	}
+}
+
+func TestOfflineSigner(t *testing.T) {
+	operator, _ := nkeys.CreateOperator()
+	operatorKey, _ := operator.PublicKey()
+	account, _ := nkeys.CreateAccount()
+	accountKey, _ := account.PublicKey()
+
+	tests := map[string]struct {
+		pubKey string
+		// err is part of the expected error, empty for none.
+		err string
+	}{
+		"operator":           {pubKey: operatorKey},
+		"trailing newline":   {pubKey: operatorKey + "\n"},
+		"surrounding spaces": {pubKey: "  " + operatorKey + " "},
+		"wrong type":         {pubKey: accountKey + "\n", err: "the public key is of type account, expected operator"},
+		"invalid":            {pubKey: "not a key", err: "illegal base32 data"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			keys, err := offlineSigner(test.pubKey, path.Root("issuer"), nkeys.PrefixByteOperator)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("err = %v, want %q", err, test.err)
+				}
+				var attrErr *attributeError
+				if !errors.As(err, &attrErr) || !attrErr.path.Equal(path.Root("issuer")) {
+					t.Errorf("err %v is not at issuer", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if key, _ := keys.PublicKey(); key != operatorKey {
+				t.Errorf("public key = %q, want %s", key, operatorKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,372 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &OperatorJWT{}
+var _ resource.ResourceWithModifyPlan = &OperatorJWT{}
+
+func NewOperatorJWT() resource.Resource {
+	return &OperatorJWT{}
+}
+
+// OperatorJWT defines the resource implementation.
+type OperatorJWT struct {
+	provider *providerData
+}
+
+// OperatorJWTModel describes the resource data model.
+type OperatorJWTModel struct {
+	Seed               types.String  `tfsdk:"seed"`
+	Name               types.String  `tfsdk:"name"`
+	SigningKeys        types.Set     `tfsdk:"signing_keys"`
+	StrictSigning      types.Bool    `tfsdk:"strict_signing_key_usage"`
+	SystemAccount      types.String  `tfsdk:"system_account"`
+	AccountServerURL   types.String  `tfsdk:"account_server_url"`
+	ServiceURLs        types.Set     `tfsdk:"operator_service_urls"`
+	Tags               types.Set     `tfsdk:"tags"`
+	ExpiresAt          types.String  `tfsdk:"expires_at"`
+	NotBefore          types.String  `tfsdk:"not_before"`
+	RenewBefore        types.String  `tfsdk:"renew_before"`
+	IssuedAt           types.String  `tfsdk:"issued_at"`
+	DeterministicID    types.Bool    `tfsdk:"deterministic_id"`
+	JWTVersion         types.Int64   `tfsdk:"jwt_version"`
+	Claims             types.Dynamic `tfsdk:"claims"`
+	PublicKey          types.String  `tfsdk:"public_key"`
+	SigningRequest     types.String  `tfsdk:"signing_request"`
+	SigningRequestHash types.String  `tfsdk:"signing_request_sha256"`
+	SignedJWT          types.String  `tfsdk:"signed_jwt"`
+	Signature          types.String  `tfsdk:"signature"`
+	JWT                types.String  `tfsdk:"jwt"`
+}
+
+func (r *OperatorJWT) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_operator_jwt"
+}
+
+func (r *OperatorJWT) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "A self-signed operator JWT, the root of trust of a NATS deployment with decentralized " +
+			"authentication. The JWT is issued again whenever its claims change.",
+
+		Attributes: map[string]schema.Attribute{
+			"seed": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Seed of the operator identity nkey the JWT is issued for and signed with",
+				Sensitive:           true,
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("public_key")),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Name of the operator",
+			},
+			"signing_keys": schema.SetAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Operator public keys that may sign account JWTs on behalf of the operator",
+			},
+			"strict_signing_key_usage": schema.BoolAttribute{
+				Optional: true,
+				MarkdownDescription: "Only accept account JWTs signed by one of the `signing_keys`, so the operator " +
+					"identity key is only needed to sign the operator JWT and can be kept offline",
+			},
+			"system_account": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Public key of the system account",
+			},
+			"account_server_url": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "URL of the account server, e.g. `nats://localhost:4222` for the built-in resolver",
+			},
+			"operator_service_urls": schema.SetAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "URLs of the NATS servers of the operator, used by `nsc` and other tools to connect, e.g. `tls://nats.example.com:4222`",
+			},
+			"tags": schema.SetAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				MarkdownDescription: "Tags of the operator. Tags are lowercased. Operator claims have no `description` " +
+					"or `info_url` like account claims do, so tags are the only metadata the operator JWT carries",
+			},
+			"expires_at":       expiresAtAttribute(),
+			"not_before":       notBeforeAttribute(),
+			"renew_before":     renewBeforeAttribute(),
+			"issued_at":        issuedAtAttribute(),
+			"deterministic_id": deterministicIDAttribute(),
+			"jwt_version":      jwtVersionAttribute(),
+			"public_key": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				MarkdownDescription: "Public key of the operator. Set it instead of `seed` to sign the JWT on an " +
+					"air-gapped machine, see `signing_request`",
+			},
+			"signing_request":        signingRequestAttribute(),
+			"signing_request_sha256": signingRequestHashAttribute(),
+			"signed_jwt":             signedJWTAttribute("seed"),
+			"signature":              signatureAttribute("seed"),
+			"jwt": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The encoded operator JWT. Empty while a JWT signed offline awaits its signature",
+			},
+			"claims": claimsAttribute(),
+		},
+	}
+}
+
+func (r *OperatorJWT) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	// Nothing to compare against on create or destroy, or before all
+	// attributes are known
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() || !req.Config.Raw.IsFullyKnown() {
+		return
+	}
+
+	var plan, state OperatorJWTModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The JWT is only signed again if its claims change. Errors are reported
+	// on apply.
+	var diags diag.Diagnostics
+	r.issue(ctx, &plan, &diags)
+	issued, previous := plan.JWT, state.JWT
+	if plan.Seed.IsNull() {
+		issued, previous = plan.SigningRequest, state.SigningRequest
+	}
+	if diags.HasError() || !sameClaims(issued, previous,
+		!plan.ExpiresAt.Equal(state.ExpiresAt) || !plan.NotBefore.Equal(state.NotBefore),
+		!plan.IssuedAt.Equal(state.IssuedAt) || !plan.DeterministicID.Equal(state.DeterministicID),
+		r.provider.provenanceKeys()...) {
+		// The claims issued again on apply only differ in those that
+		// change with the issuance.
+		if !diags.HasError() {
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("claims"),
+				claimsValue(issued, &resp.Diagnostics, volatileClaims(r.provider, plan.IssuedAt, plan.ExpiresAt, plan.NotBefore)...))...)
+		}
+		return
+	}
+
+	token := state.JWT
+	if plan.Seed.IsNull() {
+		var err error
+		signed, p := offlineJWT(state.SigningRequest, plan.SignedJWT, plan.Signature)
+		if token, err = signedJWT(state.SigningRequest, signed, p); err != nil {
+			addError(&resp.Diagnostics, "Invalid signed JWT", err)
+		}
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("public_key"), state.PublicKey)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("signing_request"), state.SigningRequest)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("signing_request_sha256"), state.SigningRequestHash)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("jwt"), token)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("claims"), claimsValue(previous, &resp.Diagnostics))...)
+}
+
+func (r *OperatorJWT) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.provider = data
+}
+
+func (r *OperatorJWT) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var data OperatorJWTModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.issue(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Trace(ctx, "created operator JWT resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.provider.register(ctx, "nkey_operator_jwt", data.JWT, &resp.Diagnostics)
+}
+
+func (r *OperatorJWT) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data OperatorJWTModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A JWT close to expiry is issued again on the next apply.
+	if renewalDue(data.JWT, data.RenewBefore) {
+		tflog.Info(ctx, "JWT is due for renewal, removing it from state", map[string]interface{}{
+			"renew_before": data.RenewBefore.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Claims are missing from the state of earlier versions.
+	if data.Claims.IsNull() {
+		token := data.JWT
+		if token.IsNull() {
+			token = data.SigningRequest
+		}
+		data.Claims = claimsValue(token, &resp.Diagnostics)
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *OperatorJWT) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var plan OperatorJWTModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The JWT in state is kept if its claims did not change.
+	reissued := plan.JWT.IsUnknown()
+	if reissued {
+		r.issue(ctx, &plan, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if reissued && !plan.JWT.IsNull() {
+		r.provider.notify(ctx, jwtEvent("reissued", "nkey_operator_jwt", plan.JWT), &resp.Diagnostics)
+		r.provider.register(ctx, "nkey_operator_jwt", plan.JWT, &resp.Diagnostics)
+	}
+}
+
+func (r *OperatorJWT) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Nothing to do here as the JWT only exists in state
+}
+
+// issue encodes the operator claims of data and signs them with the
+// operator seed, or encodes them as signing request if the JWT is signed
+// offline.
+func (r *OperatorJWT) issue(ctx context.Context, data *OperatorJWTModel, diags *diag.Diagnostics) {
+	keys, err := issuerSigner(data.Seed, path.Root("seed"), data.PublicKey, path.Root("public_key"), nkeys.PrefixByteOperator)
+	if err != nil {
+		addError(diags, "Invalid operator key", err)
+		return
+	}
+	defer keys.Wipe()
+
+	pubKey, err := keys.PublicKey()
+	if err != nil {
+		addError(diags, "Invalid operator seed", errorAt(path.Root("seed"), err))
+		return
+	}
+
+	claims := jwt.NewOperatorClaims(pubKey)
+	claims.Name = data.Name.ValueString()
+	claims.SigningKeys.Add(publicKeys(ctx, data.SigningKeys, path.Root("signing_keys"), nkeys.PrefixByteOperator, diags)...)
+	claims.StrictSigningKeyUsage = data.StrictSigning.ValueBool()
+	if claims.StrictSigningKeyUsage && len(claims.SigningKeys) == 0 {
+		addError(diags, "Invalid signing key usage", errorAt(path.Root("strict_signing_key_usage"),
+			errors.New("strict signing key usage requires at least one signing key")))
+	}
+	claims.AccountServerURL = data.AccountServerURL.ValueString()
+
+	var serviceURLs []string
+	diags.Append(data.ServiceURLs.ElementsAs(ctx, &serviceURLs, false)...)
+	claims.OperatorServiceURLs.Add(serviceURLs...)
+
+	if !data.SystemAccount.IsNull() {
+		if err := checkPublicKey(data.SystemAccount.ValueString(), nkeys.PrefixByteAccount); err != nil {
+			addError(diags, "Invalid system account", errorAt(path.Root("system_account"), err))
+		}
+		claims.SystemAccount = data.SystemAccount.ValueString()
+	}
+
+	claims.Tags.Add(tagList(ctx, data.Tags, diags)...)
+	claims.Tags.Add(r.provider.provenanceTags("nkey_operator_jwt", claims.Name)...)
+
+	setValidity(&claims.ClaimsData, data.IssuedAt, data.ExpiresAt, data.NotBefore, data.RenewBefore, diags)
+
+	if diags.HasError() {
+		return
+	}
+
+	token, err := jwtEncoder(data.JWTVersion)(claims, keys, data.DeterministicID.ValueBool(), diags)
+	if err != nil {
+		addError(diags, "Unable to issue operator JWT", err)
+		return
+	}
+	data.Claims = claimsValue(types.StringValue(token), diags)
+
+	data.PublicKey = types.StringValue(pubKey)
+	if data.Seed.IsNull() {
+		data.SigningRequest = types.StringValue(token)
+		data.SigningRequestHash = signingRequestHash(data.SigningRequest)
+		signed, p := offlineJWT(data.SigningRequest, data.SignedJWT, data.Signature)
+		data.JWT = awaitSignature(data.SigningRequest, signed, p, diags)
+		return
+	}
+	data.SigningRequest = types.StringNull()
+	data.SigningRequestHash = types.StringNull()
+	data.JWT = types.StringValue(token)
+}
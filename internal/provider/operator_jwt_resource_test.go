@@ -0,0 +1,89 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestOperatorJWT(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	operator, _ := nkeys.CreateOperator()
+	operatorKey, _ := operator.PublicKey()
+	seed, _ := operator.Seed()
+	signingKey, _ := nkeys.CreateOperator()
+	signingKeyKey, _ := signingKey.PublicKey()
+	system, _ := nkeys.CreateAccount()
+	systemKey, _ := system.PublicKey()
+
+	state := p.create("nkey_operator_jwt", map[string]tftypes.Value{
+		"seed":                     stringValue(string(seed)),
+		"name":                     stringValue("acme"),
+		"signing_keys":             stringSetValue(signingKeyKey),
+		"strict_signing_key_usage": boolValue(true),
+		"system_account":           stringValue(systemKey),
+		"account_server_url":       stringValue("nats://localhost:4222"),
+		"operator_service_urls":    stringSetValue("tls://nats.example.com:4222"),
+		"tags":                     stringSetValue("Env:Prod"),
+		"expires_at":               stringValue("2h"),
+	})
+
+	token := stringAttribute(t, state, "jwt")
+	verifySignature(t, token, operatorKey)
+
+	claims, err := jwt.DecodeOperatorClaims(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Issuer != operatorKey || claims.Subject != operatorKey {
+		t.Errorf("JWT of %s issued by %s, want a self-signed JWT of %s", claims.Subject, claims.Issuer, operatorKey)
+	}
+	if got := stringAttribute(t, state, "public_key"); got != operatorKey {
+		t.Errorf("public_key = %s, want %s", got, operatorKey)
+	}
+	if claims.Name != "acme" {
+		t.Errorf("name = %q, want acme", claims.Name)
+	}
+	if !claims.SigningKeys.Contains(signingKeyKey) || len(claims.SigningKeys) != 1 || !claims.StrictSigningKeyUsage {
+		t.Errorf("signing keys = %v, strict = %t", claims.SigningKeys, claims.StrictSigningKeyUsage)
+	}
+	if claims.SystemAccount != systemKey {
+		t.Errorf("system account = %s, want %s", claims.SystemAccount, systemKey)
+	}
+	if claims.AccountServerURL != "nats://localhost:4222" {
+		t.Errorf("account server URL = %q", claims.AccountServerURL)
+	}
+	if len(claims.OperatorServiceURLs) != 1 || claims.OperatorServiceURLs[0] != "tls://nats.example.com:4222" {
+		t.Errorf("operator service URLs = %v", claims.OperatorServiceURLs)
+	}
+	if !claims.Tags.Contains("env:prod") {
+		t.Errorf("tags = %v, want the lowercased tag", claims.Tags)
+	}
+	if claims.IssuedAt == 0 || claims.ID == "" {
+		t.Errorf("iat = %d, jti = %q", claims.IssuedAt, claims.ID)
+	}
+	if got := claims.Expires - claims.IssuedAt; got != 2*60*60 {
+		t.Errorf("JWT expires %ds after issuance, want 2h", got)
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		for name, config := range map[string]map[string]tftypes.Value{
+			"system account": {"system_account": stringValue(signingKeyKey)},
+			"signing key":    {"signing_keys": stringSetValue(systemKey)},
+			"strict usage":   {"strict_signing_key_usage": boolValue(true)},
+		} {
+			config["seed"] = stringValue(string(seed))
+			config["name"] = stringValue("acme")
+			if _, diags := p.tryApply("nkey_operator_jwt", tftypes.Value{}, config); !hasError(diags, "Invalid") {
+				t.Errorf("%s: expected an error, got %v", name, diags)
+			}
+		}
+	})
+}
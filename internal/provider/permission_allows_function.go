@@ -0,0 +1,90 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &PermissionAllowsFunction{}
+
+func NewPermissionAllowsFunction() function.Function {
+	return &PermissionAllowsFunction{}
+}
+
+// PermissionAllowsFunction defines the function implementation.
+type PermissionAllowsFunction struct{}
+
+func (f *PermissionAllowsFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "permission_allows"
+}
+
+func (f *PermissionAllowsFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Check a subject against allow and deny lists",
+		MarkdownDescription: "Returns whether the given allow and deny lists permit `action` on `subject`, using the same " +
+			"evaluation as the `nkey_permission_check` data source: deny entries take precedence, and a non-empty allow " +
+			"list must cover the subject. Useful in variable validations and preconditions.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "action",
+				MarkdownDescription: "Action to check. Must be one of publish|subscribe",
+			},
+			function.StringParameter{
+				Name:                "subject",
+				MarkdownDescription: "Subject to check. May contain wildcards for `subscribe`",
+			},
+			function.ListParameter{
+				Name:                "allow",
+				ElementType:         types.StringType,
+				AllowNullValue:      true,
+				MarkdownDescription: "Subjects to allow, all subjects are allowed if null or empty",
+			},
+			function.ListParameter{
+				Name:                "deny",
+				ElementType:         types.StringType,
+				AllowNullValue:      true,
+				MarkdownDescription: "Subjects to deny",
+			},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *PermissionAllowsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var (
+		action, subj string
+		perm         PermissionModel
+	)
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &action, &subj, &perm.Allow, &perm.Deny))
+	if resp.Error != nil {
+		return
+	}
+
+	permissions := &PermissionsModel{Publish: &perm, Subscribe: &perm}
+
+	allowed, _, err := checkPermission(permissions, action, subj)
+	if err != nil {
+		// Report the error at the argument checkPermission attributed it to.
+		argument := int64(1)
+		var ae *attributeError
+		if errors.As(err, &ae) {
+			if ae.path.Equal(path.Root("action")) {
+				argument = 0
+			}
+			err = ae.err
+		}
+		resp.Error = function.NewArgumentFuncError(argument, err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, allowed))
+}
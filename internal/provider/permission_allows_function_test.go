@@ -0,0 +1,98 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestPermissionAllowsFunction(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	// The lists are taken from a decoded user JWT, as a precondition on the
+	// jwt attribute would.
+	account, _ := nkeys.CreateAccount()
+	user, _ := nkeys.CreateUser()
+	userKey, _ := user.PublicKey()
+	claims := jwt.NewUserClaims(userKey)
+	claims.Pub.Allow.Add("orders.>", "_INBOX.>")
+	claims.Pub.Deny.Add("orders.internal.>")
+	claims.Sub.Deny.Add("secret.*")
+	token, err := claims.Encode(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := jwt.DecodeUserClaims(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list := func(s []string) tftypes.Value {
+		if s == nil {
+			return tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, nil)
+		}
+		values := make([]tftypes.Value, len(s))
+		for i, s := range s {
+			values[i] = stringValue(s)
+		}
+		return tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, values)
+	}
+	allows := func(action, subject string, perm jwt.Permission) (bool, *tfprotov6.FunctionError) {
+		result, funcErr := p.call("permission_allows", tftypes.Bool, stringValue(action), stringValue(subject), list(perm.Allow), list(perm.Deny))
+		if funcErr != nil {
+			return false, funcErr
+		}
+		var allowed bool
+		if err := result.As(&allowed); err != nil {
+			t.Fatal(err)
+		}
+		return allowed, nil
+	}
+
+	tests := []struct {
+		action, subject string
+		perm            jwt.Permission
+		allowed         bool
+	}{
+		{"publish", "orders.new", decoded.Pub, true},
+		{"publish", "orders.internal.audit", decoded.Pub, false},
+		{"publish", "billing.new", decoded.Pub, false},
+		{"subscribe", "orders.*", decoded.Sub, true},
+		{"subscribe", "secret.key", decoded.Sub, false},
+		{"publish", "anything", jwt.Permission{}, true},
+	}
+
+	for _, test := range tests {
+		allowed, funcErr := allows(test.action, test.subject, test.perm)
+		if funcErr != nil {
+			t.Errorf("permission_allows(%s, %s): %s", test.action, test.subject, funcErr.Text)
+			continue
+		}
+		if allowed != test.allowed {
+			t.Errorf("permission_allows(%s, %s) = %t, want %t", test.action, test.subject, allowed, test.allowed)
+		}
+	}
+
+	for name, test := range map[string]struct {
+		action, subject string
+		argument        int64
+	}{
+		"action":           {"request", "orders.new", 0},
+		"publish wildcard": {"publish", "orders.*", 1},
+		"empty subject":    {"subscribe", "", 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, funcErr := allows(test.action, test.subject, decoded.Pub)
+			if funcErr == nil || funcErr.FunctionArgument == nil || *funcErr.FunctionArgument != test.argument {
+				t.Errorf("expected an error at argument %d, got %v", test.argument, funcErr)
+			}
+		})
+	}
+}
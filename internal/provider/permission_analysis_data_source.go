@@ -0,0 +1,212 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"terraform-provider-nkey/internal/subject"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &PermissionAnalysis{}
+
+func NewPermissionAnalysis() datasource.DataSource {
+	return &PermissionAnalysis{}
+}
+
+// PermissionAnalysis defines the data source implementation.
+type PermissionAnalysis struct {
+}
+
+// PermissionAnalysisModel describes the data source data model.
+type PermissionAnalysisModel struct {
+	Users              map[string]PermissionsModel `tfsdk:"users"`
+	UserJWTs           map[string]string           `tfsdk:"user_jwts"`
+	DefaultPermissions *PermissionsModel           `tfsdk:"default_permissions"`
+	Findings           []PermissionFindingModel    `tfsdk:"findings"`
+}
+
+// PermissionFindingModel describes a single result of the analysis.
+type PermissionFindingModel struct {
+	Kind        types.String `tfsdk:"kind"`
+	Action      types.String `tfsdk:"action"`
+	Users       []string     `tfsdk:"users"`
+	Subjects    []string     `tfsdk:"subjects"`
+	Description types.String `tfsdk:"description"`
+}
+
+func (d *PermissionAnalysis) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_permission_analysis"
+}
+
+func (d *PermissionAnalysis) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Analyzes the permissions of all users of an account and reports wildcard grants overlapping " +
+			"between users, allow entries contradicted by deny entries and users without any restriction, to review " +
+			"least privilege before issuing user JWTs.",
+
+		Attributes: map[string]schema.Attribute{
+			"users": schema.MapNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "Map of user names to their permissions",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: permissionsDataSourceAttributes(),
+				},
+			},
+			"user_jwts": schema.MapAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Map of user names to user JWTs to take the permissions from",
+			},
+			"default_permissions": permissionsDataSourceAttribute("Default permissions of the account, applied to users without permissions"),
+			"findings": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "Results of the analysis",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"kind": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "One of overlap|contradiction|unrestricted",
+						},
+						"action": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Action the finding applies to, publish|subscribe",
+						},
+						"users": schema.ListAttribute{
+							Computed:            true,
+							ElementType:         types.StringType,
+							MarkdownDescription: "Names of the users involved",
+						},
+						"subjects": schema.ListAttribute{
+							Computed:            true,
+							ElementType:         types.StringType,
+							MarkdownDescription: "Permission entries involved",
+						},
+						"description": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Human readable explanation of the finding",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *PermissionAnalysis) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data PermissionAnalysisModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	users := map[string]*PermissionsModel{}
+	for name, p := range data.Users {
+		p := p
+		users[name] = effectivePermissions(&p, data.DefaultPermissions)
+	}
+	for name, token := range data.UserJWTs {
+		if _, ok := users[name]; ok {
+			resp.Diagnostics.AddAttributeError(path.Root("user_jwts").AtMapKey(name), "Duplicate user",
+				fmt.Sprintf("user %q is also set in users", name))
+			continue
+		}
+
+		p, err := userJWTPermissions(token)
+		if err != nil {
+			addError(&resp.Diagnostics, "Invalid user JWT", errorAt(path.Root("user_jwts").AtMapKey(name), err))
+			continue
+		}
+		users[name] = effectivePermissions(p, data.DefaultPermissions)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Findings = analyzePermissions(users)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// analyzePermissions returns the findings for users in a stable order.
+func analyzePermissions(users map[string]*PermissionsModel) []PermissionFindingModel {
+	findings := []PermissionFindingModel{}
+	names := sortedKeys(users)
+
+	finding := func(kind, action string, users, subjects []string, format string, args ...interface{}) {
+		findings = append(findings, PermissionFindingModel{
+			Kind:        types.StringValue(kind),
+			Action:      types.StringValue(action),
+			Users:       users,
+			Subjects:    subjects,
+			Description: types.StringValue(fmt.Sprintf(format, args...)),
+		})
+	}
+
+	for _, action := range []string{"publish", "subscribe"} {
+		perms := map[string]*PermissionModel{}
+		for _, name := range names {
+			if p := users[name]; p != nil {
+				if action == "publish" {
+					perms[name] = p.Publish
+				} else {
+					perms[name] = p.Subscribe
+				}
+			}
+		}
+
+		for _, name := range names {
+			p := perms[name]
+			if p == nil || len(p.Allow) == 0 {
+				finding("unrestricted", action, []string{name}, []string{},
+					"%s may %s to all subjects not explicitly denied", quote(name), action)
+				continue
+			}
+
+			for _, allow := range p.Allow {
+				for _, deny := range p.Deny {
+					switch {
+					case subject.Covers(deny, allow):
+						finding("contradiction", action, []string{name}, []string{allow, deny},
+							"allow entry %s of %s has no effect, it is denied by %s", quote(allow), quote(name), quote(deny))
+					case subject.Overlaps(deny, allow):
+						finding("contradiction", action, []string{name}, []string{allow, deny},
+							"allow entry %s of %s is partially denied by %s", quote(allow), quote(name), quote(deny))
+					}
+				}
+			}
+		}
+
+		for i, a := range names {
+			for _, b := range names[i+1:] {
+				if perms[a] == nil || perms[b] == nil {
+					continue
+				}
+
+				for _, x := range perms[a].Allow {
+					for _, y := range perms[b].Allow {
+						if subject.IsLiteral(x) && subject.IsLiteral(y) || !subject.Overlaps(x, y) {
+							continue
+						}
+						finding("overlap", action, []string{a, b}, []string{x, y},
+							"%s of %s and %s of %s grant %s on common subjects", quote(x), quote(a), quote(y), quote(b), action)
+					}
+				}
+			}
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,156 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &PermissionCheck{}
+var _ datasource.DataSourceWithValidateConfig = &PermissionCheck{}
+
+func NewPermissionCheck() datasource.DataSource {
+	return &PermissionCheck{}
+}
+
+// PermissionCheck defines the data source implementation.
+type PermissionCheck struct {
+}
+
+// PermissionCheckModel describes the data source data model.
+type PermissionCheckModel struct {
+	Subject            types.String      `tfsdk:"subject"`
+	Action             types.String      `tfsdk:"action"`
+	Permissions        *PermissionsModel `tfsdk:"permissions"`
+	DefaultPermissions *PermissionsModel `tfsdk:"default_permissions"`
+	UserJWT            types.String      `tfsdk:"user_jwt"`
+	Allowed            types.Bool        `tfsdk:"allowed"`
+	Reason             types.String      `tfsdk:"reason"`
+}
+
+// permissionsDataSourceAttribute returns the schema of a PermissionsModel.
+func permissionsDataSourceAttribute(description string) schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Optional:            true,
+		MarkdownDescription: description,
+		Attributes:          permissionsDataSourceAttributes(),
+	}
+}
+
+// permissionsDataSourceAttributes returns the attributes of a PermissionsModel.
+func permissionsDataSourceAttributes() map[string]schema.Attribute {
+	permission := func(action string) schema.SingleNestedAttribute {
+		return schema.SingleNestedAttribute{
+			Optional:            true,
+			MarkdownDescription: "Subjects the user may " + action + " to",
+			Attributes: map[string]schema.Attribute{
+				"allow": schema.ListAttribute{
+					Optional:            true,
+					ElementType:         types.StringType,
+					MarkdownDescription: "Subjects to allow, all subjects are allowed if empty",
+				},
+				"deny": schema.ListAttribute{
+					Optional:            true,
+					ElementType:         types.StringType,
+					MarkdownDescription: "Subjects to deny, takes precedence over `allow`",
+				},
+			},
+		}
+	}
+
+	return map[string]schema.Attribute{
+		"publish":   permission("publish"),
+		"subscribe": permission("subscribe"),
+	}
+}
+
+func (d *PermissionCheck) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_permission_check"
+}
+
+func (d *PermissionCheck) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Evaluates whether a user would be allowed to publish or subscribe to a subject, " +
+			"for plan-time checks such as \"tenant A must not see tenant B's subjects\".",
+
+		Attributes: map[string]schema.Attribute{
+			"subject": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Subject to check. May contain wildcards for `subscribe`",
+			},
+			"action": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Action to check. Must be one of publish|subscribe",
+			},
+			"permissions":         permissionsDataSourceAttribute("Permissions of the user. Conflicts with `user_jwt`"),
+			"default_permissions": permissionsDataSourceAttribute("Default permissions of the account, applied if the user has no permissions"),
+			"user_jwt": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "User JWT to take the permissions from. Conflicts with `permissions`",
+			},
+			"allowed": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the action is allowed",
+			},
+			"reason": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Human readable explanation of the result",
+			},
+		},
+	}
+}
+
+func (d *PermissionCheck) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var data PermissionCheckModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.Permissions != nil && !data.UserJWT.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("user_jwt"), "Conflicting permissions",
+			"only one of permissions and user_jwt can be set")
+	}
+}
+
+func (d *PermissionCheck) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data PermissionCheckModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	user := data.Permissions
+	if !data.UserJWT.IsNull() {
+		var err error
+		if user, err = userJWTPermissions(data.UserJWT.ValueString()); err != nil {
+			addError(&resp.Diagnostics, "Invalid user JWT", errorAt(path.Root("user_jwt"), err))
+			return
+		}
+	}
+
+	allowed, reason, err := checkPermission(effectivePermissions(user, data.DefaultPermissions), data.Action.ValueString(), data.Subject.ValueString())
+	if err != nil {
+		addError(&resp.Diagnostics, "Unable to check permission", err)
+		return
+	}
+
+	data.Allowed = types.BoolValue(allowed)
+	data.Reason = types.StringValue(reason)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
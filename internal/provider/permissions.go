@@ -0,0 +1,262 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"terraform-provider-nkey/internal/subject"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+
+	"github.com/nats-io/jwt/v2"
+)
+
+// PermissionModel is an allow/deny pair of subject lists.
+type PermissionModel struct {
+	Allow []string `tfsdk:"allow" json:"allow,omitempty"`
+	Deny  []string `tfsdk:"deny" json:"deny,omitempty"`
+}
+
+// PermissionsModel holds the publish and subscribe permissions of a user.
+type PermissionsModel struct {
+	Publish   *PermissionModel `tfsdk:"publish" json:"pub,omitempty"`
+	Subscribe *PermissionModel `tfsdk:"subscribe" json:"sub,omitempty"`
+}
+
+func (p *PermissionsModel) empty() bool {
+	return p == nil || (p.Publish.empty() && p.Subscribe.empty())
+}
+
+func (p *PermissionModel) empty() bool {
+	return p == nil || (len(p.Allow) == 0 && len(p.Deny) == 0)
+}
+
+// jwtPermissions returns the JWT representation of p.
+func (p *PermissionsModel) jwtPermissions() jwt.Permissions {
+	var perms jwt.Permissions
+	if p == nil {
+		return perms
+	}
+	if p.Publish != nil {
+		perms.Pub.Allow.Add(p.Publish.Allow...)
+		perms.Pub.Deny.Add(p.Publish.Deny...)
+	}
+	if p.Subscribe != nil {
+		perms.Sub.Allow.Add(p.Subscribe.Allow...)
+		perms.Sub.Deny.Add(p.Subscribe.Deny...)
+	}
+	return perms
+}
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ validator.String = subjectValidator{}
+
+// subjectValidator checks that a string is a well formed subject. With
+// queue set, the subject may be followed by a queue group, separated by a
+// single space, as in subscribe permissions.
+type subjectValidator struct {
+	wildcards bool
+	queue     bool
+}
+
+func (v subjectValidator) Description(ctx context.Context) string {
+	var desc string
+	if v.wildcards {
+		desc = "value must be a NATS subject, wildcards are allowed"
+	} else {
+		desc = "value must be a NATS subject without wildcards"
+	}
+	if v.queue {
+		desc += ", optionally followed by a space and a queue group"
+	}
+	return desc
+}
+
+func (v subjectValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v subjectValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	subj, queue, found := req.ConfigValue.ValueString(), "", false
+	if v.queue {
+		subj, queue, found = strings.Cut(subj, " ")
+	}
+
+	if err := subject.Validate(subj, v.wildcards); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid subject", err.Error())
+		return
+	}
+	// Queue groups of permissions may use wildcards, like subjects.
+	if found {
+		if err := subject.Validate(queue, true); err != nil {
+			resp.Diagnostics.AddAttributeError(req.Path, "Invalid queue group", fmt.Sprintf("queue group of %q: %s", subj, err))
+		}
+	}
+}
+
+// splitQueue splits a subscribe permission into its subject and the queue
+// group following it, if any.
+func splitQueue(s string) (subj, queue string) {
+	subj, queue, _ = strings.Cut(s, " ")
+	return subj, queue
+}
+
+// eachAllowed calls f with the action, subject and path of every subject
+// allowed by p. Queue groups of subscribe permissions are left out.
+func (p *PermissionsModel) eachAllowed(f func(action, subj string, p path.Path)) {
+	if p == nil {
+		return
+	}
+
+	for _, perm := range []struct {
+		action string
+		perm   *PermissionModel
+	}{
+		{"publish", p.Publish},
+		{"subscribe", p.Subscribe},
+	} {
+		if perm.perm == nil {
+			continue
+		}
+		for i, subj := range perm.perm.Allow {
+			subj, _ := splitQueue(subj)
+			f(perm.action, subj, path.Root("permissions").AtName(perm.action).AtName("allow").AtListIndex(i))
+		}
+	}
+}
+
+// permits reports whether every subject matched by subj is permitted by p:
+// it is not covered by a deny entry and, if there are allow entries, covered
+// by one of them.
+func (p *PermissionModel) permits(subj string) bool {
+	if p == nil {
+		return true
+	}
+
+	for _, deny := range p.Deny {
+		if subject.Covers(deny, subj) {
+			return false
+		}
+	}
+	if len(p.Allow) == 0 {
+		return true
+	}
+	for _, allow := range p.Allow {
+		if subject.Covers(allow, subj) {
+			return true
+		}
+	}
+	return false
+}
+
+// effectivePermissions returns the permissions applied to a user: the
+// account defaults apply to users without any permissions of their own.
+func effectivePermissions(user, defaults *PermissionsModel) *PermissionsModel {
+	if user.empty() {
+		return defaults
+	}
+	return user
+}
+
+// checkPermission evaluates whether action ("publish" or "subscribe") on
+// subj is permitted by p, following nats-server semantics: deny entries
+// take precedence, and a non-empty allow list must cover subj.
+func checkPermission(p *PermissionsModel, action, subj string) (bool, string, error) {
+	var perm *PermissionModel
+
+	switch action {
+	case "publish":
+		if err := subject.Validate(subj, false); err != nil {
+			return false, "", errorAt(path.Root("subject"), err)
+		}
+		if p != nil {
+			perm = p.Publish
+		}
+	case "subscribe":
+		if err := subject.Validate(subj, true); err != nil {
+			return false, "", errorAt(path.Root("subject"), err)
+		}
+		if p != nil {
+			perm = p.Subscribe
+		}
+	default:
+		return false, "", errorAt(path.Root("action"), fmt.Errorf("unsupported action %q, must be one of publish|subscribe", action))
+	}
+
+	if perm.empty() {
+		return true, "no " + action + " permissions are set", nil
+	}
+
+	// Entries with a queue group only apply to subscriptions in that queue
+	// group, which are not checked here.
+	var filtered []string
+	for _, deny := range perm.Deny {
+		if _, queue := splitQueue(deny); queue != "" {
+			continue
+		}
+		if subject.Covers(deny, subj) {
+			return false, fmt.Sprintf("denied by %q", deny), nil
+		}
+		if subject.Overlaps(deny, subj) {
+			filtered = append(filtered, deny)
+		}
+	}
+
+	reason := "not denied"
+	if len(perm.Allow) > 0 {
+		reason = ""
+		for _, allow := range perm.Allow {
+			if _, queue := splitQueue(allow); queue != "" {
+				continue
+			}
+			if subject.Covers(allow, subj) {
+				reason = fmt.Sprintf("allowed by %q", allow)
+				break
+			}
+		}
+		if reason == "" {
+			return false, "not covered by any allow entry", nil
+		}
+	}
+
+	if len(filtered) > 0 {
+		reason += fmt.Sprintf(", messages on subjects matching %s are not delivered", strings.Join(quoteAll(filtered), ", "))
+	}
+
+	return true, reason, nil
+}
+
+// userJWTPermissions decodes the permissions of a user JWT without
+// verifying its signature.
+func userJWTPermissions(token string) (*PermissionsModel, error) {
+	var claims struct {
+		Nats struct {
+			Type string `json:"type"`
+			PermissionsModel
+		} `json:"nats"`
+	}
+	if err := jwtClaims(token, &claims); err != nil {
+		return nil, err
+	}
+	if claims.Nats.Type != "user" {
+		return nil, fmt.Errorf("expected a user JWT, got type %q", claims.Nats.Type)
+	}
+
+	return &claims.Nats.PermissionsModel, nil
+}
+
+func quoteAll(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = quote(v)
+	}
+	return quoted
+}
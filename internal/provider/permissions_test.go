@@ -0,0 +1,156 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestCheckPermission(t *testing.T) {
+	perms := &PermissionsModel{
+		Publish: &PermissionModel{
+			Allow: []string{"orders.>", "_INBOX.>"},
+			Deny:  []string{"orders.internal.>"},
+		},
+		Subscribe: &PermissionModel{
+			Deny: []string{"secret.*", "orders.* workers"},
+		},
+	}
+	queues := &PermissionsModel{
+		Subscribe: &PermissionModel{
+			Allow: []string{"orders.* workers"},
+		},
+	}
+
+	tests := []struct {
+		perms   *PermissionsModel
+		action  string
+		subject string
+		allowed bool
+		reason  string
+	}{
+		{perms, "publish", "orders.new", true, `allowed by "orders.>"`},
+		{perms, "publish", "orders.internal.audit", false, `denied by "orders.internal.>"`},
+		{perms, "publish", "billing.new", false, "not covered by any allow entry"},
+		{perms, "subscribe", "orders.*", true, "not denied"},
+		{perms, "subscribe", "secret.key", false, `denied by "secret.*"`},
+		{perms, "subscribe", "secret.>", true, `not denied, messages on subjects matching "secret.*" are not delivered`},
+		{perms, "subscribe", "orders.new", true, "not denied"},
+		{queues, "subscribe", "orders.new", false, "not covered by any allow entry"},
+		{nil, "publish", "anything", true, "no publish permissions are set"},
+		{&PermissionsModel{}, "subscribe", "anything", true, "no subscribe permissions are set"},
+	}
+
+	for _, test := range tests {
+		allowed, reason, err := checkPermission(test.perms, test.action, test.subject)
+		if err != nil {
+			t.Errorf("checkPermission(%s %s): %v", test.action, test.subject, err)
+			continue
+		}
+		if allowed != test.allowed || reason != test.reason {
+			t.Errorf("checkPermission(%s %s) = %v, %q, want %v, %q", test.action, test.subject, allowed, reason, test.allowed, test.reason)
+		}
+	}
+}
+
+func TestCheckPermissionErrors(t *testing.T) {
+	tests := []struct {
+		action, subject string
+	}{
+		{"publish", "orders.*"},
+		{"publish", ""},
+		{"subscribe", "orders..new"},
+		{"request", "orders.new"},
+	}
+
+	for _, test := range tests {
+		if _, _, err := checkPermission(nil, test.action, test.subject); err == nil {
+			t.Errorf("checkPermission(%s %s): expected an error", test.action, test.subject)
+		}
+	}
+}
+
+func TestEffectivePermissions(t *testing.T) {
+	user := &PermissionsModel{Publish: &PermissionModel{Allow: []string{"foo"}}}
+	defaults := &PermissionsModel{Subscribe: &PermissionModel{Deny: []string{"bar"}}}
+
+	tests := []struct {
+		name       string
+		user, want *PermissionsModel
+	}{
+		{"user permissions", user, user},
+		{"no user permissions", nil, defaults},
+		{"empty user permissions", &PermissionsModel{Publish: &PermissionModel{}}, defaults},
+	}
+
+	for _, test := range tests {
+		if got := effectivePermissions(test.user, defaults); got != test.want {
+			t.Errorf("%s: effectivePermissions = %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestJWTPermissions(t *testing.T) {
+	perms := (&PermissionsModel{
+		Publish:   &PermissionModel{Allow: []string{"foo.>"}, Deny: []string{"foo.bar"}},
+		Subscribe: &PermissionModel{Allow: []string{"_INBOX.>"}},
+	}).jwtPermissions()
+
+	if !reflect.DeepEqual([]string(perms.Pub.Allow), []string{"foo.>"}) ||
+		!reflect.DeepEqual([]string(perms.Pub.Deny), []string{"foo.bar"}) ||
+		!reflect.DeepEqual([]string(perms.Sub.Allow), []string{"_INBOX.>"}) ||
+		len(perms.Sub.Deny) != 0 {
+		t.Errorf("jwtPermissions = %+v", perms)
+	}
+
+	if perms := (*PermissionsModel)(nil).jwtPermissions(); !reflect.DeepEqual(perms, jwt.Permissions{}) {
+		t.Errorf("jwtPermissions of nil = %+v, want none", perms)
+	}
+}
+
+func TestUserJWTPermissions(t *testing.T) {
+	account, _ := nkeys.CreateAccount()
+	user, _ := nkeys.CreateUser()
+	userKey, _ := user.PublicKey()
+	accountKey, _ := account.PublicKey()
+
+	claims := jwt.NewUserClaims(userKey)
+	claims.Pub.Allow.Add("orders.>")
+	claims.Sub.Deny.Add("secret.>")
+	token, err := claims.Encode(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	perms, err := userJWTPermissions(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(perms.Publish.Allow, []string{"orders.>"}) || !reflect.DeepEqual(perms.Subscribe.Deny, []string{"secret.>"}) {
+		t.Errorf("userJWTPermissions = %+v %+v", perms.Publish, perms.Subscribe)
+	}
+
+	operator, _ := nkeys.CreateOperator()
+	accountToken, err := jwt.NewAccountClaims(accountKey).Encode(operator)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{
+		"account JWT": accountToken,
+		"malformed":   "not.a.jwt",
+		"no JWT":      "token",
+	} {
+		if _, err := userJWTPermissions(token); err == nil {
+			t.Errorf("%s: expected an error", name)
+		} else if name == "account JWT" && !strings.Contains(err.Error(), `"account"`) {
+			t.Errorf("%s: unexpected error %v", name, err)
+		}
+	}
+}
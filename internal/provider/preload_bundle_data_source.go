@@ -0,0 +1,179 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &PreloadBundle{}
+
+func NewPreloadBundle() datasource.DataSource {
+	return &PreloadBundle{}
+}
+
+// PreloadBundle defines the data source implementation.
+type PreloadBundle struct {
+}
+
+// PreloadBundleModel describes the data source data model.
+type PreloadBundleModel struct {
+	OperatorJWT   types.String `tfsdk:"operator_jwt"`
+	AccountJWTs   types.List   `tfsdk:"account_jwts"`
+	SystemAccount types.String `tfsdk:"system_account"`
+	Config        types.String `tfsdk:"config"`
+	JSON          types.String `tfsdk:"json"`
+}
+
+// preloadJSON is the layout of the json attribute.
+type preloadJSON struct {
+	Operator      string            `json:"operator"`
+	SystemAccount string            `json:"system_account,omitempty"`
+	Accounts      map[string]string `json:"accounts"`
+}
+
+func (d *PreloadBundle) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_preload_bundle"
+}
+
+func (d *PreloadBundle) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Bundles an operator JWT and account JWTs for seeding memory resolvers and embedded servers.",
+
+		Attributes: map[string]schema.Attribute{
+			"operator_jwt": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The operator JWT",
+			},
+			"account_jwts": schema.ListAttribute{
+				Required:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Account JWTs to preload, keyed by their subject in the bundle",
+			},
+			"system_account": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "Public key of the system account. Must be one of the preloaded accounts and match " +
+					"`system_account` of the operator JWT, if it names one. Defaults to the system account of the operator JWT",
+			},
+			"config": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "nats-server configuration using the memory resolver",
+			},
+			"json": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "JSON document with `operator`, `system_account` and an `accounts` map of public keys to JWTs",
+			},
+		},
+	}
+}
+
+func (d *PreloadBundle) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data PreloadBundleModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var tokens []string
+	resp.Diagnostics.Append(data.AccountJWTs.ElementsAs(ctx, &tokens, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var operator struct {
+		Subject string `json:"sub"`
+		Nats    struct {
+			SystemAccount string `json:"system_account"`
+		} `json:"nats"`
+	}
+	err := jwtClaims(data.OperatorJWT.ValueString(), &operator)
+	if err == nil && !nkeys.IsValidPublicOperatorKey(operator.Subject) {
+		err = fmt.Errorf("subject %q is not an operator public key", operator.Subject)
+	}
+	if err != nil {
+		addError(&resp.Diagnostics, "Invalid operator JWT", errorAt(path.Root("operator_jwt"), err))
+		return
+	}
+
+	// The system account defaults to the one of the operator, servers
+	// refuse to start on another one.
+	systemAccount := operator.Nats.SystemAccount
+	if !data.SystemAccount.IsNull() {
+		if systemAccount != "" && systemAccount != data.SystemAccount.ValueString() {
+			resp.Diagnostics.AddAttributeError(path.Root("system_account"), "System account mismatch",
+				fmt.Sprintf("The operator JWT names %s as system account, not %s. Unset system_account or set "+
+					"system_account of the operator JWT to it.", systemAccount, data.SystemAccount.ValueString()))
+			return
+		}
+		systemAccount = data.SystemAccount.ValueString()
+	}
+
+	bundle := preloadJSON{
+		Operator:      data.OperatorJWT.ValueString(),
+		SystemAccount: systemAccount,
+		Accounts:      map[string]string{},
+	}
+
+	for i, token := range tokens {
+		account, err := jwtSubject(token)
+		if err == nil && !nkeys.IsValidPublicAccountKey(account) {
+			err = fmt.Errorf("subject %q is not an account public key", account)
+		}
+		if err != nil {
+			addError(&resp.Diagnostics, "Invalid account JWT", errorAt(path.Root("account_jwts").AtListIndex(i), err))
+			return
+		}
+		bundle.Accounts[account] = token
+	}
+
+	if _, ok := bundle.Accounts[bundle.SystemAccount]; bundle.SystemAccount != "" && !ok {
+		if !data.SystemAccount.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("system_account"), "Unknown system account",
+				fmt.Sprintf("%s is not among the preloaded accounts", bundle.SystemAccount))
+			return
+		}
+		resp.Diagnostics.AddAttributeWarning(path.Root("account_jwts"), "System account does not resolve",
+			fmt.Sprintf("The operator JWT names %s as system account, but its JWT is not among the preloaded "+
+				"accounts. Servers using the configuration cannot resolve it, add its JWT to account_jwts.", bundle.SystemAccount))
+	}
+
+	w := &confWriter{}
+
+	w.line("operator: %s", quote(bundle.Operator))
+	if bundle.SystemAccount != "" {
+		w.line("system_account: %s", quote(bundle.SystemAccount))
+	}
+	w.line("resolver: MEMORY")
+	w.open("resolver_preload")
+	for _, account := range sortedKeys(bundle.Accounts) {
+		w.line("%s: %s", account, quote(bundle.Accounts[account]))
+	}
+	w.close()
+
+	encoded, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		addError(&resp.Diagnostics, "Unable to encode bundle", err)
+		return
+	}
+
+	data.Config = types.StringValue(w.String())
+	data.JSON = types.StringValue(string(encoded))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,125 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestPreloadBundleSystemAccount(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	operator, _ := nkeys.CreateOperator()
+	operatorKey, _ := operator.PublicKey()
+
+	accountJWT := func() (string, string) {
+		account, _ := nkeys.CreateAccount()
+		accountKey, _ := account.PublicKey()
+		token, err := jwt.NewAccountClaims(accountKey).Encode(operator)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return accountKey, token
+	}
+	system, systemJWT := accountJWT()
+	other, otherJWT := accountJWT()
+
+	operatorJWT := func(systemAccount string) string {
+		claims := jwt.NewOperatorClaims(operatorKey)
+		claims.SystemAccount = systemAccount
+		token, err := claims.Encode(operator)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	tests := map[string]struct {
+		operatorSystemAccount string
+		systemAccount         tftypes.Value
+		accountJWTs           []string
+		// want is the expected system account of the bundle.
+		want string
+		// err is the expected error summary, empty for none.
+		err string
+		// unresolved reports whether the system account is expected not to
+		// resolve.
+		unresolved bool
+	}{
+		"operator": {
+			operatorSystemAccount: system,
+			systemAccount:         tftypes.NewValue(tftypes.String, nil),
+			accountJWTs:           []string{systemJWT, otherJWT},
+			want:                  system,
+		},
+		"same as operator": {
+			operatorSystemAccount: system,
+			systemAccount:         stringValue(system),
+			accountJWTs:           []string{systemJWT},
+			want:                  system,
+		},
+		"configured": {
+			systemAccount: stringValue(system),
+			accountJWTs:   []string{systemJWT},
+			want:          system,
+		},
+		"mismatch": {
+			operatorSystemAccount: system,
+			systemAccount:         stringValue(other),
+			accountJWTs:           []string{systemJWT, otherJWT},
+			err:                   "System account mismatch",
+		},
+		"operator not preloaded": {
+			operatorSystemAccount: system,
+			systemAccount:         tftypes.NewValue(tftypes.String, nil),
+			accountJWTs:           []string{otherJWT},
+			want:                  system,
+			unresolved:            true,
+		},
+		"configured not preloaded": {
+			systemAccount: stringValue(system),
+			accountJWTs:   []string{otherJWT},
+			err:           "Unknown system account",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			accountJWTs := make([]tftypes.Value, len(test.accountJWTs))
+			for i, token := range test.accountJWTs {
+				accountJWTs[i] = stringValue(token)
+			}
+
+			state, diags := p.read("nkey_preload_bundle", map[string]tftypes.Value{
+				"operator_jwt":   stringValue(operatorJWT(test.operatorSystemAccount)),
+				"account_jwts":   tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, accountJWTs),
+				"system_account": test.systemAccount,
+			})
+			if test.err != "" {
+				if !hasError(diags, test.err) {
+					t.Errorf("expected %q, got %v", test.err, diags)
+				}
+				return
+			}
+			checkDiagnostics(t, diags)
+			if got := hasWarning(diags, "System account does not resolve"); got != test.unresolved {
+				t.Errorf("unresolved system account warning = %t, want %t", got, test.unresolved)
+			}
+
+			var bundle preloadJSON
+			if err := json.Unmarshal([]byte(stringAttribute(t, state, "json")), &bundle); err != nil {
+				t.Fatal(err)
+			}
+			if bundle.SystemAccount != test.want {
+				t.Errorf("system_account = %s, want %s", bundle.SystemAccount, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// provenancePlaceholder matches the placeholders of provenance tag templates.
+var provenancePlaceholder = regexp.MustCompile(`\{([a-z_]+|env:[A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// checkProvenanceTemplate returns an error if the provenance tag key or its
+// template refers to an unknown placeholder.
+func checkProvenanceTemplate(key, template string) error {
+	if key == "" || strings.ContainsAny(key, ": ") {
+		return fmt.Errorf("tag key %q must not be empty or contain colons or spaces", key)
+	}
+	for _, m := range provenancePlaceholder.FindAllStringSubmatch(template, -1) {
+		switch name := m[1]; {
+		case name == "workspace", name == "run_id", name == "type", name == "name", strings.HasPrefix(name, "env:"):
+		default:
+			return fmt.Errorf("unknown placeholder {%s} in the template of %s", name, key)
+		}
+	}
+	return nil
+}
+
+// provenanceTags renders the provenance tag templates of the provider for a
+// JWT of the resource type with the name claim. Tags whose template renders
+// empty are left out.
+func (p *providerData) provenanceTags(resourceType, name string) []string {
+	if p == nil {
+		return nil
+	}
+
+	var tags []string
+	for _, key := range p.provenanceKeys() {
+		value := provenancePlaceholder.ReplaceAllStringFunc(p.provenance[key], func(placeholder string) string {
+			switch placeholder = strings.Trim(placeholder, "{}"); placeholder {
+			case "workspace":
+				return workspace()
+			case "run_id":
+				return firstEnv("TFC_RUN_ID")
+			case "type":
+				return resourceType
+			case "name":
+				return name
+			default:
+				return os.Getenv(strings.TrimPrefix(placeholder, "env:"))
+			}
+		})
+		if value = strings.TrimSpace(value); value != "" {
+			tags = append(tags, key+":"+value)
+		}
+	}
+	return tags
+}
+
+// provenanceKeys returns the sorted keys of the provenance tags.
+func (p *providerData) provenanceKeys() []string {
+	if p == nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(p.provenance))
+	for key := range p.provenance {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// workspace returns the Terraform workspace selected in the working
+// directory Terraform runs the provider in.
+func workspace() string {
+	if name := firstEnv("TF_WORKSPACE", "TFC_WORKSPACE_NAME"); name != "" {
+		return name
+	}
+
+	dataDir := firstEnv("TF_DATA_DIR")
+	if dataDir == "" {
+		dataDir = ".terraform"
+	}
+	if name, err := os.ReadFile(filepath.Join(dataDir, "environment")); err == nil && len(bytes.TrimSpace(name)) > 0 {
+		return string(bytes.TrimSpace(name))
+	}
+	return "default"
+}
+
+// firstEnv returns the value of the first of the environment variables that
+// is set.
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
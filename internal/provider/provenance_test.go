@@ -0,0 +1,91 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestProvenanceTags(t *testing.T) {
+	t.Setenv("TF_WORKSPACE", "Production")
+	t.Setenv("TFC_RUN_ID", "run-1")
+	t.Setenv("CI_JOB_ID", "")
+
+	p := newTestProvider(t, map[string]tftypes.Value{
+		"provenance_tags": tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{
+			"tf-workspace": stringValue("{workspace}"),
+			"tf-run":       stringValue("{run_id}"),
+			"tf-resource":  stringValue("{type}.{name}"),
+			"ci-job":       stringValue("{env:CI_JOB_ID}"),
+		}),
+	})
+
+	operator, _ := nkeys.CreateOperator()
+	seed, _ := operator.Seed()
+	config := map[string]tftypes.Value{
+		"seed": stringValue(string(seed)),
+		"name": stringValue("example"),
+		"tags": tftypes.NewValue(tftypes.Set{ElementType: tftypes.String}, []tftypes.Value{stringValue("team:platform")}),
+	}
+
+	state := p.create("nkey_operator_jwt", config)
+	claims, err := jwt.DecodeOperatorClaims(stringAttribute(t, state, "jwt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags := []string(claims.Tags)
+	sort.Strings(tags)
+	// Tags are lowercased, the empty CI job ID is left out.
+	if got, want := strings.Join(tags, ","), "team:platform,tf-resource:nkey_operator_jwt.example,tf-run:run-1,tf-workspace:production"; got != want {
+		t.Errorf("tags = %s, want %s", got, want)
+	}
+
+	// Another run alone does not issue the JWT again.
+	t.Setenv("TFC_RUN_ID", "run-2")
+	resp := p.plan("nkey_operator_jwt", state, config)
+	checkDiagnostics(t, resp.Diagnostics)
+	if !attribute(t, p.value(p.resourceSchema("nkey_operator_jwt"), resp.PlannedState), "jwt").IsKnown() {
+		t.Error("jwt is issued again for another run")
+	}
+
+	// Once it is issued again, it names the run that did.
+	config["name"] = stringValue("renamed")
+	updated := p.apply("nkey_operator_jwt", state, config)
+	if claims, err = jwt.DecodeOperatorClaims(stringAttribute(t, updated, "jwt")); err != nil {
+		t.Fatal(err)
+	}
+	if !claims.Tags.Contains("tf-run:run-2") || !claims.Tags.Contains("tf-resource:nkey_operator_jwt.renamed") {
+		t.Errorf("tags = %v, want those of the second run", claims.Tags)
+	}
+}
+
+func TestCheckProvenanceTemplate(t *testing.T) {
+	for key, template := range map[string]string{
+		"tf-run":  "{run_id}",
+		"ci":      "{env:GITHUB_RUN_ID}-{env:GITHUB_RUN_ATTEMPT}",
+		"static":  "terraform",
+		"tf-type": "{type}",
+	} {
+		if err := checkProvenanceTemplate(key, template); err != nil {
+			t.Errorf("%s = %q: %s", key, template, err)
+		}
+	}
+
+	for key, template := range map[string]string{
+		"tf-address": "{address}",
+		"tf:run":     "{run_id}",
+		"":           "{run_id}",
+	} {
+		if err := checkProvenanceTemplate(key, template); err == nil {
+			t.Errorf("%s = %q: expected an error", key, template)
+		}
+	}
+}
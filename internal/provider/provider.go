@@ -5,12 +5,17 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"net/url"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Ensure NatsNkeyProvider satisfies various provider interfaces.
@@ -27,6 +32,52 @@ type NatsNkeyProvider struct {
 
 // NatsNkeyProviderModel describes the provider data model.
 type NatsNkeyProviderModel struct {
+	FIPSMode types.Bool    `tfsdk:"fips_mode"`
+	Offline  types.Bool    `tfsdk:"offline"`
+	ProxyURL types.String  `tfsdk:"proxy_url"`
+	Webhook  *WebhookModel `tfsdk:"webhook"`
+
+	TargetServerVersion types.String `tfsdk:"target_server_version"`
+	ProvenanceTags      types.Map    `tfsdk:"provenance_tags"`
+	IssuanceRegistry    types.String `tfsdk:"issuance_registry"`
+
+	MasterSeed types.String `tfsdk:"master_seed"`
+}
+
+// WebhookModel describes the webhook block of the provider.
+type WebhookModel struct {
+	URL    types.String `tfsdk:"url"`
+	Secret types.String `tfsdk:"secret"`
+}
+
+// providerData is handed to resources and data sources on configuration.
+type providerData struct {
+	fipsMode bool
+	offline  bool
+	proxyURL *url.URL
+
+	webhookURL    string
+	webhookSecret string
+
+	masterSeed []byte
+
+	// targetServerVersion is the oldest nats-server version the JWTs must
+	// work with, empty for any.
+	targetServerVersion string
+
+	// provenance are the templates of the provenance tags by tag key.
+	provenance map[string]string
+
+	// registryPath is the file every issued JWT is recorded in, empty for
+	// none.
+	registryPath string
+}
+
+// isOffline reports whether network access is disabled. Data sources that
+// would contact a NATS cluster or HTTP endpoint return placeholder results
+// instead.
+func (p *providerData) isOffline() bool {
+	return p != nil && p.offline
 }
 
 func (p *NatsNkeyProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -35,7 +86,78 @@ func (p *NatsNkeyProvider) Metadata(ctx context.Context, req provider.MetadataRe
 }
 
 func (p *NatsNkeyProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
-	resp.Schema = schema.Schema{}
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"fips_mode": schema.BoolAttribute{
+				Optional: true,
+				MarkdownDescription: "Serve random number generation, SHA-256, HMAC and TLS from the FIPS 140 validated " +
+					"BoringCrypto module and refuse X25519 curve keys and age encryption. ed25519 keys and signatures, the " +
+					"HKDF derivation of `derivation_path` and the Shamir split of `shares` are not covered by the module " +
+					"and use the Go implementations. Requires a provider binary built with `GOEXPERIMENT=boringcrypto`",
+			},
+			"offline": schema.BoolAttribute{
+				Optional: true,
+				MarkdownDescription: "Do not contact NATS servers or HTTP endpoints. Network backed resources and data sources return " +
+					"placeholder results, so configurations can be planned in air-gapped or CI environments",
+			},
+			"proxy_url": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "URL of the proxy used for HTTP requests, e.g. `http://proxy.example.com:3128`. " +
+					"Defaults to the `HTTP_PROXY`, `HTTPS_PROXY` and `NO_PROXY` environment variables",
+			},
+			"master_seed": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "Secret of at least 32 bytes that nkeys with a `derivation_path` are derived from, so the " +
+					"whole key hierarchy can be rebuilt from this single secret",
+				Sensitive: true,
+			},
+			"target_server_version": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "Oldest nats-server version the issued JWTs must work with, e.g. `2.9.0`. Claims " +
+					"the version does not support fail the plan instead of being ignored by the servers: JetStream tiered " +
+					"limits require 2.8.0, subject mappings 2.7.0, auth callout 2.10.0 and `cluster_traffic` 2.11.0",
+			},
+			"provenance_tags": schema.MapAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				MarkdownDescription: "Tags added to every operator, account, user and activation JWT when it is issued, " +
+					"to trace a credential back to the Terraform run that minted it, by tag key, e.g. " +
+					"`{ tf-workspace = \"{workspace}\", tf-run = \"{run_id}\", tf-resource = \"{type}.{name}\" }`. " +
+					"Templates may use `{workspace}`, the selected Terraform workspace, `{run_id}`, the HCP Terraform " +
+					"run ID from `TFC_RUN_ID`, `{type}`, the resource type, `{name}`, the name claim, and `{env:NAME}`, " +
+					"the environment variable `NAME`, such as a CI job ID. Terraform does not hand resource addresses " +
+					"to providers, so `{type}` and `{name}` stand in for it. Tags rendering an empty value are left " +
+					"out, and provenance tags alone never cause a JWT to be issued again",
+			},
+			"issuance_registry": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "Path of a file every JWT is recorded in when it is issued, one JSON object per " +
+					"line with the time, resource type, name, claim type, `jti`, subject, issuer, issuer account, `iat`, " +
+					"`exp`, the hex encoded SHA-256 hash of the claims and the Terraform workspace, so the credentials ever " +
+					"issued can be audited after their resources are destroyed. The file is created if needed and only " +
+					"ever appended to, it holds no secrets. JWTs that fail to be recorded are reported as warnings",
+			},
+			"webhook": schema.SingleNestedAttribute{
+				Optional: true,
+				MarkdownDescription: "Webhook receiving a JSON event with the public key and type whenever an nkey is " +
+					"created or deleted, or a JWT is issued again. A rotation is reported as a `created` event for the new " +
+					"key followed by a `deleted` event for each key pair it retires, a JWT whose claims changed as a " +
+					"`reissued` event with the subject of the JWT as public key and its claim type as type",
+				Attributes: map[string]schema.Attribute{
+					"url": schema.StringAttribute{
+						Required:            true,
+						MarkdownDescription: "URL the events are POSTed to",
+					},
+					"secret": schema.StringAttribute{
+						Optional: true,
+						MarkdownDescription: "Secret the events are signed with. The hex encoded HMAC-SHA256 of the body is " +
+							"sent in the `X-Nkey-Signature` header as `sha256=<hmac>`",
+						Sensitive: true,
+					},
+				},
+			},
+		},
+	}
 }
 
 func (p *NatsNkeyProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
@@ -47,20 +169,138 @@ func (p *NatsNkeyProvider) Configure(ctx context.Context, req provider.Configure
 		return
 	}
 
+	if data.FIPSMode.ValueBool() && !fipsCapable {
+		resp.Diagnostics.AddAttributeError(path.Root("fips_mode"), "FIPS mode not available",
+			"This provider binary was not built with the BoringCrypto module (GOEXPERIMENT=boringcrypto), "+
+				"so FIPS 140 validated cryptography cannot be guaranteed.")
+		return
+	}
+
+	var proxyURL *url.URL
+	if !data.ProxyURL.IsNull() {
+		var err error
+		proxyURL, err = url.Parse(data.ProxyURL.ValueString())
+		if err == nil && (proxyURL.Scheme == "" || proxyURL.Host == "") {
+			err = fmt.Errorf("%q is not an absolute URL", data.ProxyURL.ValueString())
+		}
+		if err != nil {
+			addError(&resp.Diagnostics, "Invalid proxy URL", errorAt(path.Root("proxy_url"), err))
+			return
+		}
+	}
+
+	var webhookURL, webhookSecret string
+	if data.Webhook != nil {
+		webhookURL = data.Webhook.URL.ValueString()
+		webhookSecret = data.Webhook.Secret.ValueString()
+
+		u, err := url.Parse(webhookURL)
+		if err == nil && (u.Scheme == "" || u.Host == "") {
+			err = fmt.Errorf("%q is not an absolute URL", webhookURL)
+		}
+		if err != nil {
+			addError(&resp.Diagnostics, "Invalid webhook URL", errorAt(path.Root("webhook").AtName("url"), err))
+			return
+		}
+	}
+
+	if n := len(data.MasterSeed.ValueString()); !data.MasterSeed.IsNull() && n < minMasterSeedLength {
+		resp.Diagnostics.AddAttributeError(path.Root("master_seed"), "Invalid master seed",
+			fmt.Sprintf("the master seed must be at least %d bytes long, got %d", minMasterSeedLength, n))
+		return
+	}
+
+	if !data.TargetServerVersion.IsNull() {
+		if _, err := parseServerVersion(data.TargetServerVersion.ValueString()); err != nil {
+			addError(&resp.Diagnostics, "Invalid target server version", errorAt(path.Root("target_server_version"), err))
+			return
+		}
+	}
+
+	provenance := map[string]string{}
+	if !data.ProvenanceTags.IsNull() {
+		resp.Diagnostics.Append(data.ProvenanceTags.ElementsAs(ctx, &provenance, false)...)
+	}
+	for key, template := range provenance {
+		if err := checkProvenanceTemplate(key, template); err != nil {
+			addError(&resp.Diagnostics, "Invalid provenance tag", errorAt(path.Root("provenance_tags").AtMapKey(key), err))
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Info(ctx, "configured nkey provider", map[string]interface{}{
+		"fips_mode":    data.FIPSMode.ValueBool(),
+		"boringcrypto": fipsCapable,
+		"offline":      data.Offline.ValueBool(),
+	})
+
+	pd := &providerData{
+		fipsMode: data.FIPSMode.ValueBool(),
+		offline:  data.Offline.ValueBool(),
+		proxyURL: proxyURL,
+
+		webhookURL:    webhookURL,
+		webhookSecret: webhookSecret,
+
+		masterSeed: []byte(data.MasterSeed.ValueString()),
+
+		targetServerVersion: data.TargetServerVersion.ValueString(),
+		provenance:          provenance,
+		registryPath:        data.IssuanceRegistry.ValueString(),
+	}
+
+	resp.DataSourceData = pd
+	resp.ResourceData = pd
 }
 
 func (p *NatsNkeyProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewNkey,
+		NewManifest,
+		NewBundle,
+		NewAccountPush,
+		NewKeyset,
+		NewOperatorJWT,
+		NewAccountJWT,
+		NewUserJWT,
+		NewActivationJWT,
+		NewCreds,
+		NewCICreds,
+		NewGenericJWT,
+		NewSystemAccount,
+		NewTenant,
+		NewLimitsProfile,
+		NewResignedJWT,
 	}
 }
 
 func (p *NatsNkeyProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
-	return []func() datasource.DataSource{}
+	return []func() datasource.DataSource{
+		NewSeedFromShares,
+		NewResolverDrift,
+		NewServerTrustedOperators,
+		NewLeafnodeAuthorization,
+		NewResolverConfig,
+		NewConfigLint,
+		NewPreloadBundle,
+		NewPermissionCheck,
+		NewPermissionAnalysis,
+		NewTopology,
+		NewKeyFile,
+		NewJWTDirectory,
+		NewFromSeed,
+		NewDecodeJWT,
+	}
 }
 
 func (p *NatsNkeyProvider) Functions(ctx context.Context) []func() function.Function {
-	return []func() function.Function{}
+	return []func() function.Function{
+		NewRedactCredsFunction,
+		NewSubjectTokenFunction,
+		NewPermissionAllowsFunction,
+	}
 }
 
 func New(version string) func() provider.Provider {
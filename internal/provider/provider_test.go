@@ -0,0 +1,445 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/nkeys"
+)
+
+// testProvider drives the provider through its protocol server the way
+// Terraform does, so schemas, validators, defaults and plan modifiers take
+// part in the tests.
+type testProvider struct {
+	t       *testing.T
+	server  tfprotov6.ProviderServer
+	schemas *tfprotov6.GetProviderSchemaResponse
+}
+
+// newTestProvider returns a test provider configured with config. Provider
+// attributes missing from config are null.
+func newTestProvider(t *testing.T, config map[string]tftypes.Value) *testProvider {
+	t.Helper()
+
+	server, err := providerserver.NewProtocol6WithError(New("test")())()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schemas, err := server.GetProviderSchema(context.Background(), &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDiagnostics(t, schemas.Diagnostics)
+
+	p := &testProvider{t: t, server: server, schemas: schemas}
+
+	resp, err := server.ConfigureProvider(context.Background(), &tfprotov6.ConfigureProviderRequest{
+		Config: p.dynamicValue(objectValue(schemas.Provider, config)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDiagnostics(t, resp.Diagnostics)
+
+	return p
+}
+
+// resourceSchema returns the schema of the resource type name.
+func (p *testProvider) resourceSchema(name string) *tfprotov6.Schema {
+	p.t.Helper()
+
+	s, ok := p.schemas.ResourceSchemas[name]
+	if !ok {
+		p.t.Fatalf("unknown resource type %s", name)
+	}
+	return s
+}
+
+// validate returns the diagnostics of validating config of the resource
+// type name.
+func (p *testProvider) validate(name string, config map[string]tftypes.Value) []*tfprotov6.Diagnostic {
+	p.t.Helper()
+
+	resp, err := p.server.ValidateResourceConfig(context.Background(), &tfprotov6.ValidateResourceConfigRequest{
+		TypeName: name,
+		Config:   p.dynamicValue(objectValue(p.resourceSchema(name), config)),
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	return resp.Diagnostics
+}
+
+// plan plans the change of the resource type name from prior, null on
+// create, to config. Like Terraform, computed attributes missing from config
+// are proposed with their prior value.
+func (p *testProvider) plan(name string, prior tftypes.Value, config map[string]tftypes.Value) *tfprotov6.PlanResourceChangeResponse {
+	p.t.Helper()
+
+	s := p.resourceSchema(name)
+	cfg := objectValue(s, config)
+	if prior.IsNull() {
+		prior = tftypes.NewValue(s.ValueType(), nil)
+	}
+
+	resp, err := p.server.PlanResourceChange(context.Background(), &tfprotov6.PlanResourceChangeRequest{
+		TypeName:         name,
+		PriorState:       p.dynamicValue(prior),
+		ProposedNewState: p.dynamicValue(proposedNewState(s, prior, cfg)),
+		Config:           p.dynamicValue(cfg),
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	return resp
+}
+
+// apply plans and applies the change of the resource type name from prior,
+// null on create, to config and returns the new state.
+func (p *testProvider) apply(name string, prior tftypes.Value, config map[string]tftypes.Value) tftypes.Value {
+	p.t.Helper()
+
+	state, diags := p.tryApply(name, prior, config)
+	checkDiagnostics(p.t, diags)
+	return state
+}
+
+// tryApply is apply returning the diagnostics of the apply instead of
+// failing on errors.
+func (p *testProvider) tryApply(name string, prior tftypes.Value, config map[string]tftypes.Value) (tftypes.Value, []*tfprotov6.Diagnostic) {
+	p.t.Helper()
+
+	planned := p.plan(name, prior, config)
+	checkDiagnostics(p.t, planned.Diagnostics)
+
+	s := p.resourceSchema(name)
+	if prior.IsNull() {
+		prior = tftypes.NewValue(s.ValueType(), nil)
+	}
+
+	resp, err := p.server.ApplyResourceChange(context.Background(), &tfprotov6.ApplyResourceChangeRequest{
+		TypeName:     name,
+		PriorState:   p.dynamicValue(prior),
+		PlannedState: planned.PlannedState,
+		Config:       p.dynamicValue(objectValue(s, config)),
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	if resp.NewState == nil {
+		return tftypes.NewValue(s.ValueType(), nil), resp.Diagnostics
+	}
+
+	return p.value(s, resp.NewState), resp.Diagnostics
+}
+
+// create creates a resource of the type name from config and returns its
+// state.
+func (p *testProvider) create(name string, config map[string]tftypes.Value) tftypes.Value {
+	p.t.Helper()
+
+	return p.apply(name, tftypes.Value{}, config)
+}
+
+// refresh reads the resource type name with state as Terraform does on
+// refresh and returns the new state, null if the resource was removed.
+func (p *testProvider) refresh(name string, state tftypes.Value) tftypes.Value {
+	p.t.Helper()
+
+	resp, err := p.server.ReadResource(context.Background(), &tfprotov6.ReadResourceRequest{
+		TypeName:     name,
+		CurrentState: p.dynamicValue(state),
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	checkDiagnostics(p.t, resp.Diagnostics)
+
+	return p.value(p.resourceSchema(name), resp.NewState)
+}
+
+// importState imports a resource of the type name by id and returns its
+// state.
+func (p *testProvider) importState(name, id string) tftypes.Value {
+	p.t.Helper()
+
+	resp, err := p.server.ImportResourceState(context.Background(), &tfprotov6.ImportResourceStateRequest{
+		TypeName: name,
+		ID:       id,
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	checkDiagnostics(p.t, resp.Diagnostics)
+
+	if len(resp.ImportedResources) != 1 {
+		p.t.Fatalf("expected 1 imported resource, got %d", len(resp.ImportedResources))
+	}
+	return p.value(p.resourceSchema(name), resp.ImportedResources[0].State)
+}
+
+// upgrade upgrades the JSON state of the resource type name from version
+// and returns the upgraded state.
+func (p *testProvider) upgrade(name string, version int64, state string) tftypes.Value {
+	p.t.Helper()
+
+	resp, err := p.server.UpgradeResourceState(context.Background(), &tfprotov6.UpgradeResourceStateRequest{
+		TypeName: name,
+		Version:  version,
+		RawState: &tfprotov6.RawState{JSON: []byte(state)},
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	checkDiagnostics(p.t, resp.Diagnostics)
+
+	return p.value(p.resourceSchema(name), resp.UpgradedState)
+}
+
+// read reads the data source type name with config and returns its state.
+func (p *testProvider) read(name string, config map[string]tftypes.Value) (tftypes.Value, []*tfprotov6.Diagnostic) {
+	p.t.Helper()
+
+	s, ok := p.schemas.DataSourceSchemas[name]
+	if !ok {
+		p.t.Fatalf("unknown data source type %s", name)
+	}
+
+	resp, err := p.server.ReadDataSource(context.Background(), &tfprotov6.ReadDataSourceRequest{
+		TypeName: name,
+		Config:   p.dynamicValue(objectValue(s, config)),
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	if resp.State == nil {
+		return tftypes.NewValue(s.ValueType(), nil), resp.Diagnostics
+	}
+	return p.value(s, resp.State), resp.Diagnostics
+}
+
+// call calls the provider function name with args and returns its result
+// of type ret, or the error of the function.
+func (p *testProvider) call(name string, ret tftypes.Type, args ...tftypes.Value) (tftypes.Value, *tfprotov6.FunctionError) {
+	p.t.Helper()
+
+	arguments := make([]*tfprotov6.DynamicValue, len(args))
+	for i, arg := range args {
+		arguments[i] = p.dynamicValue(arg)
+	}
+
+	resp, err := p.server.CallFunction(context.Background(), &tfprotov6.CallFunctionRequest{
+		Name:      name,
+		Arguments: arguments,
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	if resp.Error != nil {
+		return tftypes.Value{}, resp.Error
+	}
+
+	v, err := resp.Result.Unmarshal(ret)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	return v, nil
+}
+
+func (p *testProvider) dynamicValue(v tftypes.Value) *tfprotov6.DynamicValue {
+	p.t.Helper()
+
+	dv, err := tfprotov6.NewDynamicValue(v.Type(), v)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	return &dv
+}
+
+func (p *testProvider) value(s *tfprotov6.Schema, dv *tfprotov6.DynamicValue) tftypes.Value {
+	p.t.Helper()
+
+	v, err := dv.Unmarshal(s.ValueType())
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	return v
+}
+
+// objectValue returns the object of schema s with the attributes in attrs,
+// all other attributes are null.
+func objectValue(s *tfprotov6.Schema, attrs map[string]tftypes.Value) tftypes.Value {
+	typ := s.ValueType().(tftypes.Object)
+
+	values := map[string]tftypes.Value{}
+	for name, t := range typ.AttributeTypes {
+		values[name] = tftypes.NewValue(t, nil)
+		if v, ok := attrs[name]; ok {
+			values[name] = v
+		}
+	}
+	return tftypes.NewValue(typ, values)
+}
+
+// proposedNewState returns config with the prior values of computed
+// attributes that are not configured, as Terraform proposes it.
+func proposedNewState(s *tfprotov6.Schema, prior, config tftypes.Value) tftypes.Value {
+	if prior.IsNull() {
+		return config
+	}
+
+	var priorAttrs, configAttrs map[string]tftypes.Value
+	_ = prior.As(&priorAttrs)
+	_ = config.As(&configAttrs)
+
+	// As shares the attributes of config, which must not change.
+	proposed := make(map[string]tftypes.Value, len(configAttrs))
+	for name, v := range configAttrs {
+		proposed[name] = v
+	}
+	for _, a := range s.Block.Attributes {
+		if a.Computed && configAttrs[a.Name].IsNull() {
+			proposed[a.Name] = priorAttrs[a.Name]
+		}
+	}
+	return tftypes.NewValue(config.Type(), proposed)
+}
+
+// attribute returns the attribute name of the object v.
+func attribute(t *testing.T, v tftypes.Value, name string) tftypes.Value {
+	t.Helper()
+
+	var attrs map[string]tftypes.Value
+	if err := v.As(&attrs); err != nil {
+		t.Fatal(err)
+	}
+	a, ok := attrs[name]
+	if !ok {
+		t.Fatalf("no attribute %s", name)
+	}
+	return a
+}
+
+// stringAttribute returns the string attribute name of the object v, empty
+// if it is null.
+func stringAttribute(t *testing.T, v tftypes.Value, name string) string {
+	t.Helper()
+
+	var s *string
+	if err := attribute(t, v, name).As(&s); err != nil {
+		t.Fatal(err)
+	}
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// stringMapAttribute returns the map of strings attribute name of the object
+// v, nil if it is null.
+func stringMapAttribute(t *testing.T, v tftypes.Value, name string) map[string]string {
+	t.Helper()
+
+	var values map[string]tftypes.Value
+	if err := attribute(t, v, name).As(&values); err != nil {
+		t.Fatal(err)
+	}
+	if values == nil {
+		return nil
+	}
+	m := make(map[string]string, len(values))
+	for k, v := range values {
+		var s string
+		if err := v.As(&s); err != nil {
+			t.Fatal(err)
+		}
+		m[k] = s
+	}
+	return m
+}
+
+// verifySignature checks that token is signed by issuer, independently of
+// the decoding of the jwt package.
+func verifySignature(t *testing.T, token, issuer string) {
+	t.Helper()
+
+	i := strings.LastIndex(token, ".")
+	if i < 0 {
+		t.Fatalf("%q is not a JWT", token)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(token[i+1:])
+	if err != nil {
+		t.Fatalf("invalid signature encoding: %s", err)
+	}
+	kp, err := nkeys.FromPublicKey(issuer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := kp.Verify([]byte(token[:i]), sig); err != nil {
+		t.Errorf("JWT not signed by %s: %s", issuer, err)
+	}
+}
+
+// stringSetValue returns a set of the strings s.
+func stringSetValue(s ...string) tftypes.Value {
+	values := make([]tftypes.Value, len(s))
+	for i, s := range s {
+		values[i] = stringValue(s)
+	}
+	return tftypes.NewValue(tftypes.Set{ElementType: tftypes.String}, values)
+}
+
+func stringValue(s string) tftypes.Value {
+	return tftypes.NewValue(tftypes.String, s)
+}
+
+func numberValue(n int64) tftypes.Value {
+	return tftypes.NewValue(tftypes.Number, n)
+}
+
+func boolValue(b bool) tftypes.Value {
+	return tftypes.NewValue(tftypes.Bool, b)
+}
+
+// checkDiagnostics fails the test on error diagnostics.
+func checkDiagnostics(t *testing.T, diags []*tfprotov6.Diagnostic) {
+	t.Helper()
+
+	for _, d := range diags {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			t.Fatalf("%s: %s", d.Summary, d.Detail)
+		}
+	}
+}
+
+// hasError reports whether diags hold an error whose summary contains
+// summary.
+func hasError(diags []*tfprotov6.Diagnostic, summary string) bool {
+	for _, d := range diags {
+		if d.Severity == tfprotov6.DiagnosticSeverityError && strings.Contains(d.Summary, summary) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasWarning reports whether diags hold a warning whose summary contains
+// summary.
+func hasWarning(diags []*tfprotov6.Diagnostic, summary string) bool {
+	for _, d := range diags {
+		if d.Severity == tfprotov6.DiagnosticSeverityWarning && strings.Contains(d.Summary, summary) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"runtime/debug"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const redacted = "<redacted>"
+
+// secretPatterns match the string encodings of nkey secrets: private keys
+// start with "P", seeds are an "S" followed by the type prefix. Both are
+// base32 without padding.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\bP[A-Z2-7]{107}\b`),
+	regexp.MustCompile(`\bS[OACNUXP][A-Z2-7]{56}\b`),
+}
+
+// secretFieldKeys are log field keys whose values are always masked.
+var secretFieldKeys = []string{"seed", "private_key", "credentials", "creds", "passphrase", "master_seed"}
+
+// redactSecrets replaces every seed and private key in s.
+func redactSecrets(s string) string {
+	for _, re := range secretPatterns {
+		s = re.ReplaceAllString(s, redacted)
+	}
+	return s
+}
+
+// maskSecrets returns ctx with tflog masking seeds and private keys in the
+// messages and fields of every log entry, at all levels. Methods handling
+// key material start with
+//
+//	ctx = maskSecrets(ctx)
+//	defer recoverPanic(ctx, &resp.Diagnostics)
+func maskSecrets(ctx context.Context) context.Context {
+	ctx = tflog.MaskLogRegexes(ctx, secretPatterns...)
+	return tflog.MaskFieldValuesWithFieldKeys(ctx, secretFieldKeys...)
+}
+
+// recoverPanic turns a panic in a key handling code path into an error
+// diagnostic. Terraform prints the raw panic value and stack of a crashed
+// provider, which may include secret material, so the panic is recovered
+// and only a redacted message reaches the user. Use it as
+// `defer recoverPanic(ctx, &resp.Diagnostics)`.
+func recoverPanic(ctx context.Context, diags *diag.Diagnostics) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	message := redactSecrets(fmt.Sprint(r))
+
+	tflog.Error(ctx, "recovered from panic", map[string]interface{}{
+		"panic": message,
+		"stack": redactSecrets(string(debug.Stack())),
+	})
+
+	diags.AddError("Internal provider error",
+		"The provider encountered an unexpected error and recovered. Secret values have been removed from this "+
+			"message. Please report this issue to the provider developers.\n\n"+message)
+}
@@ -0,0 +1,68 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &RedactCredsFunction{}
+
+func NewRedactCredsFunction() function.Function {
+	return &RedactCredsFunction{}
+}
+
+// RedactCredsFunction defines the function implementation.
+type RedactCredsFunction struct{}
+
+func (f *RedactCredsFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "redact_creds"
+}
+
+func (f *RedactCredsFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Strip the seed from creds file content",
+		MarkdownDescription: "Returns the user JWT block of a NATS creds file without the nkey seed, so it can be shared in support bundles and debug output.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "creds",
+				MarkdownDescription: "Content of a NATS creds file",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *RedactCredsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var creds string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &creds))
+	if resp.Error != nil {
+		return
+	}
+
+	// ParseDecoratedJWT returns its input unchanged if there is no JWT
+	// block, which must never leak a bare seed.
+	token, err := nkeys.ParseDecoratedJWT([]byte(creds))
+	if err == nil && (strings.Count(token, ".") != 2 || redactSecrets(token) != token) {
+		err = fmt.Errorf("no user JWT found")
+	}
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, "Invalid creds: "+err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, decorateJWT(token)))
+}
+
+// decorateJWT renders token as the user JWT block of a creds file.
+func decorateJWT(token string) string {
+	return fmt.Sprintf("-----BEGIN NATS USER JWT-----\n%s\n------END NATS USER JWT------\n", token)
+}
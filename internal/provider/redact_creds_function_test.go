@@ -0,0 +1,80 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestRedactCredsFunction(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	account, _ := nkeys.CreateAccount()
+	accountKey, _ := account.PublicKey()
+	user, _ := nkeys.CreateUser()
+	userKey, _ := user.PublicKey()
+	userSeed, _ := user.Seed()
+
+	claims := jwt.NewUserClaims(userKey)
+	claims.Name = "alice"
+	token, err := claims.Encode(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds, err := jwt.FormatUserConfig(token, userSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, funcErr := p.call("redact_creds", tftypes.String, stringValue(string(creds)))
+	if funcErr != nil {
+		t.Fatal(funcErr.Text)
+	}
+	var redacted string
+	if err := result.As(&redacted); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(redacted, string(userSeed)) || strings.Contains(redacted, "SEED") {
+		t.Fatalf("the seed was not removed: %s", redacted)
+	}
+	if _, err := jwt.ParseDecoratedUserNKey([]byte(redacted)); err == nil {
+		t.Error("a key pair can be parsed from the redacted creds")
+	}
+
+	kept, err := jwt.ParseDecoratedJWT([]byte(redacted))
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifySignature(t, kept, accountKey)
+	decoded, err := jwt.DecodeUserClaims(kept)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Subject != userKey || decoded.Issuer != accountKey || decoded.Name != "alice" {
+		t.Errorf("redacted JWT of %s issued by %s, named %q", decoded.Subject, decoded.Issuer, decoded.Name)
+	}
+
+	for name, input := range map[string]string{
+		"bare seed": string(userSeed),
+		"empty":     "",
+		"garbage":   "not creds",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, funcErr := p.call("redact_creds", tftypes.String, stringValue(input))
+			if funcErr == nil || !strings.Contains(funcErr.Text, "Invalid creds") {
+				t.Fatalf("expected an invalid creds error, got %v", funcErr)
+			}
+			if strings.Contains(funcErr.Text, string(userSeed)) {
+				t.Error("the error leaks the seed")
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// registryMu serializes the appends of resources issuing JWTs concurrently.
+var registryMu sync.Mutex
+
+// registryEntry is a line of the issuance registry. It must only ever contain
+// public data.
+type registryEntry struct {
+	Time          string `json:"time"`
+	Resource      string `json:"resource"`
+	Name          string `json:"name,omitempty"`
+	Type          string `json:"type"`
+	ID            string `json:"jti,omitempty"`
+	Subject       string `json:"sub"`
+	Issuer        string `json:"iss"`
+	IssuerAccount string `json:"issuer_account,omitempty"`
+	IssuedAt      int64  `json:"iat,omitempty"`
+	Expires       int64  `json:"exp,omitempty"`
+	ClaimsSHA256  string `json:"claims_sha256"`
+	Workspace     string `json:"workspace"`
+}
+
+// register appends token, issued by resource, to the issuance registry. The
+// JWT has already been stored in state at this point, so failures are only
+// reported as warnings.
+func (p *providerData) register(ctx context.Context, resource string, token types.String, diags *diag.Diagnostics) {
+	if p == nil || p.registryPath == "" || token.IsNull() || token.IsUnknown() {
+		return
+	}
+
+	entry, err := newRegistryEntry(resource, token.ValueString())
+	if err == nil {
+		err = appendRegistry(p.registryPath, entry)
+	}
+	if err != nil {
+		diags.AddWarning("Unable to record issued JWT",
+			fmt.Sprintf("The JWT issued by %s could not be added to the issuance registry %s: %s", resource, p.registryPath, err))
+		return
+	}
+	tflog.Debug(ctx, "recorded issued JWT", map[string]interface{}{"registry": p.registryPath, "subject": entry.Subject})
+}
+
+// newRegistryEntry returns the registry entry of token issued by resource.
+func newRegistryEntry(resource, token string) (registryEntry, error) {
+	var payload json.RawMessage
+	if err := jwtClaims(token, &payload); err != nil {
+		return registryEntry{}, err
+	}
+
+	var claims struct {
+		ID       string `json:"jti"`
+		Subject  string `json:"sub"`
+		Issuer   string `json:"iss"`
+		Name     string `json:"name"`
+		IssuedAt int64  `json:"iat"`
+		Expires  int64  `json:"exp"`
+		Type     string `json:"type"`
+		Nats     struct {
+			Type          string `json:"type"`
+			IssuerAccount string `json:"issuer_account"`
+		} `json:"nats"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return registryEntry{}, err
+	}
+
+	claimType := claims.Nats.Type
+	if claimType == "" {
+		// The jwt v1 layout has the type among the registered claims.
+		claimType = claims.Type
+	}
+	if claimType == "" {
+		claimType = "generic"
+	}
+	sum := sha256.Sum256(payload)
+
+	return registryEntry{
+		Time:          time.Now().UTC().Format(time.RFC3339),
+		Resource:      resource,
+		Name:          claims.Name,
+		Type:          claimType,
+		ID:            claims.ID,
+		Subject:       claims.Subject,
+		Issuer:        claims.Issuer,
+		IssuerAccount: claims.Nats.IssuerAccount,
+		IssuedAt:      claims.IssuedAt,
+		Expires:       claims.Expires,
+		ClaimsSHA256:  hex.EncodeToString(sum[:]),
+		Workspace:     workspace(),
+	}, nil
+}
+
+// appendRegistry appends entry as a JSON line to the registry file at name,
+// creating it if needed.
+func appendRegistry(name string, entry registryEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
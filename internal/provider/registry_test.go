@@ -0,0 +1,89 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/nkeys"
+)
+
+func TestIssuanceRegistry(t *testing.T) {
+	registry := filepath.Join(t.TempDir(), "issued.jsonl")
+	p := newTestProvider(t, map[string]tftypes.Value{"issuance_registry": stringValue(registry)})
+
+	operator, _ := nkeys.CreateOperator()
+	operatorKey, _ := operator.PublicKey()
+	seed, _ := operator.Seed()
+	account, _ := nkeys.CreateAccount()
+	accountKey, _ := account.PublicKey()
+
+	config := func(name string) map[string]tftypes.Value {
+		return map[string]tftypes.Value{
+			"public_key":  stringValue(accountKey),
+			"issuer_seed": stringValue(string(seed)),
+			"name":        stringValue(name),
+		}
+	}
+	state := p.create("nkey_account_jwt", config("billing"))
+	// A JWT kept is not recorded again, one issued again is.
+	state = p.apply("nkey_account_jwt", state, config("billing"))
+	state = p.apply("nkey_account_jwt", state, config("invoicing"))
+	p.create("nkey_system_account", map[string]tftypes.Value{"issuer_seed": stringValue(string(seed))})
+
+	f, err := os.Open(registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var entries []registryEntry
+	for scanner := bufio.NewScanner(f); scanner.Scan(); {
+		var entry registryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Resource+":"+entry.Type+":"+entry.Name)
+	}
+	want := []string{
+		"nkey_account_jwt:account:billing",
+		"nkey_account_jwt:account:invoicing",
+		"nkey_system_account:account:SYS",
+		"nkey_system_account:user:sys",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("entries = %v, want %v", got, want)
+	}
+
+	last := entries[1]
+	if last.Subject != accountKey || last.Issuer != operatorKey || last.ID == "" || last.IssuedAt == 0 {
+		t.Errorf("entry = %+v", last)
+	}
+	token := stringAttribute(t, state, "jwt")
+	payload := strings.Split(token, ".")[1]
+	var claims json.RawMessage
+	if err := jwtClaims(token, &claims); err != nil {
+		t.Fatal(err)
+	}
+	if sum := sha256.Sum256(claims); last.ClaimsSHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("claims_sha256 = %s, want the hash of %s", last.ClaimsSHA256, payload)
+	}
+	if entries[3].IssuerAccount != "" || entries[3].Issuer != entries[2].Subject {
+		t.Errorf("system user entry = %+v", entries[3])
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &ResignedJWT{}
+var _ resource.ResourceWithModifyPlan = &ResignedJWT{}
+
+func NewResignedJWT() resource.Resource {
+	return &ResignedJWT{}
+}
+
+// ResignedJWT defines the resource implementation.
+type ResignedJWT struct {
+	provider *providerData
+}
+
+// ResignedJWTModel describes the resource data model.
+type ResignedJWTModel struct {
+	SourceJWT     types.String `tfsdk:"source_jwt"`
+	IssuerSeed    types.String `tfsdk:"issuer_seed"`
+	Issuer        types.String `tfsdk:"issuer"`
+	IssuerAccount types.String `tfsdk:"issuer_account"`
+	JWT           types.String `tfsdk:"jwt"`
+}
+
+func (r *ResignedJWT) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_resigned_jwt"
+}
+
+func (r *ResignedJWT) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "An existing JWT, e.g. issued by `nsc`, signed again with another issuer key while its " +
+			"claims, including expiry, are kept. Use it to move JWTs to a new signing key. The JWT is issued again " +
+			"whenever the claims of the source JWT change.",
+
+		Attributes: map[string]schema.Attribute{
+			"source_jwt": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The JWT to sign again. Its signature must be valid",
+				Sensitive:           true,
+			},
+			"issuer_seed": schema.StringAttribute{
+				Required: true,
+				MarkdownDescription: "Seed the JWT is signed with: the operator or an operator signing key for operator " +
+					"and account JWTs, the account or an account signing key for user and activation JWTs",
+				Sensitive: true,
+			},
+			"issuer": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key the JWT was signed with",
+			},
+			"issuer_account": schema.StringAttribute{
+				Computed: true,
+				MarkdownDescription: "Account of user and activation JWTs signed with an account signing key, taken from " +
+					"the source JWT. Not set if signed by the account itself",
+			},
+			"jwt": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The JWT signed with `issuer_seed`",
+				Sensitive:           true,
+			},
+		},
+	}
+}
+
+func (r *ResignedJWT) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	// Nothing to compare against on create or destroy, or before all
+	// attributes are known
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() || !req.Config.Raw.IsFullyKnown() {
+		return
+	}
+
+	var plan, state ResignedJWTModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The JWT is only signed again if its claims change. Errors are reported
+	// on apply.
+	var diags diag.Diagnostics
+	r.issue(&plan, &diags)
+	if !diags.HasError() && sameClaims(plan.JWT, state.JWT, true, false) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("issuer"), state.Issuer)...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("issuer_account"), state.IssuerAccount)...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("jwt"), state.JWT)...)
+	}
+}
+
+func (r *ResignedJWT) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.provider = data
+}
+
+func (r *ResignedJWT) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var data ResignedJWTModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.issue(&data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Trace(ctx, "created resigned JWT resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.provider.register(ctx, "nkey_resigned_jwt", data.JWT, &resp.Diagnostics)
+}
+
+func (r *ResignedJWT) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data ResignedJWTModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ResignedJWT) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var plan ResignedJWTModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The JWT in state is kept if its claims did not change.
+	reissued := plan.JWT.IsUnknown()
+	if reissued {
+		r.issue(&plan, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if reissued {
+		r.provider.notify(ctx, jwtEvent("reissued", "nkey_resigned_jwt", plan.JWT), &resp.Diagnostics)
+		r.provider.register(ctx, "nkey_resigned_jwt", plan.JWT, &resp.Diagnostics)
+	}
+}
+
+func (r *ResignedJWT) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Nothing to do here as the JWT only exists in state
+}
+
+// issue decodes the source JWT of data and signs its claims with the issuer
+// seed.
+func (r *ResignedJWT) issue(data *ResignedJWTModel, diags *diag.Diagnostics) {
+	claims, err := jwt.Decode(data.SourceJWT.ValueString())
+	if err != nil {
+		addError(diags, "Invalid source JWT", errorAt(path.Root("source_jwt"), err))
+		return
+	}
+
+	// Generic JWTs may be signed by any nkey able to sign.
+	prefixes := claims.ExpectedPrefixes()
+	if prefixes == nil {
+		prefixes = []nkeys.PrefixByte{nkeys.PrefixByteOperator, nkeys.PrefixByteAccount, nkeys.PrefixByteUser,
+			nkeys.PrefixByteServer, nkeys.PrefixByteCluster}
+	}
+
+	keys, err := jwtSigner(data.IssuerSeed.ValueString(), path.Root("issuer_seed"), prefixes...)
+	if err != nil {
+		addError(diags, "Invalid issuer seed", err)
+		return
+	}
+	defer keys.Wipe()
+
+	issuer, err := keys.PublicKey()
+	if err != nil {
+		addError(diags, "Invalid issuer seed", errorAt(path.Root("issuer_seed"), err))
+		return
+	}
+
+	// JWTs of an account carry the account if signed by one of its signing
+	// keys.
+	issuerAccount := types.StringNull()
+	switch c := claims.(type) {
+	case *jwt.UserClaims:
+		c.IssuerAccount = reissuedAccount(c.Issuer, c.IssuerAccount, issuer)
+		if c.IssuerAccount != "" {
+			issuerAccount = types.StringValue(c.IssuerAccount)
+		}
+	case *jwt.ActivationClaims:
+		c.IssuerAccount = reissuedAccount(c.Issuer, c.IssuerAccount, issuer)
+		if c.IssuerAccount != "" {
+			issuerAccount = types.StringValue(c.IssuerAccount)
+		}
+	}
+
+	token, err := encodeJWT(claims, keys, false, diags)
+	if err != nil {
+		addError(diags, "Unable to issue JWT", err)
+		return
+	}
+
+	data.Issuer = types.StringValue(issuer)
+	data.IssuerAccount = issuerAccount
+	data.JWT = types.StringValue(token)
+}
+
+// reissuedAccount returns the issuer account of a JWT signed by issuer on
+// behalf of issuerAccount, if set, once it is signed by newIssuer instead.
+func reissuedAccount(issuer, issuerAccount, newIssuer string) string {
+	account := issuerAccount
+	if account == "" {
+		account = issuer
+	}
+	if newIssuer == account {
+		return ""
+	}
+	return account
+}
@@ -0,0 +1,97 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestResignedJWT(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	account, _ := nkeys.CreateAccount()
+	accountKey, _ := account.PublicKey()
+	accountSeed, _ := account.Seed()
+	signingKey, _ := nkeys.CreateAccount()
+	signingKeyKey, _ := signingKey.PublicKey()
+	signingKeySeed, _ := signingKey.Seed()
+	user, _ := nkeys.CreateUser()
+	userKey, _ := user.PublicKey()
+
+	source := jwt.NewUserClaims(userKey)
+	source.Name = "alice"
+	source.Expires = time.Now().Add(time.Hour).Unix()
+	source.Pub.Allow.Add("orders.>")
+	sourceJWT, err := source.Encode(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	original, _ := jwt.DecodeUserClaims(sourceJWT)
+
+	tests := map[string]struct {
+		seed          []byte
+		issuer        string
+		issuerAccount string
+	}{
+		"signing key": {signingKeySeed, signingKeyKey, accountKey},
+		"account":     {accountSeed, accountKey, ""},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := p.create("nkey_resigned_jwt", map[string]tftypes.Value{
+				"source_jwt":  stringValue(sourceJWT),
+				"issuer_seed": stringValue(string(test.seed)),
+			})
+
+			token := stringAttribute(t, state, "jwt")
+			verifySignature(t, token, test.issuer)
+
+			claims, err := jwt.DecodeUserClaims(token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims.Issuer != test.issuer || claims.IssuerAccount != test.issuerAccount {
+				t.Errorf("JWT issued by %s for account %q, want %s for %q", claims.Issuer, claims.IssuerAccount, test.issuer, test.issuerAccount)
+			}
+			if got := stringAttribute(t, state, "issuer_account"); got != test.issuerAccount {
+				t.Errorf("issuer_account = %q, want %q", got, test.issuerAccount)
+			}
+			if claims.Subject != userKey || claims.Name != "alice" || !claims.Pub.Allow.Contains("orders.>") {
+				t.Errorf("claims of %s changed: %s, %v", claims.Subject, claims.Name, claims.Pub.Allow)
+			}
+			if claims.Expires != original.Expires || claims.IssuedAt != original.IssuedAt {
+				t.Errorf("validity = %d-%d, want the source validity %d-%d", claims.IssuedAt, claims.Expires, original.IssuedAt, original.Expires)
+			}
+		})
+	}
+
+	operator, _ := nkeys.CreateOperator()
+	operatorSeed, _ := operator.Seed()
+
+	for name, test := range map[string]struct {
+		source string
+		seed   []byte
+		err    string
+	}{
+		"tampered":      {sourceJWT[:len(sourceJWT)-4] + "AAAA", signingKeySeed, "Invalid source JWT"},
+		"operator seed": {sourceJWT, operatorSeed, "Invalid issuer seed"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, diags := p.tryApply("nkey_resigned_jwt", tftypes.Value{}, map[string]tftypes.Value{
+				"source_jwt":  stringValue(test.source),
+				"issuer_seed": stringValue(string(test.seed)),
+			})
+			if !hasError(diags, test.err) {
+				t.Errorf("expected %q, got %v", test.err, diags)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ResolverConfig{}
+
+func NewResolverConfig() datasource.DataSource {
+	return &ResolverConfig{}
+}
+
+// ResolverConfig defines the data source implementation.
+type ResolverConfig struct {
+}
+
+// ResolverConfigModel describes the data source data model.
+type ResolverConfigModel struct {
+	Dir         types.String `tfsdk:"dir"`
+	AllowDelete types.Bool   `tfsdk:"allow_delete"`
+	Interval    types.String `tfsdk:"interval"`
+	Limit       types.Int64  `tfsdk:"limit"`
+	Config      types.String `tfsdk:"config"`
+}
+
+func (d *ResolverConfig) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_resolver_config"
+}
+
+func (d *ResolverConfig) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Renders the nats-server `resolver` block of a full (directory based) account resolver.",
+
+		Attributes: map[string]schema.Attribute{
+			"dir": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Directory the resolver stores account JWTs in",
+			},
+			"allow_delete": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Whether accounts may be deleted from the resolver. Defaults to false",
+			},
+			"interval": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Interval at which the resolvers of a cluster synchronize, as a duration. Defaults to `2m`",
+			},
+			"limit": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Maximum number of account JWTs to store. Defaults to 1000",
+			},
+			"config": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The rendered configuration",
+			},
+		},
+	}
+}
+
+func (d *ResolverConfig) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ResolverConfigModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	interval := "2m"
+	if !data.Interval.IsNull() {
+		interval = data.Interval.ValueString()
+	}
+	if _, err := time.ParseDuration(interval); err != nil {
+		addError(&resp.Diagnostics, "Invalid resolver interval", errorAt(path.Root("interval"), err))
+		return
+	}
+
+	limit := int64(1000)
+	if !data.Limit.IsNull() {
+		limit = data.Limit.ValueInt64()
+	}
+
+	w := &confWriter{}
+
+	w.open("resolver")
+	w.line("type: full")
+	w.line("dir: %s", quote(data.Dir.ValueString()))
+	w.line("allow_delete: %t", data.AllowDelete.ValueBool())
+	w.line("interval: %s", quote(interval))
+	w.line("limit: %d", limit)
+	w.close()
+
+	data.Config = types.StringValue(w.String())
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
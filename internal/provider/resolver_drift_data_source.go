@@ -0,0 +1,242 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/datasource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ResolverDrift{}
+var _ datasource.DataSourceWithConfigure = &ResolverDrift{}
+
+func NewResolverDrift() datasource.DataSource {
+	return &ResolverDrift{}
+}
+
+// ResolverDrift defines the data source implementation.
+type ResolverDrift struct {
+	provider *providerData
+}
+
+// ResolverDriftModel describes the data source data model.
+type ResolverDriftModel struct {
+	Servers     types.List   `tfsdk:"servers"`
+	Credentials types.String `tfsdk:"credentials"`
+	Accounts    types.Map    `tfsdk:"accounts"`
+	Status      types.Map    `tfsdk:"status"`
+	Drifted     types.List   `tfsdk:"drifted"`
+	Missing     types.List   `tfsdk:"missing"`
+	InSync      types.Bool   `tfsdk:"in_sync"`
+
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+}
+
+const (
+	driftInSync  = "in_sync"
+	driftDrifted = "drifted"
+	driftMissing = "missing"
+	driftOffline = "offline"
+)
+
+func (d *ResolverDrift) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_resolver_drift"
+}
+
+func (d *ResolverDrift) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Compares account JWTs against what the account resolver of a NATS cluster currently serves, " +
+			"to surface changes made outside of Terraform, e.g. by `nsc push`. The `timeouts` block bounds the " +
+			"connection and all lookups, otherwise every lookup is bounded by 5 seconds.",
+
+		Attributes: map[string]schema.Attribute{
+			"servers": schema.ListAttribute{
+				Required:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "NATS server URLs to connect to",
+			},
+			"credentials": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Content of a creds file of a system account user",
+				Sensitive:           true,
+			},
+			"accounts": schema.MapAttribute{
+				Required:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Map of account public keys to the account JWT expected to be served",
+			},
+			"status": schema.MapAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Map of account public keys to one of `in_sync`, `drifted` or `missing`, or `offline` if the provider is offline",
+			},
+			"drifted": schema.ListAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Public keys of accounts for which the resolver serves a different JWT",
+			},
+			"missing": schema.ListAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Public keys of accounts the resolver does not know",
+			},
+			"in_sync": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the resolver serves exactly the expected JWT for every account. Always true if the provider is offline",
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx),
+		},
+	}
+}
+
+func (d *ResolverDrift) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.provider = data
+}
+
+func (d *ResolverDrift) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var data ResolverDriftModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var servers []string
+	accounts := map[string]string{}
+
+	resp.Diagnostics.Append(data.Servers.ElementsAs(ctx, &servers, false)...)
+	resp.Diagnostics.Append(data.Accounts.ElementsAs(ctx, &accounts, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for account := range accounts {
+		if !nkeys.IsValidPublicAccountKey(account) {
+			resp.Diagnostics.AddAttributeError(path.Root("accounts").AtMapKey(account), "Invalid account public key",
+				fmt.Sprintf("%q is not an account public key", account))
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if d.provider.isOffline() {
+		resp.Diagnostics.AddWarning("Resolver not checked",
+			"The provider is configured offline, all accounts are reported as offline and in sync.")
+
+		status := map[string]attr.Value{}
+		for account := range accounts {
+			status[account] = types.StringValue(driftOffline)
+		}
+
+		data.Status = types.MapValueMust(types.StringType, status)
+		data.Drifted = types.ListValueMust(types.StringType, []attr.Value{})
+		data.Missing = types.ListValueMust(types.StringType, []attr.Value{})
+		data.InSync = types.BoolValue(true)
+
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
+	readTimeout, diags := data.Timeouts.Read(ctx, 0)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, readTimeout)
+	defer cancel()
+
+	nc, err := connectNATS(ctx, servers, data.Credentials.ValueString())
+	if err != nil {
+		addError(&resp.Diagnostics, "Unable to connect to NATS", err)
+		return
+	}
+	defer nc.Close()
+
+	keys := make([]string, 0, len(accounts))
+	for account := range accounts {
+		keys = append(keys, account)
+	}
+	sort.Strings(keys)
+
+	status := map[string]attr.Value{}
+	var drifted, missing []attr.Value
+
+	for _, account := range keys {
+		served, err := lookupAccountJWT(ctx, nc, account)
+		if err != nil {
+			addError(&resp.Diagnostics, "Unable to look up account JWT",
+				errorAt(path.Root("accounts").AtMapKey(account), err))
+			return
+		}
+
+		switch {
+		case served == "":
+			status[account] = types.StringValue(driftMissing)
+			missing = append(missing, types.StringValue(account))
+		case jwtHash(served) != jwtHash(accounts[account]):
+			status[account] = types.StringValue(driftDrifted)
+			drifted = append(drifted, types.StringValue(account))
+		default:
+			status[account] = types.StringValue(driftInSync)
+		}
+	}
+
+	data.Status = types.MapValueMust(types.StringType, status)
+	data.Drifted = types.ListValueMust(types.StringType, nonNil(drifted))
+	data.Missing = types.ListValueMust(types.StringType, nonNil(missing))
+	data.InSync = types.BoolValue(len(drifted) == 0 && len(missing) == 0)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// jwtHash returns the hex encoded SHA-256 hash of an encoded JWT.
+func jwtHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func nonNil(values []attr.Value) []attr.Value {
+	if values == nil {
+		return []attr.Value{}
+	}
+	return values
+}
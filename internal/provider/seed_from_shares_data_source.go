@@ -0,0 +1,128 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"terraform-provider-nkey/internal/shamir"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &SeedFromShares{}
+
+func NewSeedFromShares() datasource.DataSource {
+	return &SeedFromShares{}
+}
+
+// SeedFromShares defines the data source implementation.
+type SeedFromShares struct {
+}
+
+// SeedFromSharesModel describes the data source data model.
+type SeedFromSharesModel struct {
+	Shares    types.List   `tfsdk:"shares"`
+	KeyType   types.String `tfsdk:"type"`
+	PublicKey types.String `tfsdk:"public_key"`
+	Seed      types.String `tfsdk:"seed"`
+}
+
+func (d *SeedFromShares) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_seed_from_shares"
+}
+
+func (d *SeedFromShares) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Recombines an nkey seed from Shamir secret shares created by the `shares` option of `nkey_nkey`.",
+
+		Attributes: map[string]schema.Attribute{
+			"shares": schema.ListAttribute{
+				Required:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "At least `share_threshold` of the hex encoded seed shares",
+				Sensitive:           true,
+			},
+			"type": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The type of the recombined nkey",
+			},
+			"public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the recombined nkey",
+			},
+			"seed": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Recombined seed of the nkey",
+				Sensitive:           true,
+			},
+		},
+	}
+}
+
+func (d *SeedFromShares) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var data SeedFromSharesModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var encoded []string
+	resp.Diagnostics.Append(data.Shares.ElementsAs(ctx, &encoded, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	shares := make([][]byte, len(encoded))
+	for i, e := range encoded {
+		share, err := hex.DecodeString(e)
+		if err != nil {
+			addError(&resp.Diagnostics, "Invalid seed share", errorAt(path.Root("shares").AtListIndex(i), err))
+			return
+		}
+		shares[i] = share
+	}
+
+	seed, err := shamir.Combine(shares)
+	if err != nil {
+		addError(&resp.Diagnostics, "Unable to recombine seed", errorAt(path.Root("shares"), err))
+		return
+	}
+	defer clear(seed)
+
+	keys, err := nkeys.FromSeed(seed)
+	if err != nil {
+		addError(&resp.Diagnostics, "Unable to recombine seed", errorAt(path.Root("shares"),
+			fmt.Errorf("the shares do not form a valid seed, are at least share_threshold shares given? %w", err)))
+		return
+	}
+	defer keys.Wipe()
+
+	pubKey, err := keys.PublicKey()
+	if err != nil {
+		addError(&resp.Diagnostics, "Unable to recombine seed", err)
+		return
+	}
+
+	data.KeyType = types.StringValue(keyTypeName(nkeys.Prefix(pubKey)))
+	data.PublicKey = types.StringValue(pubKey)
+	data.Seed = types.StringValue(string(seed))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
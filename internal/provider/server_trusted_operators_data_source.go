@@ -0,0 +1,211 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/datasource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ServerTrustedOperators{}
+var _ datasource.DataSourceWithConfigure = &ServerTrustedOperators{}
+
+func NewServerTrustedOperators() datasource.DataSource {
+	return &ServerTrustedOperators{}
+}
+
+// ServerTrustedOperators defines the data source implementation.
+type ServerTrustedOperators struct {
+	provider *providerData
+}
+
+// ServerTrustedOperatorsModel describes the data source data model.
+type ServerTrustedOperatorsModel struct {
+	MonitoringURL      types.String `tfsdk:"monitoring_url"`
+	ServerID           types.String `tfsdk:"server_id"`
+	OperatorPublicKeys types.List   `tfsdk:"operator_public_keys"`
+	OperatorJWTs       types.List   `tfsdk:"operator_jwts"`
+	SystemAccount      types.String `tfsdk:"system_account"`
+
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+}
+
+// varz is the subset of the /varz monitoring endpoint used here.
+type varz struct {
+	ID                    string   `json:"server_id"`
+	SystemAccount         string   `json:"system_account"`
+	TrustedOperatorsJWT   []string `json:"trusted_operators_jwt"`
+	TrustedOperatorsClaim []struct {
+		Subject string `json:"sub"`
+	} `json:"trusted_operators_claim"`
+}
+
+func (d *ServerTrustedOperators) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_server_trusted_operators"
+}
+
+func (d *ServerTrustedOperators) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Lists the operators a NATS server trusts, as reported by its monitoring endpoint. The " +
+			"request is bounded by the `timeouts` block, or by 10 seconds.",
+
+		Attributes: map[string]schema.Attribute{
+			"monitoring_url": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Base URL of the server's HTTP monitoring endpoint, e.g. `http://nats.example.com:8222`",
+			},
+			"server_id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "ID of the server that answered",
+			},
+			"operator_public_keys": schema.ListAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Public keys of the trusted operators",
+			},
+			"operator_jwts": schema.ListAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "JWTs of the trusted operators",
+			},
+			"system_account": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the system account configured on the server",
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx),
+		},
+	}
+}
+
+func (d *ServerTrustedOperators) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.provider = data
+}
+
+func (d *ServerTrustedOperators) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ServerTrustedOperatorsModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if d.provider.isOffline() {
+		resp.Diagnostics.AddWarning("Server not contacted",
+			"The provider is configured offline, no trusted operators are reported.")
+
+		data.ServerID = types.StringValue("")
+		data.OperatorPublicKeys = types.ListValueMust(types.StringType, []attr.Value{})
+		data.OperatorJWTs = types.ListValueMust(types.StringType, []attr.Value{})
+		data.SystemAccount = types.StringValue("")
+
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
+	readTimeout, diags := data.Timeouts.Read(ctx, 0)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, readTimeout)
+	defer cancel()
+
+	var v varz
+	url := strings.TrimSuffix(data.MonitoringURL.ValueString(), "/") + "/varz"
+	if err := getJSON(ctx, d.provider.httpClient(), url, &v); err != nil {
+		addError(&resp.Diagnostics, "Unable to read server information", errorAt(path.Root("monitoring_url"), err))
+		return
+	}
+
+	keys := make([]string, 0, len(v.TrustedOperatorsJWT))
+	for i, token := range v.TrustedOperatorsJWT {
+		if i < len(v.TrustedOperatorsClaim) && v.TrustedOperatorsClaim[i].Subject != "" {
+			keys = append(keys, v.TrustedOperatorsClaim[i].Subject)
+			continue
+		}
+
+		sub, err := jwtSubject(token)
+		if err != nil {
+			addError(&resp.Diagnostics, "Unable to decode trusted operator JWT", err)
+			return
+		}
+		keys = append(keys, sub)
+	}
+
+	operatorKeys, diags := types.ListValueFrom(ctx, types.StringType, keys)
+	resp.Diagnostics.Append(diags...)
+	operatorJWTs, diags := types.ListValueFrom(ctx, types.StringType, append([]string{}, v.TrustedOperatorsJWT...))
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ServerID = types.StringValue(v.ID)
+	data.OperatorPublicKeys = operatorKeys
+	data.OperatorJWTs = operatorJWTs
+	data.SystemAccount = types.StringValue(v.SystemAccount)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// jwtSubject returns the subject of an encoded JWT without verifying it.
+func jwtSubject(token string) (string, error) {
+	var claims struct {
+		Subject string `json:"sub"`
+	}
+	if err := jwtClaims(token, &claims); err != nil {
+		return "", err
+	}
+
+	return claims.Subject, nil
+}
+
+// jwtClaims decodes the payload of an encoded JWT into v without verifying
+// its signature.
+func jwtClaims(token string, v interface{}) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return errors.New("malformed JWT")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(payload, v)
+}
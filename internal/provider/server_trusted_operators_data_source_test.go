@@ -0,0 +1,87 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestServerTrustedOperators(t *testing.T) {
+	operator, _ := nkeys.CreateOperator()
+	operatorKey, _ := operator.PublicKey()
+	token, err := jwt.NewOperatorClaims(operatorKey).Encode(operator)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/varz" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"server_id":             "NSERVER",
+			"system_account":        "ASYS",
+			"trusted_operators_jwt": []string{token},
+		})
+	}))
+	defer server.Close()
+
+	p := newTestProvider(t, nil)
+
+	state, diags := p.read("nkey_server_trusted_operators", map[string]tftypes.Value{
+		"monitoring_url": stringValue(server.URL + "/"),
+	})
+	checkDiagnostics(t, diags)
+
+	if got := stringAttribute(t, state, "server_id"); got != "NSERVER" {
+		t.Errorf("server_id = %q", got)
+	}
+	var keys []tftypes.Value
+	if err := attribute(t, state, "operator_public_keys").As(&keys); err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || !keys[0].Equal(stringValue(operatorKey)) {
+		t.Errorf("operator_public_keys = %v, want [%s]", keys, operatorKey)
+	}
+}
+
+func TestServerTrustedOperatorsTimeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	p := newTestProvider(t, nil)
+	timeoutsType := p.schemas.DataSourceSchemas["nkey_server_trusted_operators"].ValueType().(tftypes.Object).AttributeTypes["timeouts"]
+
+	start := time.Now()
+	_, diags := p.read("nkey_server_trusted_operators", map[string]tftypes.Value{
+		"monitoring_url": stringValue(server.URL),
+		"timeouts": tftypes.NewValue(timeoutsType, map[string]tftypes.Value{
+			"read": stringValue("200ms"),
+		}),
+	})
+
+	if !hasError(diags, "Unable to read server information") {
+		t.Fatalf("expected a timeout error, got %v", diags)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("read took %s despite a timeout of 200ms", elapsed)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/jwt/v2"
+)
+
+// serverFeature is a claim set by the top-level attribute that nats-server
+// only supports from version on. Older servers ignore it.
+type serverFeature struct {
+	attribute string
+	name      string
+	version   string
+}
+
+// usedFeatures returns the features whose attribute is set in config, known
+// or not.
+func usedFeatures(config tftypes.Value, features []serverFeature) []serverFeature {
+	var attrs map[string]tftypes.Value
+	if err := config.As(&attrs); err != nil {
+		return nil
+	}
+
+	var used []serverFeature
+	for _, f := range features {
+		if v, ok := attrs[f.attribute]; ok && !v.IsNull() {
+			used = append(used, f)
+		}
+	}
+	return used
+}
+
+// serverVersion is a parsed nats-server version.
+type serverVersion [3]int
+
+// parseServerVersion parses a nats-server version such as 2.10.0.
+func parseServerVersion(s string) (serverVersion, error) {
+	major, minor, update, err := jwt.ParseServerVersion(s)
+	return serverVersion{major, minor, update}, err
+}
+
+// less reports whether v is older than w.
+func (v serverVersion) less(w serverVersion) bool {
+	for i := range v {
+		if v[i] != w[i] {
+			return v[i] < w[i]
+		}
+	}
+	return false
+}
+
+// checkServerVersion adds an error for each of the features the target
+// server version of the provider does not support. Without a target server
+// version every feature is allowed.
+func (p *providerData) checkServerVersion(features []serverFeature, diags *diag.Diagnostics) {
+	if p == nil || p.targetServerVersion == "" {
+		return
+	}
+	target, _ := parseServerVersion(p.targetServerVersion)
+
+	for _, f := range features {
+		if required, _ := parseServerVersion(f.version); target.less(required) {
+			diags.AddAttributeError(path.Root(f.attribute), "Claim not supported by the target server",
+				fmt.Sprintf("%s requires nats-server %s, but the provider targets nats-server %s, which ignores it. "+
+					"Remove it or raise target_server_version once all servers are upgraded.",
+					f.name, f.version, p.targetServerVersion))
+		}
+	}
+}
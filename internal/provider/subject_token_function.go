@@ -0,0 +1,104 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"io"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &SubjectTokenFunction{}
+
+// subjectTokenAlphabet only contains characters that are safe in NATS
+// subjects, queue names and inbox prefixes.
+const subjectTokenAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+const maxSubjectTokenLength = 256
+
+func NewSubjectTokenFunction() function.Function {
+	return &SubjectTokenFunction{}
+}
+
+// SubjectTokenFunction defines the function implementation.
+type SubjectTokenFunction struct{}
+
+func (f *SubjectTokenFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "subject_token"
+}
+
+func (f *SubjectTokenFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Derive a NATS subject safe token",
+		MarkdownDescription: "Derives a token of the given length consisting only of letters and digits, safe for use in NATS " +
+			"subjects, queue names and inbox prefixes. Provider functions have to be pure, so the randomness is taken from " +
+			"`seed`, e.g. the `hex` of a `random_id` resource or a public key: the same seed always yields the same token.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "seed",
+				MarkdownDescription: "Random input the token is derived from",
+			},
+			function.Int64Parameter{
+				Name:                "length",
+				MarkdownDescription: "Length of the token, between 1 and 256",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *SubjectTokenFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var (
+		seed   string
+		length int64
+	)
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &seed, &length))
+	if resp.Error != nil {
+		return
+	}
+
+	if seed == "" {
+		resp.Error = function.NewArgumentFuncError(0, "seed must not be empty")
+		return
+	}
+	if length < 1 || length > maxSubjectTokenLength {
+		resp.Error = function.NewArgumentFuncError(1, "length must be between 1 and 256")
+		return
+	}
+
+	token, err := subjectToken(seed, int(length))
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, token))
+}
+
+// subjectToken expands seed with HKDF and maps the output onto the token
+// alphabet. Bytes that would bias the distribution are skipped.
+func subjectToken(seed string, length int) (string, error) {
+	r := hkdf.New(sha256.New, []byte(seed), nil, []byte("nkey subject token"))
+
+	limit := byte(256 - 256%len(subjectTokenAlphabet))
+	token := make([]byte, 0, length)
+	buf := make([]byte, 1)
+
+	for len(token) < length {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return "", err
+		}
+		if buf[0] >= limit {
+			continue
+		}
+		token = append(token, subjectTokenAlphabet[int(buf[0])%len(subjectTokenAlphabet)])
+	}
+
+	return string(token), nil
+}
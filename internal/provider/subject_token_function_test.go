@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"strings"
+	"terraform-provider-nkey/internal/subject"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestSubjectTokenFunction(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	token := func(t *testing.T, seed string, length int64) string {
+		t.Helper()
+
+		result, funcErr := p.call("subject_token", tftypes.String, stringValue(seed), numberValue(length))
+		if funcErr != nil {
+			t.Fatal(funcErr.Text)
+		}
+		var s string
+		if err := result.As(&s); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	for _, length := range []int64{1, 16, maxSubjectTokenLength} {
+		s := token(t, "5f2c8e", length)
+		if int64(len(s)) != length {
+			t.Errorf("token of length %d has %d characters", length, len(s))
+		}
+		if strings.Trim(s, subjectTokenAlphabet) != "" {
+			t.Errorf("token %q has characters outside of the alphabet", s)
+		}
+		if err := subject.Validate("inbox."+s+".>", true); err != nil {
+			t.Errorf("token %q is not a valid subject token: %s", s, err)
+		}
+	}
+
+	if token(t, "5f2c8e", 16) != token(t, "5f2c8e", 16) {
+		t.Error("the same seed yields different tokens")
+	}
+	if token(t, "5f2c8e", 16) == token(t, "5f2c8f", 16) {
+		t.Error("different seeds yield the same token")
+	}
+	if !strings.HasPrefix(token(t, "5f2c8e", 32), token(t, "5f2c8e", 16)) {
+		t.Error("a longer token does not extend the shorter one of the same seed")
+	}
+
+	for name, test := range map[string]struct {
+		seed     string
+		length   int64
+		argument int64
+	}{
+		"empty seed":  {"", 16, 0},
+		"zero length": {"5f2c8e", 0, 1},
+		"too long":    {"5f2c8e", maxSubjectTokenLength + 1, 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, funcErr := p.call("subject_token", tftypes.String, stringValue(test.seed), numberValue(test.length))
+			if funcErr == nil || funcErr.FunctionArgument == nil || *funcErr.FunctionArgument != test.argument {
+				t.Errorf("expected an error at argument %d, got %v", test.argument, funcErr)
+			}
+		})
+	}
+}
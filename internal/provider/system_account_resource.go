@@ -0,0 +1,386 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &SystemAccount{}
+var _ resource.ResourceWithConfigure = &SystemAccount{}
+var _ resource.ResourceWithModifyPlan = &SystemAccount{}
+
+func NewSystemAccount() resource.Resource {
+	return &SystemAccount{}
+}
+
+// SystemAccount defines the resource implementation.
+type SystemAccount struct {
+	provider *providerData
+}
+
+// SystemAccountModel describes the resource data model.
+type SystemAccountModel struct {
+	IssuerSeed    types.String `tfsdk:"issuer_seed"`
+	Name          types.String `tfsdk:"name"`
+	UserName      types.String `tfsdk:"user_name"`
+	PublicKey     types.String `tfsdk:"public_key"`
+	Seed          types.String `tfsdk:"seed"`
+	JWT           types.String `tfsdk:"jwt"`
+	UserPublicKey types.String `tfsdk:"user_public_key"`
+	UserSeed      types.String `tfsdk:"user_seed"`
+	UserJWT       types.String `tfsdk:"user_jwt"`
+	Creds         types.String `tfsdk:"creds"`
+}
+
+// systemAccountExports returns the exports nsc adds to a system account,
+// which let other accounts monitor themselves.
+func systemAccountExports() []*jwt.Export {
+	const infoURL = "https://docs.nats.io/nats-server/configuration/sys_accounts"
+
+	return []*jwt.Export{
+		{
+			Name:                 "account-monitoring-streams",
+			Subject:              "$SYS.ACCOUNT.*.>",
+			Type:                 jwt.Stream,
+			AccountTokenPosition: 3,
+			Info: jwt.Info{
+				Description: "Account specific monitoring stream",
+				InfoURL:     infoURL,
+			},
+		},
+		{
+			Name:                 "account-monitoring-services",
+			Subject:              "$SYS.REQ.ACCOUNT.*.*",
+			Type:                 jwt.Service,
+			ResponseType:         jwt.ResponseTypeStream,
+			AccountTokenPosition: 4,
+			Info: jwt.Info{
+				Description: "Request account specific monitoring services for: SUBSZ, CONNZ, LEAFZ, JSZ and INFO",
+				InfoURL:     infoURL,
+			},
+		},
+	}
+}
+
+// systemAccountImports returns the imports of account that use the exports
+// of systemAccountExports of the system account system: the monitoring
+// streams, which carry the connection events of the account, if streams is
+// set and the monitoring services if services is set.
+func systemAccountImports(system, account string, streams, services bool) []*jwt.Import {
+	var imports []*jwt.Import
+	if streams {
+		imports = append(imports, &jwt.Import{
+			Name:    "account-monitoring-streams",
+			Account: system,
+			Subject: jwt.Subject("$SYS.ACCOUNT." + account + ".>"),
+			Type:    jwt.Stream,
+		})
+	}
+	if services {
+		imports = append(imports, &jwt.Import{
+			Name:    "account-monitoring-services",
+			Account: system,
+			Subject: jwt.Subject("$SYS.REQ.ACCOUNT." + account + ".*"),
+			Type:    jwt.Service,
+		})
+	}
+	return imports
+}
+
+func (r *SystemAccount) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_system_account"
+}
+
+func (r *SystemAccount) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "The system account of an operator: the account nkey and its JWT with the `$SYS` " +
+			"monitoring exports, plus a system user with its JWT and creds. Set `system_account` of the operator JWT " +
+			"to `public_key`. The keys are kept, the JWTs are issued again whenever their claims change.",
+
+		Attributes: map[string]schema.Attribute{
+			"issuer_seed": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Seed of the operator or an operator signing key the account JWT is signed with",
+				Sensitive:           true,
+			},
+			"name": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("SYS"),
+				MarkdownDescription: "Name of the system account. Defaults to `SYS`",
+			},
+			"user_name": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("sys"),
+				MarkdownDescription: "Name of the system user. Defaults to `sys`",
+			},
+			"public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the system account",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"seed": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Seed of the system account",
+				Sensitive:           true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"jwt": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The encoded system account JWT",
+			},
+			"user_public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the system user",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"user_seed": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Seed of the system user",
+				Sensitive:           true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"user_jwt": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The encoded system user JWT",
+				Sensitive:           true,
+			},
+			"creds": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Creds file content of the system user",
+				Sensitive:           true,
+			},
+		},
+	}
+}
+
+func (r *SystemAccount) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.provider = data
+}
+
+func (r *SystemAccount) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	// Nothing to compare against on create or destroy, or before all
+	// attributes are known
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() || !req.Config.Raw.IsFullyKnown() {
+		return
+	}
+
+	var plan, state SystemAccountModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Each JWT is only signed again if its claims change. Errors are
+	// reported on apply.
+	var diags diag.Diagnostics
+	r.issue(&plan, &diags)
+	if diags.HasError() {
+		return
+	}
+	if sameClaims(plan.JWT, state.JWT, false, false) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("jwt"), state.JWT)...)
+	}
+	if sameClaims(plan.UserJWT, state.UserJWT, false, false) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("user_jwt"), state.UserJWT)...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("creds"), state.Creds)...)
+	}
+}
+
+func (r *SystemAccount) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var data SystemAccountModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	account, err := newKeysetKey(nkeys.PrefixByteAccount)
+	if err != nil {
+		addError(&resp.Diagnostics, "Unable to generate nkey", err)
+		return
+	}
+	user, err := newKeysetKey(nkeys.PrefixByteUser)
+	if err != nil {
+		addError(&resp.Diagnostics, "Unable to generate nkey", err)
+		return
+	}
+	data.PublicKey = types.StringValue(account.publicKey)
+	data.Seed = types.StringValue(account.seed)
+	data.UserPublicKey = types.StringValue(user.publicKey)
+	data.UserSeed = types.StringValue(user.seed)
+
+	r.issue(&data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Trace(ctx, "created system account resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.provider.register(ctx, "nkey_system_account", data.JWT, &resp.Diagnostics)
+	r.provider.register(ctx, "nkey_system_account", data.UserJWT, &resp.Diagnostics)
+}
+
+func (r *SystemAccount) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data SystemAccountModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SystemAccount) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var plan SystemAccountModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The JWTs in state are kept if their claims did not change.
+	accountIssued, userIssued := plan.JWT.IsUnknown(), plan.UserJWT.IsUnknown()
+	if accountIssued || userIssued {
+		issued := plan
+		r.issue(&issued, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if accountIssued {
+			plan.JWT = issued.JWT
+		}
+		if userIssued {
+			plan.UserJWT = issued.UserJWT
+			plan.Creds = issued.Creds
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if accountIssued {
+		r.provider.register(ctx, "nkey_system_account", plan.JWT, &resp.Diagnostics)
+	}
+	if userIssued {
+		r.provider.register(ctx, "nkey_system_account", plan.UserJWT, &resp.Diagnostics)
+	}
+}
+
+func (r *SystemAccount) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Nothing to do here as the keys and JWTs only exist in state
+}
+
+// issue encodes the system account JWT, signed with the issuer seed, and the
+// system user JWT and creds, signed with the system account seed.
+func (r *SystemAccount) issue(data *SystemAccountModel, diags *diag.Diagnostics) {
+	issuerKeys, err := jwtSigner(data.IssuerSeed.ValueString(), path.Root("issuer_seed"), nkeys.PrefixByteOperator)
+	if err != nil {
+		addError(diags, "Invalid issuer seed", err)
+		return
+	}
+	defer issuerKeys.Wipe()
+
+	accountKeys, err := jwtSigner(data.Seed.ValueString(), path.Root("seed"), nkeys.PrefixByteAccount)
+	if err != nil {
+		addError(diags, "Invalid system account seed in state", err)
+		return
+	}
+	defer accountKeys.Wipe()
+
+	account := jwt.NewAccountClaims(data.PublicKey.ValueString())
+	account.Name = data.Name.ValueString()
+	account.Exports.Add(systemAccountExports()...)
+
+	token, err := encodeJWT(account, issuerKeys, false, diags)
+	if err != nil {
+		addError(diags, "Unable to issue system account JWT", err)
+		return
+	}
+
+	user := jwt.NewUserClaims(data.UserPublicKey.ValueString())
+	user.Name = data.UserName.ValueString()
+
+	userToken, err := encodeJWT(user, accountKeys, false, diags)
+	if err != nil {
+		addError(diags, "Unable to issue system user JWT", err)
+		return
+	}
+
+	creds, err := jwt.FormatUserConfig(userToken, []byte(data.UserSeed.ValueString()))
+	if err != nil {
+		addError(diags, "Unable to render system user creds", err)
+		return
+	}
+	defer clear(creds)
+
+	data.JWT = types.StringValue(token)
+	data.UserJWT = types.StringValue(userToken)
+	data.Creds = types.StringValue(string(creds))
+}
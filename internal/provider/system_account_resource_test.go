@@ -0,0 +1,86 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/nkeys"
+)
+
+func TestSystemAccountReissue(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	operator, _ := nkeys.CreateOperator()
+	seed, _ := operator.Seed()
+	other, _ := nkeys.CreateOperator()
+	otherSeed, _ := other.Seed()
+
+	config := map[string]tftypes.Value{"issuer_seed": stringValue(string(seed))}
+	state := p.create("nkey_system_account", config)
+
+	tests := []struct {
+		name   string
+		config map[string]tftypes.Value
+		// account and user report whether the account and user JWTs are
+		// issued again.
+		account, user bool
+	}{
+		{
+			name:   "issuer with the same key",
+			config: map[string]tftypes.Value{"issuer_seed": stringValue(" " + string(seed) + "\n")},
+		},
+		{
+			name:   "user name",
+			config: map[string]tftypes.Value{"issuer_seed": stringValue(string(seed)), "user_name": stringValue("admin")},
+			user:   true,
+		},
+		{
+			name:    "account name",
+			config:  map[string]tftypes.Value{"issuer_seed": stringValue(string(seed)), "name": stringValue("SYSTEM"), "user_name": stringValue("admin")},
+			account: true,
+		},
+		{
+			name:    "issuer",
+			config:  map[string]tftypes.Value{"issuer_seed": stringValue(string(otherSeed)), "name": stringValue("SYSTEM"), "user_name": stringValue("admin")},
+			account: true,
+		},
+	}
+
+	for _, test := range tests {
+		resp := p.plan("nkey_system_account", state, test.config)
+		checkDiagnostics(t, resp.Diagnostics)
+		planned := p.value(p.resourceSchema("nkey_system_account"), resp.PlannedState)
+
+		for _, attr := range []struct {
+			name     string
+			reissued bool
+		}{
+			{"jwt", test.account},
+			{"user_jwt", test.user},
+			{"creds", test.user},
+		} {
+			if known := attribute(t, planned, attr.name).IsKnown(); known == attr.reissued {
+				t.Errorf("%s: %s is known = %t, want %t", test.name, attr.name, known, !attr.reissued)
+			}
+		}
+
+		updated := p.apply("nkey_system_account", state, test.config)
+		if !test.account && stringAttribute(t, updated, "jwt") != stringAttribute(t, state, "jwt") {
+			t.Errorf("%s: jwt changed", test.name)
+		}
+		if !test.user && stringAttribute(t, updated, "user_jwt") != stringAttribute(t, state, "user_jwt") {
+			t.Errorf("%s: user_jwt changed", test.name)
+		}
+		for _, name := range []string{"public_key", "seed", "user_public_key", "user_seed"} {
+			if stringAttribute(t, updated, name) != stringAttribute(t, state, name) {
+				t.Errorf("%s: %s changed", test.name, name)
+			}
+		}
+
+		state = updated
+	}
+}
@@ -0,0 +1,493 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/objectvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &Tenant{}
+var _ resource.ResourceWithConfigure = &Tenant{}
+var _ resource.ResourceWithModifyPlan = &Tenant{}
+
+func NewTenant() resource.Resource {
+	return &Tenant{}
+}
+
+// Tenant defines the resource implementation.
+type Tenant struct {
+	provider *providerData
+}
+
+// TenantModel describes the resource data model.
+type TenantModel struct {
+	IssuerSeed        types.String                    `tfsdk:"issuer_seed"`
+	Name              types.String                    `tfsdk:"name"`
+	Limits            *AccountLimitsModel             `tfsdk:"limits"`
+	JetStream         *JetStreamLimitsModel           `tfsdk:"jetstream_limits"`
+	JetStreamTiers    map[string]JetStreamLimitsModel `tfsdk:"jetstream_tiered_limits"`
+	UserName          types.String                    `tfsdk:"user_name"`
+	Permissions       *PermissionsModel               `tfsdk:"permissions"`
+	Push              *TenantPushModel                `tfsdk:"push"`
+	PublicKey         types.String                    `tfsdk:"public_key"`
+	Seed              types.String                    `tfsdk:"seed"`
+	SigningKey        types.String                    `tfsdk:"signing_key"`
+	SigningKeySeed    types.String                    `tfsdk:"signing_key_seed"`
+	JWT               types.String                    `tfsdk:"jwt"`
+	UserPublicKey     types.String                    `tfsdk:"user_public_key"`
+	UserSeed          types.String                    `tfsdk:"user_seed"`
+	UserJWT           types.String                    `tfsdk:"user_jwt"`
+	Creds             types.String                    `tfsdk:"creds"`
+	Connection        types.Object                    `tfsdk:"connection_info"`
+	PushedJWTChecksum types.String                    `tfsdk:"pushed_jwt_sha256"`
+}
+
+// TenantPushModel describes the NATS cluster the account JWT of a tenant is
+// pushed to.
+type TenantPushModel struct {
+	Servers     types.List   `tfsdk:"servers"`
+	Credentials types.String `tfsdk:"credentials"`
+}
+
+func (r *Tenant) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_tenant"
+}
+
+func (r *Tenant) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	keepKey := []planmodifier.String{
+		stringplanmodifier.UseStateForUnknown(),
+	}
+
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "A complete tenant: an account nkey and its JWT with limits, an account signing key, a " +
+			"default user signed with it and its creds, optionally pushed to the account resolver of a NATS cluster. " +
+			"The keys are kept, the JWTs are issued again whenever their claims change. Use `nkey_account_jwt` and " +
+			"`nkey_user_jwt` for anything the compact schema does not cover.",
+
+		Attributes: map[string]schema.Attribute{
+			"issuer_seed": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Seed of the operator or an operator signing key the account JWT is signed with",
+				Sensitive:           true,
+			},
+			"name": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Name of the tenant and its account",
+			},
+			"limits": schema.SingleNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "Limits of the account, see `nkey_account_jwt`. Unset limits are unlimited",
+				Attributes:          accountLimitsAttributes(),
+			},
+			"jetstream_limits": schema.SingleNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "JetStream limits of the account, enables JetStream for it. Unset limits are unlimited",
+				Attributes:          jetStreamLimitsAttributes(),
+				Validators: []validator.Object{
+					objectvalidator.ConflictsWith(path.MatchRoot("jetstream_tiered_limits")),
+				},
+			},
+			"jetstream_tiered_limits": schema.MapNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "JetStream limits of the account per replication tier, e.g. `R1` and `R3`",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: jetStreamLimitsAttributes(),
+				},
+			},
+			"user_name": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("default"),
+				MarkdownDescription: "Name of the default user. Defaults to `default`",
+			},
+			"permissions": schema.SingleNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "Publish and subscribe permissions of the default user",
+				Attributes:          permissionsResourceAttributes(),
+			},
+			"push": schema.SingleNestedAttribute{
+				Optional: true,
+				MarkdownDescription: "NATS cluster whose account resolver the account JWT is pushed to whenever it is " +
+					"issued, see `nkey_account_push`",
+				Attributes: map[string]schema.Attribute{
+					"servers": schema.ListAttribute{
+						Required:            true,
+						ElementType:         types.StringType,
+						MarkdownDescription: "NATS server URLs to connect to",
+					},
+					"credentials": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "Content of a creds file of a system account user",
+						Sensitive:           true,
+					},
+				},
+			},
+			"public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the account",
+				PlanModifiers:       keepKey,
+			},
+			"seed": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Seed of the account",
+				Sensitive:           true,
+				PlanModifiers:       keepKey,
+			},
+			"signing_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the account signing key",
+				PlanModifiers:       keepKey,
+			},
+			"signing_key_seed": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Seed of the account signing key, to sign further users of the tenant with",
+				Sensitive:           true,
+				PlanModifiers:       keepKey,
+			},
+			"jwt": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The encoded account JWT",
+			},
+			"user_public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the default user",
+				PlanModifiers:       keepKey,
+			},
+			"user_seed": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Seed of the default user",
+				Sensitive:           true,
+				PlanModifiers:       keepKey,
+			},
+			"user_jwt": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The encoded JWT of the default user",
+				Sensitive:           true,
+			},
+			"creds": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Creds file content of the default user",
+				Sensitive:           true,
+			},
+			"connection_info": connectionAttribute(),
+			"pushed_jwt_sha256": schema.StringAttribute{
+				Computed: true,
+				MarkdownDescription: "Hex encoded SHA-256 hash of the account JWT last accepted by the resolver. Not set " +
+					"without `push` or while the push is pending, a pending push is retried on the next apply",
+			},
+		},
+	}
+}
+
+func (r *Tenant) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.provider = data
+}
+
+func (r *Tenant) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	// Nothing to compare against on create or destroy, or before all
+	// attributes are known
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() || !req.Config.Raw.IsFullyKnown() {
+		return
+	}
+
+	var plan, state TenantModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Each JWT is only signed again if its claims change. Errors are
+	// reported on apply.
+	var diags diag.Diagnostics
+	r.issue(ctx, &plan, &diags)
+	if diags.HasError() {
+		return
+	}
+	token := plan.JWT
+	if sameClaims(plan.JWT, state.JWT, false, false, r.provider.provenanceKeys()...) {
+		token = state.JWT
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("jwt"), state.JWT)...)
+	}
+	if sameClaims(plan.UserJWT, state.UserJWT, false, false, r.provider.provenanceKeys()...) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("user_jwt"), state.UserJWT)...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("creds"), state.Creds)...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("connection_info"),
+			plan.connection(ctx, state.UserJWT, state.Creds, &resp.Diagnostics))...)
+	}
+
+	// A JWT kept is pushed again until the resolver accepted it.
+	pushed := types.StringNull()
+	switch {
+	case plan.Push == nil:
+	case token == state.JWT && state.PushedJWTChecksum.ValueString() == jwtHash(state.JWT.ValueString()):
+		pushed = state.PushedJWTChecksum
+	default:
+		pushed = types.StringUnknown()
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("pushed_jwt_sha256"), pushed)...)
+}
+
+func (r *Tenant) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var data TenantModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, k := range []struct {
+		prefix          nkeys.PrefixByte
+		publicKey, seed *types.String
+	}{
+		{nkeys.PrefixByteAccount, &data.PublicKey, &data.Seed},
+		{nkeys.PrefixByteAccount, &data.SigningKey, &data.SigningKeySeed},
+		{nkeys.PrefixByteUser, &data.UserPublicKey, &data.UserSeed},
+	} {
+		key, err := newKeysetKey(k.prefix)
+		if err != nil {
+			addError(&resp.Diagnostics, "Unable to generate nkey", err)
+			return
+		}
+		*k.publicKey = types.StringValue(key.publicKey)
+		*k.seed = types.StringValue(key.seed)
+	}
+
+	r.issue(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	r.push(ctx, &data, &resp.Diagnostics)
+	tflog.Trace(ctx, "created tenant resource")
+
+	// Save data into Terraform state, the keys are kept if the push failed
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.provider.register(ctx, "nkey_tenant", data.JWT, &resp.Diagnostics)
+	r.provider.register(ctx, "nkey_tenant", data.UserJWT, &resp.Diagnostics)
+}
+
+func (r *Tenant) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data TenantModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Tenant) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var plan TenantModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The JWTs in state are kept if their claims did not change.
+	accountIssued, userIssued := plan.JWT.IsUnknown(), plan.UserJWT.IsUnknown()
+	if accountIssued || userIssued {
+		issued := plan
+		r.issue(ctx, &issued, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if accountIssued {
+			plan.JWT = issued.JWT
+		}
+		if userIssued {
+			plan.UserJWT = issued.UserJWT
+			plan.Creds = issued.Creds
+		}
+	}
+	plan.Connection = plan.connection(ctx, plan.UserJWT, plan.Creds, &resp.Diagnostics)
+	if plan.PushedJWTChecksum.IsUnknown() {
+		r.push(ctx, &plan, &resp.Diagnostics)
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if accountIssued {
+		r.provider.register(ctx, "nkey_tenant", plan.JWT, &resp.Diagnostics)
+	}
+	if userIssued {
+		r.provider.register(ctx, "nkey_tenant", plan.UserJWT, &resp.Diagnostics)
+	}
+}
+
+func (r *Tenant) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Nothing to do here as the keys and JWTs only exist in state. Removing
+	// the account from the resolver requires a delete request signed by the
+	// operator.
+}
+
+// issue encodes the account JWT, signed with the issuer seed, and the JWT
+// and creds of the default user, signed with the account signing key.
+func (r *Tenant) issue(ctx context.Context, data *TenantModel, diags *diag.Diagnostics) {
+	issuerKeys, err := jwtSigner(data.IssuerSeed.ValueString(), path.Root("issuer_seed"), nkeys.PrefixByteOperator)
+	if err != nil {
+		addError(diags, "Invalid issuer seed", err)
+		return
+	}
+	defer issuerKeys.Wipe()
+
+	signingKeys, err := jwtSigner(data.SigningKeySeed.ValueString(), path.Root("signing_key_seed"), nkeys.PrefixByteAccount)
+	if err != nil {
+		addError(diags, "Invalid account signing key seed in state", err)
+		return
+	}
+	defer signingKeys.Wipe()
+
+	// The claims are those of the account and user JWT resources.
+	account := (&AccountJWTModel{
+		PublicKey:      data.PublicKey,
+		Name:           data.Name,
+		Tags:           types.SetNull(types.StringType),
+		SigningKeys:    types.SetValueMust(types.StringType, []attr.Value{data.SigningKey}),
+		Limits:         data.Limits,
+		JetStream:      data.JetStream,
+		JetStreamTiers: data.JetStreamTiers,
+	}).claims(ctx, diags)
+	user := (&UserJWTModel{
+		PublicKey:   data.UserPublicKey,
+		Name:        data.UserName,
+		Tags:        types.SetNull(types.StringType),
+		Permissions: data.Permissions,
+		ConnTypes:   types.SetNull(types.StringType),
+	}).claims(ctx, diags)
+	if diags.HasError() {
+		return
+	}
+	account.Tags.Add(r.provider.provenanceTags("nkey_tenant", account.Name)...)
+	user.IssuerAccount = data.PublicKey.ValueString()
+	user.Tags.Add(r.provider.provenanceTags("nkey_tenant", user.Name)...)
+
+	token, err := encodeJWT(account, issuerKeys, false, diags)
+	if err != nil {
+		addError(diags, "Unable to issue account JWT", err)
+		return
+	}
+
+	userToken, err := encodeJWT(user, signingKeys, false, diags)
+	if err != nil {
+		addError(diags, "Unable to issue user JWT", err)
+		return
+	}
+
+	creds, err := jwt.FormatUserConfig(userToken, []byte(data.UserSeed.ValueString()))
+	if err != nil {
+		addError(diags, "Unable to render user creds", err)
+		return
+	}
+	defer clear(creds)
+
+	data.JWT = types.StringValue(token)
+	data.UserJWT = types.StringValue(userToken)
+	data.Creds = types.StringValue(string(creds))
+	data.Connection = data.connection(ctx, data.UserJWT, data.Creds, diags)
+}
+
+// connection returns the connection object of the default user with the
+// token and creds, connecting to the servers the account is pushed to.
+func (m *TenantModel) connection(ctx context.Context, token, creds types.String, diags *diag.Diagnostics) types.Object {
+	servers := types.ListNull(types.StringType)
+	if m.Push != nil {
+		servers = m.Push.Servers
+	}
+	return connectionValue(ctx, servers, nil, token, m.UserSeed, creds, diags)
+}
+
+// push sends the account JWT to the resolver if push is set and records its
+// hash once the resolver accepted it.
+func (r *Tenant) push(ctx context.Context, data *TenantModel, diags *diag.Diagnostics) {
+	data.PushedJWTChecksum = types.StringNull()
+	if data.Push == nil {
+		return
+	}
+
+	if r.provider.isOffline() {
+		diags.AddWarning("Account not pushed",
+			"The provider is configured offline, the account JWT will be pushed on the next online apply.")
+		return
+	}
+
+	var servers []string
+	diags.Append(data.Push.Servers.ElementsAs(ctx, &servers, false)...)
+	if diags.HasError() {
+		return
+	}
+
+	nc, err := connectNATS(ctx, servers, data.Push.Credentials.ValueString())
+	if err != nil {
+		addError(diags, "Unable to connect to NATS", errorAt(path.Root("push"), err))
+		return
+	}
+	defer nc.Close()
+
+	message, err := pushAccountJWT(ctx, nc, data.JWT.ValueString())
+	if err != nil {
+		addError(diags, "Unable to push account JWT", errorAt(path.Root("push"), err))
+		return
+	}
+	tflog.Debug(ctx, "pushed tenant account JWT", map[string]interface{}{"account": data.PublicKey.ValueString(), "message": message})
+
+	data.PushedJWTChecksum = types.StringValue(jwtHash(data.JWT.ValueString()))
+}
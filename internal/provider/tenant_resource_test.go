@@ -0,0 +1,134 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestTenant(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	operator, _ := nkeys.CreateOperator()
+	operatorKey, _ := operator.PublicKey()
+	seed, _ := operator.Seed()
+
+	// nested returns the nested attribute name of the tenant schema with the
+	// attributes in attrs, all other attributes are null.
+	typ := p.resourceSchema("nkey_tenant").ValueType().(tftypes.Object)
+	nested := func(name string, attrs map[string]tftypes.Value) tftypes.Value {
+		nestedType := typ.AttributeTypes[name].(tftypes.Object)
+		values := map[string]tftypes.Value{}
+		for name, t := range nestedType.AttributeTypes {
+			values[name] = tftypes.NewValue(t, nil)
+			if v, ok := attrs[name]; ok {
+				values[name] = v
+			}
+		}
+		return tftypes.NewValue(nestedType, values)
+	}
+	subjects := func(s ...string) tftypes.Value {
+		values := make([]tftypes.Value, len(s))
+		for i, s := range s {
+			values[i] = stringValue(s)
+		}
+		return tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, values)
+	}
+	config := func(connections int64, userName string) map[string]tftypes.Value {
+		return map[string]tftypes.Value{
+			"issuer_seed": stringValue(string(seed)),
+			"name":        stringValue("acme"),
+			"user_name":   stringValue(userName),
+			"limits":      nested("limits", map[string]tftypes.Value{"connections": numberValue(connections)}),
+			"permissions": nested("permissions", map[string]tftypes.Value{
+				"publish": tftypes.NewValue(typ.AttributeTypes["permissions"].(tftypes.Object).AttributeTypes["publish"],
+					map[string]tftypes.Value{"allow": subjects("acme.>"), "deny": tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, nil)}),
+			}),
+		}
+	}
+
+	state := p.create("nkey_tenant", config(10, "default"))
+
+	account, err := jwt.DecodeAccountClaims(stringAttribute(t, state, "jwt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Issuer != operatorKey || account.Subject != stringAttribute(t, state, "public_key") {
+		t.Errorf("account JWT of %s issued by %s", account.Subject, account.Issuer)
+	}
+	if account.Limits.Conn != 10 {
+		t.Errorf("connections = %d, want 10", account.Limits.Conn)
+	}
+	signingKey := stringAttribute(t, state, "signing_key")
+	if !account.SigningKeys.Contains(signingKey) {
+		t.Errorf("signing key %s missing from the account JWT", signingKey)
+	}
+
+	user, err := jwt.DecodeUserClaims(stringAttribute(t, state, "user_jwt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Issuer != signingKey || user.IssuerAccount != account.Subject {
+		t.Errorf("user JWT issued by %s for account %s, want %s for %s", user.Issuer, user.IssuerAccount, signingKey, account.Subject)
+	}
+	if user.Name != "default" || !user.Pub.Allow.Contains("acme.>") {
+		t.Errorf("user claims = %s, %v", user.Name, user.Pub.Allow)
+	}
+
+	creds := stringAttribute(t, state, "creds")
+	if token, err := jwt.ParseDecoratedJWT([]byte(creds)); err != nil || token != stringAttribute(t, state, "user_jwt") {
+		t.Errorf("creds hold another user JWT: %v", err)
+	}
+	if kp, err := jwt.ParseDecoratedNKey([]byte(creds)); err != nil {
+		t.Error(err)
+	} else if key, _ := kp.PublicKey(); key != user.Subject {
+		t.Errorf("creds hold the seed of %s, want %s", key, user.Subject)
+	}
+
+	tests := []struct {
+		name        string
+		connections int64
+		userName    string
+		// account and user report whether the account and user JWTs are
+		// issued again.
+		account, user bool
+	}{
+		{name: "unchanged", connections: 10, userName: "default"},
+		{name: "user name", connections: 10, userName: "ci", user: true},
+		{name: "limits", connections: 20, userName: "ci", account: true},
+	}
+
+	for _, test := range tests {
+		resp := p.plan("nkey_tenant", state, config(test.connections, test.userName))
+		checkDiagnostics(t, resp.Diagnostics)
+		planned := p.value(p.resourceSchema("nkey_tenant"), resp.PlannedState)
+
+		for _, attr := range []struct {
+			name     string
+			reissued bool
+		}{
+			{"jwt", test.account},
+			{"user_jwt", test.user},
+			{"creds", test.user},
+			{"connection_info", test.user},
+		} {
+			if known := attribute(t, planned, attr.name).IsKnown(); known == attr.reissued {
+				t.Errorf("%s: %s is known = %t, want %t", test.name, attr.name, known, !attr.reissued)
+			}
+		}
+
+		updated := p.apply("nkey_tenant", state, config(test.connections, test.userName))
+		for _, name := range []string{"public_key", "seed", "signing_key", "signing_key_seed", "user_public_key", "user_seed"} {
+			if stringAttribute(t, updated, name) != stringAttribute(t, state, name) {
+				t.Errorf("%s: %s changed", test.name, name)
+			}
+		}
+		state = updated
+	}
+}
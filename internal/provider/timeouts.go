@@ -0,0 +1,29 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"time"
+)
+
+// withTimeout bounds ctx by timeout, as read from a timeouts block with a
+// default of zero. Without a configured timeout ctx stays unbounded and every
+// request falls back to its own default, see withDefaultTimeout.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// withDefaultTimeout bounds a single request by timeout, unless ctx already
+// carries the deadline of a configured timeouts block, which then applies to
+// the request as a whole.
+func withDefaultTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
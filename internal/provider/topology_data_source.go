@@ -0,0 +1,248 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &Topology{}
+
+func NewTopology() datasource.DataSource {
+	return &Topology{}
+}
+
+// Topology defines the data source implementation.
+type Topology struct {
+}
+
+// TopologyModel describes the data source data model.
+type TopologyModel struct {
+	OperatorJWT types.String `tfsdk:"operator_jwt"`
+	AccountJWTs []string     `tfsdk:"account_jwts"`
+	UserJWTs    []string     `tfsdk:"user_jwts"`
+	DOT         types.String `tfsdk:"dot"`
+	JSON        types.String `tfsdk:"json"`
+}
+
+// topologyGraph is the layout of the json attribute.
+type topologyGraph struct {
+	Nodes []topologyNode `json:"nodes"`
+	Edges []topologyEdge `json:"edges"`
+}
+
+type topologyNode struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+	Name string `json:"name,omitempty"`
+}
+
+type topologyEdge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Relation string `json:"relation"`
+}
+
+// topologyClaims are the parts of operator, account and user JWTs the graph
+// is built from.
+type topologyClaims struct {
+	Subject string `json:"sub"`
+	Issuer  string `json:"iss"`
+	Name    string `json:"name"`
+	Nats    struct {
+		Type          string            `json:"type"`
+		SigningKeys   []json.RawMessage `json:"signing_keys"`
+		IssuerAccount string            `json:"issuer_account"`
+	} `json:"nats"`
+}
+
+// signingKeys returns the public signing keys of the claims. Accounts may
+// list scoped signing keys as objects instead of plain strings.
+func (c *topologyClaims) signingKeys() ([]string, error) {
+	keys := make([]string, 0, len(c.Nats.SigningKeys))
+
+	for _, raw := range c.Nats.SigningKeys {
+		var key string
+		if err := json.Unmarshal(raw, &key); err != nil {
+			var scoped struct {
+				Key string `json:"key"`
+			}
+			if err := json.Unmarshal(raw, &scoped); err != nil {
+				return nil, err
+			}
+			key = scoped.Key
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+func (d *Topology) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_topology"
+}
+
+func (d *Topology) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Renders the trust relationships between an operator, its accounts, their signing keys and " +
+			"users as a graph. Only public keys and names are included.",
+
+		Attributes: map[string]schema.Attribute{
+			"operator_jwt": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "The operator JWT",
+			},
+			"account_jwts": schema.ListAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Account JWTs to include",
+			},
+			"user_jwts": schema.ListAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "User JWTs to include",
+			},
+			"dot": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Graph in Graphviz DOT format",
+			},
+			"json": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "JSON document with a list of `nodes` (`id`, `kind`, `name`) and `edges` (`from`, `to`, `relation`)",
+			},
+		},
+	}
+}
+
+func (d *Topology) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data TopologyModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	nodes := map[string]topologyNode{}
+	edges := map[topologyEdge]bool{}
+
+	add := func(p path.Path, token, kind string) {
+		var claims topologyClaims
+		if err := jwtClaims(token, &claims); err != nil {
+			addError(&resp.Diagnostics, "Invalid JWT", errorAt(p, err))
+			return
+		}
+		if claims.Nats.Type != kind {
+			resp.Diagnostics.AddAttributeError(p, "Invalid JWT",
+				fmt.Sprintf("expected a %s JWT, got type %q", kind, claims.Nats.Type))
+			return
+		}
+
+		keys, err := claims.signingKeys()
+		if err != nil {
+			addError(&resp.Diagnostics, "Invalid JWT", errorAt(p, err))
+			return
+		}
+
+		nodes[claims.Subject] = topologyNode{ID: claims.Subject, Kind: kind, Name: claims.Name}
+		for _, key := range keys {
+			if _, ok := nodes[key]; !ok {
+				nodes[key] = topologyNode{ID: key, Kind: "signing_key"}
+			}
+			edges[topologyEdge{From: claims.Subject, To: key, Relation: "signing_key"}] = true
+		}
+
+		// The operator JWT is self-signed.
+		if claims.Issuer != claims.Subject {
+			edges[topologyEdge{From: claims.Issuer, To: claims.Subject, Relation: "issued"}] = true
+		}
+		if claims.Nats.IssuerAccount != "" && claims.Nats.IssuerAccount != claims.Issuer {
+			edges[topologyEdge{From: claims.Nats.IssuerAccount, To: claims.Issuer, Relation: "signing_key"}] = true
+		}
+	}
+
+	if !data.OperatorJWT.IsNull() {
+		add(path.Root("operator_jwt"), data.OperatorJWT.ValueString(), "operator")
+	}
+	for i, token := range data.AccountJWTs {
+		add(path.Root("account_jwts").AtListIndex(i), token, "account")
+	}
+	for i, token := range data.UserJWTs {
+		add(path.Root("user_jwts").AtListIndex(i), token, "user")
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	graph := topologyGraph{Nodes: []topologyNode{}, Edges: []topologyEdge{}}
+	for _, id := range sortedKeys(nodes) {
+		graph.Nodes = append(graph.Nodes, nodes[id])
+	}
+	for edge := range edges {
+		graph.Edges = append(graph.Edges, edge)
+	}
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Relation < b.Relation
+	})
+
+	encoded, err := json.MarshalIndent(graph, "", "  ")
+	if err != nil {
+		addError(&resp.Diagnostics, "Unable to encode graph", err)
+		return
+	}
+
+	data.DOT = types.StringValue(graph.dot())
+	data.JSON = types.StringValue(string(encoded))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+var topologyShapes = map[string]string{
+	"operator":    "doubleoctagon",
+	"account":     "box",
+	"signing_key": "diamond",
+	"user":        "ellipse",
+}
+
+// dot renders the graph in Graphviz DOT format. Issuers that are not part
+// of the graph are still drawn, as plain nodes.
+func (g topologyGraph) dot() string {
+	var b strings.Builder
+
+	b.WriteString("digraph nats {\n")
+	b.WriteString("  rankdir=LR;\n")
+	for _, n := range g.Nodes {
+		label := n.ID
+		if n.Name != "" {
+			label = n.Name + "\n" + n.ID
+		}
+		fmt.Fprintf(&b, "  %s [label=%s, shape=%s];\n", quote(n.ID), quote(label), topologyShapes[n.Kind])
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", quote(e.From), quote(e.To), quote(e.Relation))
+	}
+	b.WriteString("}\n")
+
+	return b.String()
+}
@@ -0,0 +1,700 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"terraform-provider-nkey/internal/subject"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &UserJWT{}
+var _ resource.ResourceWithModifyPlan = &UserJWT{}
+
+func NewUserJWT() resource.Resource {
+	return &UserJWT{}
+}
+
+// UserJWT defines the resource implementation.
+type UserJWT struct {
+	provider *providerData
+}
+
+// UserJWTModel describes the resource data model.
+type UserJWTModel struct {
+	PublicKey       types.String      `tfsdk:"public_key"`
+	IssuerSeed      types.String      `tfsdk:"issuer_seed"`
+	IssuerAccount   types.String      `tfsdk:"issuer_account"`
+	AccountJWT      types.String      `tfsdk:"account_jwt"`
+	Name            types.String      `tfsdk:"name"`
+	Audience        types.String      `tfsdk:"audience"`
+	Tags            types.Set         `tfsdk:"tags"`
+	Permissions     *PermissionsModel `tfsdk:"permissions"`
+	Responses       *ResponsesModel   `tfsdk:"allow_responses"`
+	Limits          *UserLimitsModel  `tfsdk:"limits"`
+	BearerToken     types.Bool        `tfsdk:"bearer_token"`
+	ConnTypes       types.Set         `tfsdk:"allowed_connection_types"`
+	ExpiresAt       types.String      `tfsdk:"expires_at"`
+	NotBefore       types.String      `tfsdk:"not_before"`
+	RenewBefore     types.String      `tfsdk:"renew_before"`
+	IssuedAt        types.String      `tfsdk:"issued_at"`
+	DeterministicID types.Bool        `tfsdk:"deterministic_id"`
+	JWTVersion      types.Int64       `tfsdk:"jwt_version"`
+	Claims          types.Dynamic     `tfsdk:"claims"`
+	Issuer          types.String      `tfsdk:"issuer"`
+	JWT             types.String      `tfsdk:"jwt"`
+}
+
+// ResponsesModel describes the response permissions of a user: it may
+// publish replies to the requests it received, even without publish
+// permissions on the reply subjects.
+type ResponsesModel struct {
+	MaxMessages types.Int64  `tfsdk:"max_messages"`
+	TTL         types.String `tfsdk:"ttl"`
+}
+
+// UserLimitsModel describes the limits block of a user JWT. Unset limits
+// are unlimited.
+type UserLimitsModel struct {
+	Subscriptions types.Int64      `tfsdk:"subscriptions"`
+	Data          types.Int64      `tfsdk:"data"`
+	Payload       types.Int64      `tfsdk:"payload"`
+	Src           []string         `tfsdk:"src"`
+	Times         []TimeRangeModel `tfsdk:"times"`
+	Locale        types.String     `tfsdk:"locale"`
+}
+
+// TimeRangeModel is a daily time window in which a user may connect.
+type TimeRangeModel struct {
+	Start types.String `tfsdk:"start"`
+	End   types.String `tfsdk:"end"`
+}
+
+// permissionsResourceAttributes returns the attributes of a PermissionsModel.
+func permissionsResourceAttributes() map[string]schema.Attribute {
+	permission := func(action string) schema.SingleNestedAttribute {
+		// Subscribe permissions may be restricted to a queue group.
+		subjects := subjectValidator{wildcards: true, queue: action == "subscribe"}
+		description := ""
+		if action == "subscribe" {
+			description = ". A subject may be followed by a space and a queue group, e.g. `orders.* workers`"
+		}
+
+		return schema.SingleNestedAttribute{
+			Optional:            true,
+			MarkdownDescription: "Subjects the user may " + action + " to",
+			Attributes: map[string]schema.Attribute{
+				"allow": schema.ListAttribute{
+					Optional:            true,
+					ElementType:         types.StringType,
+					MarkdownDescription: "Subjects to allow, all subjects are allowed if empty" + description,
+					Validators: []validator.List{
+						listvalidator.ValueStringsAre(subjects),
+					},
+				},
+				"deny": schema.ListAttribute{
+					Optional:            true,
+					ElementType:         types.StringType,
+					MarkdownDescription: "Subjects to deny, takes precedence over `allow`" + description,
+					Validators: []validator.List{
+						listvalidator.ValueStringsAre(subjects),
+					},
+				},
+			},
+		}
+	}
+
+	return map[string]schema.Attribute{
+		"publish":   permission("publish"),
+		"subscribe": permission("subscribe"),
+	}
+}
+
+// responsesAttributes returns the attributes of a ResponsesModel.
+func responsesAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"max_messages": schema.Int64Attribute{
+			Optional:            true,
+			MarkdownDescription: "Maximum number of responses per request, -1 for unlimited. Defaults to 1",
+		},
+		"ttl": schema.StringAttribute{
+			Optional:            true,
+			MarkdownDescription: "Duration after receiving a request in which responses may be sent, e.g. `5s`. Unlimited if not set",
+		},
+	}
+}
+
+// userLimitsAttributes returns the attributes of a UserLimitsModel.
+func userLimitsAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"subscriptions": schema.Int64Attribute{
+			Optional:            true,
+			MarkdownDescription: "Maximum number of subscriptions",
+		},
+		"data": schema.Int64Attribute{
+			Optional:            true,
+			MarkdownDescription: "Maximum number of bytes",
+		},
+		"payload": schema.Int64Attribute{
+			Optional:            true,
+			MarkdownDescription: "Maximum message payload in bytes",
+		},
+		"src": schema.ListAttribute{
+			Optional:            true,
+			ElementType:         types.StringType,
+			MarkdownDescription: "CIDRs of the networks the user may connect from, e.g. `10.0.0.0/8`. Any network if empty",
+		},
+		"times": schema.ListNestedAttribute{
+			Optional:            true,
+			MarkdownDescription: "Daily time windows in which the user may connect. Any time if empty",
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: map[string]schema.Attribute{
+					"start": schema.StringAttribute{
+						Required:            true,
+						MarkdownDescription: "Start of the window as `HH:MM:SS`",
+					},
+					"end": schema.StringAttribute{
+						Required:            true,
+						MarkdownDescription: "End of the window as `HH:MM:SS`",
+					},
+				},
+			},
+		},
+		"locale": schema.StringAttribute{
+			Optional:            true,
+			MarkdownDescription: "IANA time zone of `times`, e.g. `Europe/Berlin`. Defaults to the time zone of the server",
+		},
+	}
+}
+
+// connectionTypesAttribute returns the allowed_connection_types attribute
+// of users with the given description.
+func connectionTypesAttribute(description string) schema.SetAttribute {
+	return schema.SetAttribute{
+		Optional:            true,
+		ElementType:         types.StringType,
+		MarkdownDescription: description,
+		Validators: []validator.Set{
+			setvalidator.ValueStringsAre(stringvalidator.OneOf(
+				jwt.ConnectionTypeStandard,
+				jwt.ConnectionTypeWebsocket,
+				jwt.ConnectionTypeLeafnode,
+				jwt.ConnectionTypeLeafnodeWS,
+				jwt.ConnectionTypeMqtt,
+				jwt.ConnectionTypeMqttWS,
+				jwt.ConnectionTypeInProcess,
+			)),
+		},
+	}
+}
+
+func (r *UserJWT) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user_jwt"
+}
+
+func (r *UserJWT) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "A user JWT signed by an account. The JWT is issued again whenever its claims change. Unlike " +
+			"the operator and account JWTs it cannot be signed offline: sign it with an account signing key, which can " +
+			"be rotated without signing the account JWT, instead of the account identity key.",
+
+		Attributes: map[string]schema.Attribute{
+			"public_key": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Public key of the user the JWT is issued for",
+			},
+			"issuer_seed": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Seed of the account or an account signing key the JWT is signed with",
+				Sensitive:           true,
+			},
+			"issuer_account": issuerAccountAttribute("the user belongs to"),
+			"account_jwt": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "JWT of the account the user belongs to, e.g. `nkey_account_jwt.this.jwt`. If set, " +
+					"the user is checked against the account during plan, or on apply if the account JWT or other " +
+					"attributes are not known yet: the issuer must be the account or one of its signing keys, the user must not outlive " +
+					"the account, its subjects must be within the role of a scoped signing key and use the local subjects of " +
+					"imports, and its limits must fit within those of the account",
+			},
+			"name": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Name of the user",
+			},
+			"audience": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "Audience of the JWT, its `aud` claim, for gateways and auth callout services " +
+					"that accept users by audience. Not checked by the server",
+			},
+			"tags": schema.SetAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				MarkdownDescription: "Tags of the user. Tags are lowercased. User claims have no `description` or " +
+					"`info_url`, document the user in the account or with tags instead",
+			},
+			"permissions": schema.SingleNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "Publish and subscribe permissions of the user",
+				Attributes:          permissionsResourceAttributes(),
+			},
+			"allow_responses": schema.SingleNestedAttribute{
+				Optional: true,
+				MarkdownDescription: "Allow the user to respond to the requests it receives without publish permissions " +
+					"on the reply subjects, e.g. `_INBOX.>`",
+				Attributes: responsesAttributes(),
+			},
+			"limits": schema.SingleNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "Limits of the user. Unset limits are unlimited",
+				Attributes:          userLimitsAttributes(),
+			},
+			"bearer_token": schema.BoolAttribute{
+				Optional: true,
+				MarkdownDescription: "Issue a bearer token: the server accepts the JWT without proof of possession of the " +
+					"user seed, for clients that cannot sign the connection nonce such as WebSocket and MQTT clients. " +
+					"Anyone holding the JWT can connect as the user",
+			},
+			"allowed_connection_types": connectionTypesAttribute("Connection types the user may connect with. Must be " +
+				"STANDARD, WEBSOCKET, LEAFNODE, LEAFNODE_WS, MQTT, MQTT_WS or IN_PROCESS. Any type if empty"),
+			"expires_at":       expiresAtAttribute(),
+			"not_before":       notBeforeAttribute(),
+			"renew_before":     renewBeforeAttribute(),
+			"issued_at":        issuedAtAttribute(),
+			"deterministic_id": deterministicIDAttribute(),
+			"jwt_version":      jwtVersionAttribute(),
+			"issuer": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the account or signing key the JWT was signed with",
+			},
+			"jwt": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The encoded user JWT. A bearer token if `bearer_token` is set",
+				Sensitive:           true,
+			},
+			"claims": claimsAttribute(),
+		},
+	}
+}
+
+func (r *UserJWT) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	// Nothing to do on destroy. Users with claims that are not known yet,
+	// such as the public key of a user created in the same apply, are
+	// issued and checked on apply. An account JWT or renewal window that is
+	// not known yet does not change the claims.
+	if req.Plan.Raw.IsNull() || !claimsKnown(req.Config.Raw, "account_jwt", "renew_before") {
+		return
+	}
+
+	var plan UserJWTModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !plan.AccountJWT.IsNull() {
+		r.checkPlan(ctx, &plan, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Nothing to compare against on create
+	if req.State.Raw.IsNull() {
+		return
+	}
+
+	var state UserJWTModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The JWT is only signed again if its claims change. Errors are reported
+	// on apply.
+	var diags diag.Diagnostics
+	r.issue(ctx, &plan, &diags)
+	if !diags.HasError() && sameClaims(plan.JWT, state.JWT,
+		!plan.ExpiresAt.Equal(state.ExpiresAt) || !plan.NotBefore.Equal(state.NotBefore),
+		!plan.IssuedAt.Equal(state.IssuedAt) || !plan.DeterministicID.Equal(state.DeterministicID),
+		r.provider.provenanceKeys()...) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("issuer"), state.Issuer)...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("jwt"), state.JWT)...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("claims"), claimsValue(state.JWT, &resp.Diagnostics))...)
+	} else if !diags.HasError() {
+		// The claims issued again on apply only differ in those that
+		// change with the issuance.
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("claims"),
+			claimsValue(plan.JWT, &resp.Diagnostics, volatileClaims(r.provider, plan.IssuedAt, plan.ExpiresAt, plan.NotBefore)...))...)
+	}
+}
+
+// checkPlan checks the planned user against its account JWT before anything
+// is signed.
+func (r *UserJWT) checkPlan(ctx context.Context, plan *UserJWTModel, diags *diag.Diagnostics) {
+	keys, err := jwtSigner(plan.IssuerSeed.ValueString(), path.Root("issuer_seed"), nkeys.PrefixByteAccount)
+	if err != nil {
+		addError(diags, "Invalid issuer seed", err)
+		return
+	}
+	defer keys.Wipe()
+
+	issuer, err := keys.PublicKey()
+	if err != nil {
+		addError(diags, "Invalid issuer seed", errorAt(path.Root("issuer_seed"), err))
+		return
+	}
+
+	claims := plan.claims(ctx, diags)
+	if diags.HasError() {
+		return
+	}
+
+	plan.checkAccount(claims, issuer, diags)
+}
+
+func (r *UserJWT) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.provider = data
+}
+
+func (r *UserJWT) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var data UserJWTModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.issue(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Trace(ctx, "created user JWT resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.provider.register(ctx, "nkey_user_jwt", data.JWT, &resp.Diagnostics)
+}
+
+func (r *UserJWT) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data UserJWTModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A JWT close to expiry is issued again on the next apply.
+	if renewalDue(data.JWT, data.RenewBefore) {
+		tflog.Info(ctx, "JWT is due for renewal, removing it from state", map[string]interface{}{
+			"renew_before": data.RenewBefore.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Claims are missing from the state of earlier versions.
+	if data.Claims.IsNull() {
+		data.Claims = claimsValue(data.JWT, &resp.Diagnostics)
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *UserJWT) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = maskSecrets(ctx)
+	defer recoverPanic(ctx, &resp.Diagnostics)
+
+	var plan UserJWTModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The JWT in state is kept if its claims did not change.
+	reissued := plan.JWT.IsUnknown()
+	if reissued {
+		r.issue(ctx, &plan, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if reissued {
+		r.provider.notify(ctx, jwtEvent("reissued", "nkey_user_jwt", plan.JWT), &resp.Diagnostics)
+		r.provider.register(ctx, "nkey_user_jwt", plan.JWT, &resp.Diagnostics)
+	}
+}
+
+func (r *UserJWT) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Nothing to do here as the JWT only exists in state
+}
+
+// issue encodes the user claims of data and signs them with the issuer seed.
+func (r *UserJWT) issue(ctx context.Context, data *UserJWTModel, diags *diag.Diagnostics) {
+	keys, err := jwtSigner(data.IssuerSeed.ValueString(), path.Root("issuer_seed"), nkeys.PrefixByteAccount)
+	if err != nil {
+		addError(diags, "Invalid issuer seed", err)
+		return
+	}
+	defer keys.Wipe()
+
+	issuer, err := keys.PublicKey()
+	if err != nil {
+		addError(diags, "Invalid issuer seed", errorAt(path.Root("issuer_seed"), err))
+		return
+	}
+
+	claims := data.claims(ctx, diags)
+	if diags.HasError() {
+		return
+	}
+	claims.Tags.Add(r.provider.provenanceTags("nkey_user_jwt", claims.Name)...)
+
+	claims.IssuerAccount = issuerAccount(data.IssuerAccount, issuer, diags)
+	if diags.HasError() {
+		return
+	}
+
+	// The account JWT may not have been known during plan.
+	data.checkAccount(claims, issuer, diags)
+	if diags.HasError() {
+		return
+	}
+
+	token, err := jwtEncoder(data.JWTVersion)(claims, keys, data.DeterministicID.ValueBool(), diags)
+	if err != nil {
+		addError(diags, "Unable to issue user JWT", err)
+		return
+	}
+	data.Claims = claimsValue(types.StringValue(token), diags)
+
+	data.Issuer = types.StringValue(issuer)
+	data.JWT = types.StringValue(token)
+}
+
+// claims returns the user claims described by m.
+func (m *UserJWTModel) claims(ctx context.Context, diags *diag.Diagnostics) *jwt.UserClaims {
+	if err := checkPublicKey(m.PublicKey.ValueString(), nkeys.PrefixByteUser); err != nil {
+		addError(diags, "Invalid user public key", errorAt(path.Root("public_key"), err))
+		return nil
+	}
+
+	claims := jwt.NewUserClaims(m.PublicKey.ValueString())
+	claims.Name = m.Name.ValueString()
+	claims.Audience = m.Audience.ValueString()
+	claims.Tags.Add(tagList(ctx, m.Tags, diags)...)
+	claims.Permissions = m.Permissions.jwtPermissions()
+	claims.Resp = m.Responses.jwtResponses(path.Root("allow_responses"), diags)
+	claims.BearerToken = m.BearerToken.ValueBool()
+	diags.Append(m.ConnTypes.ElementsAs(ctx, &claims.AllowedConnectionTypes, false)...)
+
+	setValidity(&claims.ClaimsData, m.IssuedAt, m.ExpiresAt, m.NotBefore, m.RenewBefore, diags)
+
+	if m.Limits != nil {
+		claims.Limits = m.Limits.jwtLimits()
+	}
+
+	return claims
+}
+
+// checkAccount checks claims, signed by issuer, against the account JWT of m
+// if it is set.
+func (m *UserJWTModel) checkAccount(claims *jwt.UserClaims, issuer string, diags *diag.Diagnostics) {
+	if m.AccountJWT.IsNull() || m.AccountJWT.IsUnknown() {
+		return
+	}
+
+	account, err := jwt.DecodeAccountClaims(m.AccountJWT.ValueString())
+	if err != nil {
+		addError(diags, "Invalid account JWT", errorAt(path.Root("account_jwt"), err))
+		return
+	}
+
+	accountKey := issuer
+	if !m.IssuerAccount.IsNull() {
+		accountKey = m.IssuerAccount.ValueString()
+	}
+	if account.Subject != accountKey {
+		diags.AddAttributeError(path.Root("account_jwt"), "User of another account",
+			fmt.Sprintf("The user is issued for account %s, but the account JWT is of account %s. Set issuer_account "+
+				"to %s if issuer_seed is one of its signing keys.", accountKey, account.Subject, account.Subject))
+		return
+	}
+
+	if issuer != account.Subject && !account.SigningKeys.Contains(issuer) {
+		diags.AddAttributeError(path.Root("issuer_seed"), "Unknown signing key",
+			fmt.Sprintf("%s is not a signing key of account %s, the server rejects the user. Add it to signing_keys "+
+				"or scoped_signing_keys of the account JWT.", issuer, account.Subject))
+		return
+	}
+
+	if claims.BearerToken && account.Limits.DisallowBearer {
+		diags.AddAttributeError(path.Root("bearer_token"), "Bearer tokens disallowed",
+			fmt.Sprintf("Account %s disallows bearer tokens, the server rejects the user. Unset bearer_token or "+
+				"allow bearer tokens in the limits of the account JWT.", account.Subject))
+	}
+
+	if account.Expires != 0 && (claims.Expires == 0 || claims.Expires > account.Expires) {
+		diags.AddAttributeError(path.Root("expires_at"), "User outlives its account",
+			fmt.Sprintf("The account JWT expires at %s, from then on the user cannot connect. Set expires_at to no "+
+				"later than that.", time.Unix(account.Expires, 0).UTC().Format(time.RFC3339)))
+	}
+
+	if scope, ok := account.SigningKeys.GetScope(issuer); ok && scope != nil {
+		if m.Permissions != nil || m.Responses != nil || m.Limits != nil || !m.BearerToken.IsNull() || !m.ConnTypes.IsNull() {
+			diags.AddAttributeWarning(path.Root("issuer_seed"), "User permissions are ignored",
+				fmt.Sprintf("%s is a scoped signing key of account %s, the permissions and limits of its role apply "+
+					"instead of those of the user. Unset them or sign with an unscoped key.", issuer, account.Subject))
+		}
+		if us, ok := scope.(*jwt.UserScope); ok {
+			m.checkScope(us, diags)
+		}
+		return
+	}
+
+	m.checkImports(account.Imports, diags)
+
+	for _, l := range []struct {
+		name         string
+		user, parent int64
+	}{
+		{"subscriptions", claims.Limits.Subs, account.Limits.Subs},
+		{"data", claims.Limits.Data, account.Limits.Data},
+		{"payload", claims.Limits.Payload, account.Limits.Payload},
+	} {
+		if l.parent != jwt.NoLimit && l.user > l.parent {
+			diags.AddAttributeWarning(path.Root("limits").AtName(l.name), "User limit exceeds account limit",
+				fmt.Sprintf("The user limit of %d exceeds the account limit of %d, which takes precedence.", l.user, l.parent))
+		}
+	}
+}
+
+// checkScope checks that the subjects the user is allowed to publish and
+// subscribe to are within the role of the scoped signing key us, whose
+// permissions apply instead.
+func (m *UserJWTModel) checkScope(us *jwt.UserScope, diags *diag.Diagnostics) {
+	role := decodedPermissions(us.Template.Permissions)
+
+	m.Permissions.eachAllowed(func(action, subj string, p path.Path) {
+		var perm *PermissionModel
+		if role != nil && action == "publish" {
+			perm = role.Publish
+		} else if role != nil {
+			perm = role.Subscribe
+		}
+		if !perm.permits(subj) {
+			diags.AddAttributeError(p, "Subject outside signing key scope",
+				fmt.Sprintf("The role %q of scoped signing key %s does not allow to %s to %q, and its permissions "+
+					"apply instead of those of the user. Extend the role or sign with an unscoped key.",
+					us.Role, us.Key, action, subj))
+		}
+	})
+}
+
+// checkImports checks that the subjects the user is allowed to publish and
+// subscribe to do not name the subject of an import that is imported under
+// a different local subject, which the user has to use instead.
+func (m *UserJWTModel) checkImports(imports jwt.Imports, diags *diag.Diagnostics) {
+	m.Permissions.eachAllowed(func(action, subj string, p path.Path) {
+		for _, imp := range imports {
+			local := string(imp.LocalSubject)
+			if local == "" || local == string(imp.Subject) {
+				continue
+			}
+			if subject.Covers(string(imp.Subject), subj) && !subject.Overlaps(local, subj) {
+				diags.AddAttributeError(p, "Subject of another account",
+					fmt.Sprintf("%q is a subject of account %s, which is imported as %q. Users of the account only "+
+						"reach the import on its local subject, allow that instead.", subj, imp.Account, local))
+			}
+		}
+	})
+}
+
+// jwtLimits returns the JWT representation of m.
+func (m *UserLimitsModel) jwtLimits() jwt.Limits {
+	var limits jwt.Limits
+	limits.Subs = limit(m.Subscriptions, jwt.NoLimit)
+	limits.Data = limit(m.Data, jwt.NoLimit)
+	limits.Payload = limit(m.Payload, jwt.NoLimit)
+	limits.Src.Add(m.Src...)
+	for _, t := range m.Times {
+		limits.Times = append(limits.Times, jwt.TimeRange{Start: t.Start.ValueString(), End: t.End.ValueString()})
+	}
+	limits.Locale = m.Locale.ValueString()
+	return limits
+}
+
+// jwtResponses returns the JWT representation of m at p, nil if m is nil.
+func (m *ResponsesModel) jwtResponses(p path.Path, diags *diag.Diagnostics) *jwt.ResponsePermission {
+	if m == nil {
+		return nil
+	}
+
+	resp := &jwt.ResponsePermission{MaxMsgs: int(limit(m.MaxMessages, 1))}
+	if !m.TTL.IsNull() {
+		ttl, err := time.ParseDuration(m.TTL.ValueString())
+		if err != nil {
+			addError(diags, "Invalid response TTL", errorAt(p.AtName("ttl"), err))
+		}
+		resp.Expires = ttl
+	}
+
+	return resp
+}
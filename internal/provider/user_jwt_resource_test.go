@@ -0,0 +1,304 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestUserJWTCheckAccount(t *testing.T) {
+	operator, _ := nkeys.CreateOperator()
+	account, _ := nkeys.CreateAccount()
+	accountKey, _ := account.PublicKey()
+	signer, _ := nkeys.CreateAccount()
+	signerKey, _ := signer.PublicKey()
+	scoped, _ := nkeys.CreateAccount()
+	scopedKey, _ := scoped.PublicKey()
+	exporter, _ := nkeys.CreateAccount()
+	exporterKey, _ := exporter.PublicKey()
+
+	expires := time.Now().Add(24 * time.Hour).Unix()
+
+	claims := jwt.NewAccountClaims(accountKey)
+	claims.Expires = expires
+	claims.SigningKeys.Add(signerKey)
+	role := jwt.NewUserScope()
+	role.Key = scopedKey
+	role.Role = "orders"
+	role.Template.Pub.Allow.Add("orders.>")
+	role.Template.Sub.Allow.Add("_INBOX.>")
+	claims.SigningKeys.AddScopedSigner(role)
+	claims.Imports.Add(&jwt.Import{
+		Account:      exporterKey,
+		Subject:      "svc.prices",
+		LocalSubject: "prices",
+		Type:         jwt.Service,
+	})
+	accountJWT, err := claims.Encode(operator)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		issuer      string
+		expires     int64
+		permissions *PermissionsModel
+		// errors are the expected error summaries.
+		errors []string
+	}{
+		"valid": {
+			issuer:  signerKey,
+			expires: expires,
+			permissions: &PermissionsModel{
+				Publish: &PermissionModel{Allow: []string{"prices", "svc.>"}},
+			},
+		},
+		"never expires": {
+			issuer: signerKey,
+			errors: []string{"User outlives its account"},
+		},
+		"expires after account": {
+			issuer:  signerKey,
+			expires: expires + 1,
+			errors:  []string{"User outlives its account"},
+		},
+		"remote subject of import": {
+			issuer:  signerKey,
+			expires: expires,
+			permissions: &PermissionsModel{
+				Publish: &PermissionModel{Allow: []string{"svc.prices"}},
+			},
+			errors: []string{"Subject of another account"},
+		},
+		"within scope": {
+			issuer:  scopedKey,
+			expires: expires,
+			permissions: &PermissionsModel{
+				Publish:   &PermissionModel{Allow: []string{"orders.new"}},
+				Subscribe: &PermissionModel{Allow: []string{"_INBOX.>"}},
+			},
+		},
+		"outside scope": {
+			issuer:  scopedKey,
+			expires: expires,
+			permissions: &PermissionsModel{
+				Publish:   &PermissionModel{Allow: []string{"orders.new", "billing.>"}},
+				Subscribe: &PermissionModel{Allow: []string{"orders.>"}},
+			},
+			errors: []string{"Subject outside signing key scope", "Subject outside signing key scope"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &UserJWTModel{
+				IssuerAccount: types.StringValue(accountKey),
+				AccountJWT:    types.StringValue(accountJWT),
+				Permissions:   test.permissions,
+				BearerToken:   types.BoolNull(),
+				ConnTypes:     types.SetNull(types.StringType),
+			}
+			user := jwt.NewUserClaims("UAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA")
+			user.Expires = test.expires
+
+			var diags diag.Diagnostics
+			m.checkAccount(user, test.issuer, &diags)
+
+			var got []string
+			for _, d := range diags.Errors() {
+				got = append(got, d.Summary())
+			}
+			if len(got) != len(test.errors) {
+				t.Fatalf("errors = %v, want %v", got, test.errors)
+			}
+			for i := range got {
+				if got[i] != test.errors[i] {
+					t.Errorf("errors = %v, want %v", got, test.errors)
+				}
+			}
+		})
+	}
+}
+
+func TestUserJWTAudience(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	account, _ := nkeys.CreateAccount()
+	accountKey, _ := account.PublicKey()
+	seed, _ := account.Seed()
+	user, _ := nkeys.CreateUser()
+	userKey, _ := user.PublicKey()
+
+	config := map[string]tftypes.Value{
+		"public_key":  stringValue(userKey),
+		"issuer_seed": stringValue(string(seed)),
+		"name":        stringValue("gateway"),
+		"audience":    stringValue("edge-gateway"),
+	}
+	state := p.create("nkey_user_jwt", config)
+
+	claims, err := jwt.DecodeUserClaims(stringAttribute(t, state, "jwt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Issuer != accountKey {
+		t.Errorf("iss = %s, want %s", claims.Issuer, accountKey)
+	}
+	if claims.Audience != "edge-gateway" {
+		t.Errorf("aud = %q, want %q", claims.Audience, "edge-gateway")
+	}
+
+	// A new audience issues the JWT again.
+	config["audience"] = tftypes.NewValue(tftypes.String, nil)
+	resp := p.plan("nkey_user_jwt", state, config)
+	checkDiagnostics(t, resp.Diagnostics)
+	if attribute(t, p.value(p.resourceSchema("nkey_user_jwt"), resp.PlannedState), "jwt").IsKnown() {
+		t.Error("jwt is kept although the audience changed")
+	}
+
+	state = p.apply("nkey_user_jwt", state, config)
+	claims, err = jwt.DecodeUserClaims(stringAttribute(t, state, "jwt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Audience != "" {
+		t.Errorf("aud = %q, want none", claims.Audience)
+	}
+}
+
+func TestUserJWTQueuePermissions(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	account, _ := nkeys.CreateAccount()
+	seed, _ := account.Seed()
+	user, _ := nkeys.CreateUser()
+	userKey, _ := user.PublicKey()
+
+	permissionsType := p.resourceSchema("nkey_user_jwt").ValueType().(tftypes.Object).AttributeTypes["permissions"].(tftypes.Object)
+	permissionType := permissionsType.AttributeTypes["publish"]
+	list := func(s ...string) tftypes.Value {
+		values := make([]tftypes.Value, len(s))
+		for i, s := range s {
+			values[i] = stringValue(s)
+		}
+		return tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, values)
+	}
+	config := func(publish, subscribe []string) map[string]tftypes.Value {
+		permission := func(allow []string) tftypes.Value {
+			if allow == nil {
+				return tftypes.NewValue(permissionType, nil)
+			}
+			return tftypes.NewValue(permissionType, map[string]tftypes.Value{
+				"allow": list(allow...),
+				"deny":  tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, nil),
+			})
+		}
+		return map[string]tftypes.Value{
+			"public_key":  stringValue(userKey),
+			"issuer_seed": stringValue(string(seed)),
+			"name":        stringValue("worker"),
+			"permissions": tftypes.NewValue(permissionsType, map[string]tftypes.Value{
+				"publish":   permission(publish),
+				"subscribe": permission(subscribe),
+			}),
+		}
+	}
+
+	valid := config(nil, []string{"orders.* workers", "updates.> ui.*", "_INBOX.>"})
+	checkDiagnostics(t, p.validate("nkey_user_jwt", valid))
+	claims, err := jwt.DecodeUserClaims(stringAttribute(t, p.create("nkey_user_jwt", valid), "jwt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !claims.Sub.Allow.Contains("orders.* workers") || !claims.Sub.Allow.Contains("updates.> ui.*") {
+		t.Errorf("subscribe allow = %v, want the queue groups kept", claims.Sub.Allow)
+	}
+
+	for name, test := range map[string]struct {
+		publish, subscribe []string
+		err                string
+	}{
+		"publish queue":      {[]string{"orders.new workers"}, nil, "Invalid subject"},
+		"two queue groups":   {nil, []string{"orders.* workers extra"}, "Invalid queue group"},
+		"empty queue group":  {nil, []string{"orders.* "}, "Invalid queue group"},
+		"invalid queue":      {nil, []string{"orders.* work..ers"}, "Invalid queue group"},
+		"invalid subject":    {nil, []string{"orders..* workers"}, "Invalid subject"},
+		"leading whitespace": {nil, []string{" orders.*"}, "Invalid subject"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if diags := p.validate("nkey_user_jwt", config(test.publish, test.subscribe)); !hasError(diags, test.err) {
+				t.Errorf("expected %q, got %v", test.err, diags)
+			}
+		})
+	}
+}
+
+func TestUserJWTUnknownAttributes(t *testing.T) {
+	p := newTestProvider(t, nil)
+
+	account, _ := nkeys.CreateAccount()
+	accountKey, _ := account.PublicKey()
+	seed, _ := account.Seed()
+	user, _ := nkeys.CreateUser()
+	userKey, _ := user.PublicKey()
+	operator, _ := nkeys.CreateOperator()
+	accountJWT, err := jwt.NewAccountClaims(accountKey).Encode(operator)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unknown := tftypes.NewValue(tftypes.String, tftypes.UnknownValue)
+	config := func(attrs map[string]tftypes.Value) map[string]tftypes.Value {
+		config := map[string]tftypes.Value{
+			"public_key":   stringValue(userKey),
+			"issuer_seed":  stringValue(string(seed)),
+			"account_jwt":  stringValue(accountJWT),
+			"name":         stringValue("alice"),
+			"renew_before": stringValue("24h"),
+			"expires_at":   stringValue("720h"),
+		}
+		for name, v := range attrs {
+			config[name] = v
+		}
+		return config
+	}
+	state := p.create("nkey_user_jwt", config(nil))
+
+	tests := map[string]struct {
+		attrs    map[string]tftypes.Value
+		reissued bool
+	}{
+		// The account JWT is issued again in the same apply, e.g. for new
+		// limits, which does not change the user.
+		"account JWT":            {map[string]tftypes.Value{"account_jwt": unknown}, false},
+		"renewal window":         {map[string]tftypes.Value{"renew_before": unknown}, false},
+		"public key":             {map[string]tftypes.Value{"public_key": unknown}, true},
+		"name":                   {map[string]tftypes.Value{"name": unknown}, true},
+		"account JWT and change": {map[string]tftypes.Value{"account_jwt": unknown, "name": stringValue("bob")}, true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := p.plan("nkey_user_jwt", state, config(test.attrs))
+			checkDiagnostics(t, resp.Diagnostics)
+			planned := p.value(p.resourceSchema("nkey_user_jwt"), resp.PlannedState)
+
+			jwtValue := attribute(t, planned, "jwt")
+			if known := jwtValue.IsKnown(); known == test.reissued {
+				t.Fatalf("jwt is known = %t, want %t", known, !test.reissued)
+			}
+			if !test.reissued && stringAttribute(t, planned, "jwt") != stringAttribute(t, state, "jwt") {
+				t.Error("jwt differs from the JWT in state")
+			}
+		})
+	}
+}
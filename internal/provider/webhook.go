@@ -0,0 +1,111 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// webhookSignatureHeader carries the hex encoded HMAC-SHA256 of the request
+// body, keyed with the webhook secret.
+const webhookSignatureHeader = "X-Nkey-Signature"
+
+// webhookEvent is the JSON body sent to the webhook. It must only ever
+// contain public data.
+type webhookEvent struct {
+	Event     string `json:"event"`
+	Resource  string `json:"resource"`
+	Type      string `json:"type"`
+	PublicKey string `json:"public_key"`
+	Time      string `json:"time"`
+}
+
+// notify sends event to the configured webhook. The key has already been
+// created or deleted at this point, so failures are only reported as
+// warnings.
+func (p *providerData) notify(ctx context.Context, event webhookEvent, diags *diag.Diagnostics) {
+	if p == nil || p.webhookURL == "" {
+		return
+	}
+	if p.offline {
+		tflog.Debug(ctx, "not sending webhook event while offline", map[string]interface{}{"event": event.Event})
+		return
+	}
+
+	event.Time = time.Now().UTC().Format(time.RFC3339)
+
+	if err := p.sendWebhook(ctx, event); err != nil {
+		diags.AddWarning("Unable to send webhook event",
+			fmt.Sprintf("The %s event for %s could not be delivered: %s", event.Event, event.PublicKey, err))
+	}
+}
+
+func (p *providerData) sendWebhook(ctx context.Context, event webhookEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withDefaultTimeout(ctx, httpTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if p.webhookSecret != "" {
+		mac := hmac.New(sha256.New, []byte(p.webhookSecret))
+		mac.Write(body)
+		req.Header.Set(webhookSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := p.httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("POST %s: unexpected status %s", p.webhookURL, resp.Status)
+	}
+
+	return nil
+}
+
+// jwtEvent returns the webhook event for token issued by resource, reporting
+// the subject and claim type of the JWT.
+func jwtEvent(name, resource string, token types.String) webhookEvent {
+	var claims struct {
+		Subject string `json:"sub"`
+		Nats    struct {
+			Type string `json:"type"`
+		} `json:"nats"`
+	}
+	_ = jwtClaims(token.ValueString(), &claims)
+
+	if claims.Nats.Type == "" {
+		claims.Nats.Type = "generic"
+	}
+
+	return webhookEvent{
+		Event:     name,
+		Resource:  resource,
+		Type:      claims.Nats.Type,
+		PublicKey: claims.Subject,
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/nkeys"
+)
+
+// webhookReceiver records the events POSTed to it.
+type webhookReceiver struct {
+	mu     sync.Mutex
+	events []webhookEvent
+}
+
+// newWebhookProvider returns a test provider sending its webhook events to
+// the returned receiver.
+func newWebhookProvider(t *testing.T) (*testProvider, *webhookReceiver) {
+	t.Helper()
+
+	recv := &webhookReceiver{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event webhookEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("invalid webhook event: %s", err)
+		}
+		recv.mu.Lock()
+		recv.events = append(recv.events, event)
+		recv.mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+
+	webhookType := tftypes.Object{AttributeTypes: map[string]tftypes.Type{
+		"url":    tftypes.String,
+		"secret": tftypes.String,
+	}}
+	p := newTestProvider(t, map[string]tftypes.Value{
+		"webhook": tftypes.NewValue(webhookType, map[string]tftypes.Value{
+			"url":    stringValue(srv.URL),
+			"secret": tftypes.NewValue(tftypes.String, nil),
+		}),
+	})
+
+	return p, recv
+}
+
+// take returns the events received since the last call.
+func (r *webhookReceiver) take() []webhookEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := r.events
+	r.events = nil
+	for i := range events {
+		events[i].Time = ""
+	}
+	return events
+}
+
+// withAttribute returns the object v with the attribute name set to value.
+func withAttribute(t *testing.T, v tftypes.Value, name string, value tftypes.Value) tftypes.Value {
+	t.Helper()
+
+	var attrs map[string]tftypes.Value
+	if err := v.As(&attrs); err != nil {
+		t.Fatal(err)
+	}
+	attrs[name] = value
+	return tftypes.NewValue(v.Type(), attrs)
+}
+
+func TestWebhookRotation(t *testing.T) {
+	tests := map[string]struct {
+		config map[string]tftypes.Value
+		// deleted reports whether the old key pair is retired right away.
+		deleted bool
+	}{
+		"key history": {
+			config: map[string]tftypes.Value{
+				"type":          stringValue("user"),
+				"rotation_days": numberValue(30),
+				"key_history":   numberValue(2),
+			},
+			deleted: true,
+		},
+		"overlap": {
+			config: map[string]tftypes.Value{
+				"type":                  stringValue("user"),
+				"rotation_days":         numberValue(30),
+				"rotation_overlap_days": numberValue(7),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, recv := newWebhookProvider(t)
+
+			state := p.create("nkey_nkey", test.config)
+			oldKey := stringAttribute(t, state, "public_key")
+			if events := recv.take(); len(events) != 1 || events[0].Event != "created" || events[0].PublicKey != oldKey {
+				t.Fatalf("create sent %v, want created for %s", events, oldKey)
+			}
+
+			state = withAttribute(t, state, "expires_at", stringValue("2000-01-01T00:00:00Z"))
+			state = p.apply("nkey_nkey", state, test.config)
+			newKey := stringAttribute(t, state, "public_key")
+			if newKey == oldKey {
+				t.Fatal("key not rotated")
+			}
+
+			want := []webhookEvent{{Event: "created", Resource: "nkey_nkey", Type: "user", PublicKey: newKey}}
+			if test.deleted {
+				want = append(want, webhookEvent{Event: "deleted", Resource: "nkey_nkey", Type: "user", PublicKey: oldKey})
+			}
+			if events := recv.take(); !equalEvents(events, want) {
+				t.Errorf("rotation sent %v, want %v", events, want)
+			}
+		})
+	}
+}
+
+func TestWebhookJWTReissued(t *testing.T) {
+	p, recv := newWebhookProvider(t)
+
+	issuer, err := nkeys.CreateAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed, _ := issuer.Seed()
+	user, err := nkeys.CreateUser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, _ := user.PublicKey()
+
+	config := map[string]tftypes.Value{
+		"subject":     stringValue(subject),
+		"claims":      stringValue(`{"role":"billing"}`),
+		"issuer_seed": stringValue(string(seed)),
+	}
+	state := p.create("nkey_generic_jwt", config)
+	if events := recv.take(); len(events) != 0 {
+		t.Errorf("create sent %v", events)
+	}
+
+	// Unchanged claims keep the JWT.
+	state = p.apply("nkey_generic_jwt", state, config)
+	if events := recv.take(); len(events) != 0 {
+		t.Errorf("update without changes sent %v", events)
+	}
+
+	config["claims"] = stringValue(`{"role":"admin"}`)
+	p.apply("nkey_generic_jwt", state, config)
+
+	want := []webhookEvent{{Event: "reissued", Resource: "nkey_generic_jwt", Type: "generic", PublicKey: subject}}
+	if events := recv.take(); !equalEvents(events, want) {
+		t.Errorf("update sent %v, want %v", events, want)
+	}
+}
+
+func equalEvents(a, b []webhookEvent) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
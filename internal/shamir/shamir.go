@@ -0,0 +1,137 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package shamir implements Shamir's secret sharing over GF(2^8).
+//
+// Every byte of the secret is the constant term of its own random
+// polynomial of degree threshold-1. A share holds the evaluation of all
+// polynomials at one non-zero x coordinate, which is appended as the last
+// byte of the share.
+package shamir
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+const maxShares = 255
+
+// Split divides secret into parts shares, any threshold of which are needed
+// to reconstruct it. Coefficients are read from rand.
+func Split(secret []byte, parts, threshold int, rand io.Reader) ([][]byte, error) {
+	switch {
+	case len(secret) == 0:
+		return nil, errors.New("cannot split an empty secret")
+	case threshold < 2:
+		return nil, errors.New("threshold must be at least 2")
+	case parts < threshold:
+		return nil, errors.New("number of shares cannot be less than the threshold")
+	case parts > maxShares:
+		return nil, fmt.Errorf("number of shares cannot exceed %d", maxShares)
+	}
+
+	shares := make([][]byte, parts)
+	for i := range shares {
+		shares[i] = make([]byte, len(secret)+1)
+		shares[i][len(secret)] = byte(i + 1)
+	}
+
+	coefficients := make([]byte, threshold)
+	defer clear(coefficients)
+
+	for idx, b := range secret {
+		coefficients[0] = b
+		if _, err := io.ReadFull(rand, coefficients[1:]); err != nil {
+			return nil, err
+		}
+
+		for i := range shares {
+			shares[i][idx] = evaluate(coefficients, byte(i+1))
+		}
+	}
+
+	return shares, nil
+}
+
+// Combine reconstructs the secret from at least threshold shares created
+// by Split. Passing fewer shares yields a wrong secret, not an error.
+func Combine(shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, errors.New("at least two shares are required")
+	}
+
+	size := len(shares[0])
+	if size < 2 {
+		return nil, errors.New("shares are too short")
+	}
+
+	xs := make([]byte, len(shares))
+	seen := map[byte]bool{}
+
+	for i, share := range shares {
+		if len(share) != size {
+			return nil, errors.New("all shares must have the same length")
+		}
+
+		x := share[size-1]
+		if x == 0 || seen[x] {
+			return nil, fmt.Errorf("share %d has an invalid or duplicate coordinate", i)
+		}
+		seen[x] = true
+		xs[i] = x
+	}
+
+	secret := make([]byte, size-1)
+
+	for idx := range secret {
+		var value byte
+
+		// Lagrange interpolation at x = 0.
+		for i, share := range shares {
+			basis := byte(1)
+			for j := range shares {
+				if i == j {
+					continue
+				}
+				basis = mul(basis, div(xs[j], xs[i]^xs[j]))
+			}
+			value ^= mul(share[idx], basis)
+		}
+
+		secret[idx] = value
+	}
+
+	return secret, nil
+}
+
+// evaluate computes the polynomial with the given coefficients at x.
+func evaluate(coefficients []byte, x byte) byte {
+	var result byte
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		result = mul(result, x) ^ coefficients[i]
+	}
+	return result
+}
+
+// mul multiplies in GF(2^8) modulo the AES polynomial x^8+x^4+x^3+x+1,
+// without data dependent branches.
+func mul(a, b byte) byte {
+	var p byte
+	for i := 0; i < 8; i++ {
+		p ^= -(b & 1) & a
+		carry := -(a >> 7) & 0x1b
+		a = a<<1 ^ carry
+		b >>= 1
+	}
+	return p
+}
+
+// div divides a by the non-zero b, using b^254 as the inverse of b.
+func div(a, b byte) byte {
+	inv := b
+	for i := 0; i < 6; i++ {
+		inv = mul(mul(inv, inv), b)
+	}
+	return mul(a, mul(inv, inv))
+}
@@ -0,0 +1,171 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package shamir
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+)
+
+func TestSplitCombine(t *testing.T) {
+	secret := []byte("SUAIBDPBAUTWCWBKIO6XHQNINK5FWJW4OHLXC3HQ2KFE4PEJUA44CNHTC4")
+
+	tests := []struct {
+		parts, threshold int
+	}{
+		{2, 2},
+		{3, 2},
+		{5, 3},
+		{10, 10},
+		{255, 2},
+	}
+
+	for _, test := range tests {
+		shares, err := Split(secret, test.parts, test.threshold, rand.Reader)
+		if err != nil {
+			t.Fatalf("Split(%d, %d): %v", test.parts, test.threshold, err)
+		}
+		if len(shares) != test.parts {
+			t.Fatalf("Split(%d, %d) returned %d shares", test.parts, test.threshold, len(shares))
+		}
+
+		// Every window of threshold consecutive shares, in both orders,
+		// recombines the secret.
+		for i := 0; i+test.threshold <= len(shares); i++ {
+			subset := shares[i : i+test.threshold]
+
+			got, err := Combine(subset)
+			if err != nil {
+				t.Fatalf("Combine(%d of %d): %v", test.threshold, test.parts, err)
+			}
+			if !bytes.Equal(got, secret) {
+				t.Errorf("Combine(%d of %d) = %q, want %q", test.threshold, test.parts, got, secret)
+			}
+
+			reversed := make([][]byte, len(subset))
+			for j, share := range subset {
+				reversed[len(subset)-1-j] = share
+			}
+			if got, _ := Combine(reversed); !bytes.Equal(got, secret) {
+				t.Errorf("Combine(%d of %d) reversed = %q, want %q", test.threshold, test.parts, got, secret)
+			}
+		}
+
+		// All shares recombine the secret as well.
+		if got, _ := Combine(shares); !bytes.Equal(got, secret) {
+			t.Errorf("Combine(all %d) = %q, want %q", test.parts, got, secret)
+		}
+	}
+}
+
+func TestCombineBelowThreshold(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x42}, 32)
+
+	shares, err := Split(secret, 5, 3, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Combine(shares[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(got, secret) {
+		t.Error("two of three shares recombined the secret")
+	}
+}
+
+func TestSplitErrors(t *testing.T) {
+	tests := map[string]struct {
+		secret           []byte
+		parts, threshold int
+	}{
+		"empty secret":       {nil, 3, 2},
+		"threshold of one":   {[]byte("s"), 3, 1},
+		"parts below":        {[]byte("s"), 2, 3},
+		"too many parts":     {[]byte("s"), 256, 2},
+		"negative threshold": {[]byte("s"), 3, -1},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Split(test.secret, test.parts, test.threshold, rand.Reader); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("no entropy")
+}
+
+func TestSplitEntropyError(t *testing.T) {
+	if _, err := Split([]byte("secret"), 3, 2, failingReader{}); err == nil {
+		t.Error("expected the entropy error")
+	}
+}
+
+func TestCombineErrors(t *testing.T) {
+	tests := map[string][][]byte{
+		"no shares":            nil,
+		"one share":            {{1, 1}},
+		"too short":            {{1}, {2}},
+		"different lengths":    {{1, 2, 1}, {1, 2}},
+		"zero coordinate":      {{1, 0}, {2, 1}},
+		"duplicate coordinate": {{1, 1}, {2, 1}},
+	}
+
+	for name, shares := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Combine(shares); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestField(t *testing.T) {
+	tests := []struct {
+		a, b, product byte
+	}{
+		{0x00, 0x53, 0x00},
+		{0x01, 0x53, 0x53},
+		{0x02, 0x80, 0x1b},
+		{0x53, 0xca, 0x01},
+		{0x57, 0x83, 0xc1},
+		{0x57, 0x13, 0xfe},
+	}
+
+	for _, test := range tests {
+		if got := mul(test.a, test.b); got != test.product {
+			t.Errorf("mul(%#x, %#x) = %#x, want %#x", test.a, test.b, got, test.product)
+		}
+		if got := mul(test.b, test.a); got != test.product {
+			t.Errorf("mul(%#x, %#x) = %#x, want %#x", test.b, test.a, got, test.product)
+		}
+	}
+
+	for a := 0; a < 256; a++ {
+		for b := 1; b < 256; b++ {
+			if got := mul(div(byte(a), byte(b)), byte(b)); got != byte(a) {
+				t.Fatalf("div(%#x, %#x) * %#x = %#x", a, b, b, got)
+			}
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	// 3 + 2x + x^2 at x = 2 is 3 ^ mul(2, 2) ^ mul(4, 1) = 3 ^ 4 ^ 4.
+	if got := evaluate([]byte{3, 2, 1}, 2); got != 3 {
+		t.Errorf("evaluate = %#x, want 0x3", got)
+	}
+	if got := evaluate([]byte{0x42, 0x17, 0x99}, 0); got != 0x42 {
+		t.Errorf("evaluate at 0 = %#x, want the constant term", got)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package subject implements validation and wildcard matching of NATS
+// subjects.
+package subject
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	separator = "."
+	single    = "*"
+	full      = ">"
+)
+
+// Validate checks that s is a well formed subject. Wildcards are only
+// accepted if wildcards is set, as they are valid in subscriptions and
+// permissions but not when publishing.
+func Validate(s string, wildcards bool) error {
+	if s == "" {
+		return errors.New("subject must not be empty")
+	}
+	if strings.ContainsAny(s, " \t\r\n") {
+		return fmt.Errorf("subject %q must not contain whitespace", s)
+	}
+
+	tokens := strings.Split(s, separator)
+	for i, t := range tokens {
+		switch {
+		case t == "":
+			return fmt.Errorf("subject %q contains an empty token", s)
+		case t == full && i != len(tokens)-1:
+			return fmt.Errorf("subject %q may only use %q as the last token", s, full)
+		case (t == single || t == full) && !wildcards:
+			return fmt.Errorf("subject %q must not contain wildcards", s)
+		case t != single && t != full && strings.ContainsAny(t, single+full):
+			return fmt.Errorf("subject %q uses a wildcard inside token %q", s, t)
+		}
+	}
+
+	return nil
+}
+
+// Covers reports whether every subject matched by s is also matched by
+// pattern. For a literal s this is plain wildcard matching.
+func Covers(pattern, s string) bool {
+	p := strings.Split(pattern, separator)
+	t := strings.Split(s, separator)
+
+	for i, pt := range p {
+		if i >= len(t) {
+			return false
+		}
+
+		st := t[i]
+		switch {
+		case pt == full:
+			return true
+		case pt == single:
+			if st == full {
+				return false
+			}
+		case st == single || st == full || pt != st:
+			return false
+		}
+	}
+
+	return len(p) == len(t)
+}
+
+// Overlaps reports whether at least one literal subject is matched by both
+// a and b.
+func Overlaps(a, b string) bool {
+	at := strings.Split(a, separator)
+	bt := strings.Split(b, separator)
+
+	for i := 0; i < len(at) && i < len(bt); i++ {
+		x, y := at[i], bt[i]
+		switch {
+		case x == full || y == full:
+			return true
+		case x == single || y == single || x == y:
+			continue
+		default:
+			return false
+		}
+	}
+
+	return len(at) == len(bt)
+}
+
+// IsLiteral reports whether s contains no wildcard tokens.
+func IsLiteral(s string) bool {
+	for _, t := range strings.Split(s, separator) {
+		if t == single || t == full {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package subject
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		subject   string
+		wildcards bool
+		valid     bool
+	}{
+		{"foo", false, true},
+		{"foo.bar.baz", false, true},
+		{"$SYS.REQ.ACCOUNT", false, true},
+		{"foo-bar_baz", false, true},
+		{"foo.*", true, true},
+		{"foo.>", true, true},
+		{"*.*.>", true, true},
+		{">", true, true},
+		{"foo.*", false, false},
+		{"foo.>", false, false},
+		{"", true, false},
+		{"foo bar", true, false},
+		{"foo\tbar", true, false},
+		{"foo.", true, false},
+		{".foo", true, false},
+		{"foo..bar", true, false},
+		{"foo.>.bar", true, false},
+		{"foo*.bar", true, false},
+		{"foo.ba>", true, false},
+	}
+
+	for _, test := range tests {
+		err := Validate(test.subject, test.wildcards)
+		if got := err == nil; got != test.valid {
+			t.Errorf("Validate(%q, %v) = %v, want valid %v", test.subject, test.wildcards, err, test.valid)
+		}
+	}
+}
+
+func TestCovers(t *testing.T) {
+	tests := []struct {
+		pattern, subject string
+		covers           bool
+	}{
+		{"foo", "foo", true},
+		{"foo", "bar", false},
+		{"foo.*", "foo.bar", true},
+		{"foo.*", "foo", false},
+		{"foo.*", "foo.bar.baz", false},
+		{"foo.>", "foo.bar", true},
+		{"foo.>", "foo.bar.baz", true},
+		{"foo.>", "foo", false},
+		{">", "foo.bar", true},
+		{"*.bar", "foo.bar", true},
+		{"*.bar", "foo.baz", false},
+		{"foo.*", "foo.*", true},
+		{"foo.>", "foo.*", true},
+		{"foo.>", "foo.>", true},
+		{"foo.*", "foo.>", false},
+		{"foo.bar", "foo.*", false},
+		{"foo.*.baz", "foo.*.baz", true},
+		{"foo.bar.baz", "foo.bar", false},
+	}
+
+	for _, test := range tests {
+		if got := Covers(test.pattern, test.subject); got != test.covers {
+			t.Errorf("Covers(%q, %q) = %v, want %v", test.pattern, test.subject, got, test.covers)
+		}
+	}
+}
+
+func TestOverlaps(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		overlaps bool
+	}{
+		{"foo", "foo", true},
+		{"foo", "bar", false},
+		{"foo.*", "foo.bar", true},
+		{"foo.*", "*.bar", true},
+		{"foo.*", "bar.*", false},
+		{"foo.>", "foo.bar.baz", true},
+		{"foo.>", "*.bar", true},
+		{"foo.*", "foo.bar.baz", false},
+		{"foo", "foo.bar", false},
+		{">", "foo", true},
+		{"foo.bar", "foo.*.baz", false},
+	}
+
+	for _, test := range tests {
+		if got := Overlaps(test.a, test.b); got != test.overlaps {
+			t.Errorf("Overlaps(%q, %q) = %v, want %v", test.a, test.b, got, test.overlaps)
+		}
+		if got := Overlaps(test.b, test.a); got != test.overlaps {
+			t.Errorf("Overlaps(%q, %q) = %v, want %v", test.b, test.a, got, test.overlaps)
+		}
+	}
+}
+
+func TestIsLiteral(t *testing.T) {
+	tests := map[string]bool{
+		"foo":         true,
+		"foo.bar":     true,
+		"foo*bar":     true,
+		"foo.*":       false,
+		"foo.>":       false,
+		"*":           false,
+		"foo.*.bar.>": false,
+	}
+
+	for s, literal := range tests {
+		if got := IsLiteral(s); got != literal {
+			t.Errorf("IsLiteral(%q) = %v, want %v", s, got, literal)
+		}
+	}
+}
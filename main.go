@@ -7,6 +7,8 @@ import (
 	"context"
 	"flag"
 	"log"
+	"os"
+	"terraform-provider-nkey/internal/extract"
 	"terraform-provider-nkey/internal/provider"
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
@@ -34,6 +36,13 @@ var (
 func main() {
 	var debug bool
 
+	if len(os.Args) > 1 && os.Args[1] == "extract" {
+		if err := extract.Run(os.Args[2:], os.Stdin, os.Stdout); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+
 	flag.BoolVar(&debug, "debug", false, "set to true to run the provider with support for debuggers like delve")
 	flag.Parse()
 